@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+)
+
+// runDiff implements "diff <service>" and "diff <old-file> <new-file>". Like
+// diff(1), it exits with 0 when there are no changes, 1 when there are and 2
+// on errors.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	from := flags.String("from", "", "git revision of the old version (default: HEAD)")
+	to := flags.String("to", "", "git revision of the new version (default: the working tree file)")
+	jsonOutput := flags.Bool("json", false, "print changes as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: diff [flags] <service>\n       diff [-json] <old-file> <new-file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var request services.DiffRequest
+	switch flags.NArg() {
+	case 1:
+		request = services.DiffRequest{ServiceName: flags.Arg(0), From: *from, To: *to}
+	case 2:
+		if *from != "" || *to != "" {
+			fmt.Fprintln(os.Stderr, "-from and -to compare a service's file between git revisions and cannot be used with two files")
+			return 2
+		}
+		request = services.DiffRequest{OldFile: flags.Arg(0), NewFile: flags.Arg(1)}
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	diff, err := services.NewDiffProvider(*configDir).Diff(request)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(diff)
+	} else {
+		fmt.Print(services.FormatServiceDiff(diff))
+	}
+
+	if len(diff.Changes) > 0 {
+		return 1
+	}
+	return 0
+}
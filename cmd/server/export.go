@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+)
+
+// runExport implements "export site". It exits with 2 on usage errors and 1
+// when the export fails.
+func runExport(args []string) int {
+	if len(args) == 0 || args[0] != "site" {
+		fmt.Fprintln(os.Stderr, "usage: export site [flags] -o <dir>")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("export site", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	format := flags.String("format", services.SiteFormatHTML, "site format: html or mkdocs")
+	templateFile := flags.String("template", "", "README template file for the service pages (default: "+services.ReadmeTemplateFile+" in the config directory, or the built-in template)")
+	output := flags.String("o", "", "output directory")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: export site [flags] -o <dir>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *output == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	options := services.SiteOptions{Dir: *output, Format: *format}
+	if *templateFile != "" {
+		content, err := ioutil.ReadFile(*templateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		options.Template = string(content)
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	provider := services.NewReadmeProvider(*configDir)
+	for _, err := range provider.LoadErrors() {
+		fmt.Fprintf(os.Stderr, "Warning: skipped service: %v\n", err)
+	}
+	summary, err := provider.ExportSite(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Exported %d services in %d categories and %d shared troubleshooting entries to %s\n",
+		summary.Services, summary.Categories, summary.SharedIssues, *output)
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+)
+
+// runImport implements "import <README.md>". It prints a draft service
+// configuration, or writes it to the -o file, and prints the import report
+// to stderr. It exits with 2 on usage errors and 1 when the import fails.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	service := flags.String("service", "", "service name (default: the package directory of packages/<name>/docs/README.md)")
+	output := flags.String("o", "", "write the draft configuration to this file instead of stdout")
+	force := flags.Bool("force", false, "overwrite the -o file if it exists")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: import [flags] <README.md>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if *output != "" && !*force {
+		if _, err := os.Stat(*output); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %s exists; use -force to overwrite it\n", *output)
+			return 2
+		}
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	draft, report, err := services.NewReadmeProvider(*configDir).ImportReadme(flags.Arg(0), *service)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	content, err := services.FormatImportedConfig(draft)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	content = fmt.Sprintf("# Draft imported from %s; review it before use\n%s", flags.Arg(0), content)
+
+	if *output == "" {
+		fmt.Print(content)
+	} else if err := ioutil.WriteFile(*output, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprint(os.Stderr, services.FormatImportReport(report))
+	return 0
+}
@@ -1,14 +1,59 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
 
+	"elastic-integration-docs-mcp/internal/config"
 	"elastic-integration-docs-mcp/internal/mcp"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	if _, err := config.ContentModeFromEnv(); err != nil {
+		log.Fatal(err)
+	}
 	server := mcp.NewServer()
 	if err := server.Run(); err != nil {
 		log.Fatal(err)
 	}
 }
+
+const usage = `Usage:
+  elastic-integration-docs-mcp                          run the MCP server on stdio
+  elastic-integration-docs-mcp validate run <service>   run a service's validation steps
+  elastic-integration-docs-mcp readme <service>         render a service's integration README
+  elastic-integration-docs-mcp export site -o <dir>     export every service as a static site
+  elastic-integration-docs-mcp diff <service>           compare a service's file with a git revision
+  elastic-integration-docs-mcp import <README.md>       draft a service configuration from a README
+  elastic-integration-docs-mcp stale [service...]       list content verified too long ago
+  elastic-integration-docs-mcp review [service...]      list content that still needs sign-off
+`
+
+// runCommand runs a command-line subcommand and returns the process exit code.
+// Without a subcommand, the binary runs the MCP server on stdio.
+func runCommand(name string, args []string) int {
+	switch name {
+	case "validate":
+		return runValidate(args)
+	case "readme":
+		return runReadme(args)
+	case "export":
+		return runExport(args)
+	case "diff":
+		return runDiff(args)
+	case "import":
+		return runImport(args)
+	case "stale":
+		return runStale(args)
+	case "review":
+		return runReview(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", name, usage)
+		return 2
+	}
+}
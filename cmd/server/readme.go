@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+)
+
+// runReadme implements "readme <service>". It prints the rendered README, or
+// writes it to the -o file, and exits with 2 on usage errors and 1 when
+// rendering fails.
+func runReadme(args []string) int {
+	flags := flag.NewFlagSet("readme", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	integration := flags.String("integration", "", "integration package for the data stream reference (default: the service name)")
+	templateFile := flags.String("template", "", "README template file (default: "+services.ReadmeTemplateFile+" in the config directory, or the built-in template)")
+	output := flags.String("o", "", "write the README to this file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: readme [flags] <service>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var templateText string
+	if *templateFile != "" {
+		content, err := ioutil.ReadFile(*templateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		templateText = string(content)
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	readme, err := services.NewReadmeProvider(*configDir).Readme(flags.Arg(0), *integration, templateText)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		fmt.Print(readme)
+		return 0
+	}
+	if err := ioutil.WriteFile(*output, []byte(readme), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
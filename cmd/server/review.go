@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+)
+
+// runReview implements "review [service...]". It prints a checklist per
+// service of the sections that still need sign-off, and exits with 0 when
+// nothing does, 1 when something does and 2 on errors.
+func runReview(args []string) int {
+	flags := flag.NewFlagSet("review", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	all := flags.Bool("all", false, "also list services with nothing left to review")
+	jsonOutput := flags.Bool("json", false, "print the checklists as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: review [flags] [service...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	report, err := services.NewReviewProvider(*configDir).Review(services.ReviewOptions{
+		ServiceNames: flags.Args(),
+		All:          *all,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		fmt.Print(services.FormatReviewReport(report))
+	}
+
+	if len(report.Errors) > 0 {
+		return 2
+	}
+	if report.Open > 0 {
+		return 1
+	}
+	return 0
+}
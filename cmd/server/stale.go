@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+)
+
+// runStale implements "stale [service...]". It exits with 0 when no content
+// is stale, 1 when some is and 2 on errors, so it can gate CI jobs.
+func runStale(args []string) int {
+	flags := flag.NewFlagSet("stale", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	maxAge := flags.String("max-age", services.DefaultStaleAge, "how long ago content may have been verified, e.g. 90d, 12w, 6m or 1y")
+	unverified := flags.Bool("unverified", false, "also list content without a verified_on date")
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: stale [flags] [service...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	report, err := services.NewStaleProvider(*configDir).Stale(services.StaleOptions{
+		ServiceNames: flags.Args(),
+		MaxAge:       *maxAge,
+		Unverified:   *unverified,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		fmt.Print(services.FormatStaleReport(report))
+	}
+
+	if len(report.Errors) > 0 {
+		return 2
+	}
+	if len(report.Items) > 0 {
+		return 1
+	}
+	return 0
+}
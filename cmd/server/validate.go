@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/services"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// runValidate implements "validate run <service>". It exits with 1 when any
+// step fails and 2 on usage errors.
+func runValidate(args []string) int {
+	if len(args) == 0 || args[0] != "run" {
+		fmt.Fprintln(os.Stderr, "usage: validate run [flags] <service>")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("validate run", flag.ContinueOnError)
+	configDir := flags.String("config-dir", "", "config directory (default: located automatically)")
+	steps := flags.String("steps", "", "comma separated step numbers to run (default: all)")
+	timeout := flags.Duration("timeout", 0, "timeout for steps that set none in their expect block (default 30s)")
+	passEnv := flags.String("env", "", "comma separated environment variables to pass to the steps, e.g. ES_URL,ES_API_KEY")
+	noSandbox := flags.Bool("no-sandbox", false, "run the steps without isolation, e.g. inside a disposable container")
+	jsonOutput := flags.Bool("json", false, "print results as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate run [flags] <service>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	options := services.ValidationRunOptions{Timeout: *timeout, NoSandbox: *noSandbox}
+	for _, name := range strings.Split(*passEnv, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			options.PassEnv = append(options.PassEnv, name)
+		}
+	}
+	for _, field := range strings.Split(*steps, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		step, err := strconv.Atoi(field)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid step number '%s'\n", field)
+			return 2
+		}
+		options.Steps = append(options.Steps, step)
+	}
+
+	if *configDir == "" {
+		*configDir = config.FindConfigDir()
+	}
+	results, err := services.NewValidationProvider(*configDir).RunValidationSteps(flags.Arg(0), options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(results)
+	} else {
+		printStepResults(flags.Arg(0), results)
+	}
+
+	for _, result := range results {
+		if result.Status == "fail" {
+			return 1
+		}
+	}
+	return 0
+}
+
+func printStepResults(serviceName string, results []shared.ValidationStepResult) {
+	counts := make(map[string]int)
+	fmt.Printf("%s validation steps\n\n", serviceName)
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Printf("%-4s  Step %d: %s", strings.ToUpper(result.Status), result.Step, result.Title)
+		if result.Duration != "" {
+			fmt.Printf(" (%s)", result.Duration)
+		}
+		if result.Message != "" {
+			fmt.Printf(" - %s", result.Message)
+		}
+		fmt.Println()
+
+		for _, failure := range result.Failures {
+			fmt.Printf("      %s\n", failure)
+		}
+		if result.Status == "fail" {
+			printOutput("stdout", result.Stdout)
+			printOutput("stderr", result.Stderr)
+		}
+	}
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", counts["pass"], counts["fail"], counts["skip"])
+}
+
+// printOutput prints the last lines of a failed step's output
+func printOutput(name, output string) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if output == "" {
+		return
+	}
+	if len(lines) > 10 {
+		lines = lines[len(lines)-10:]
+	}
+	fmt.Printf("      %s:\n", name)
+	for _, line := range lines {
+		fmt.Printf("        %s\n", line)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TroubleshootingLibrary represents a file of reusable troubleshooting issues
+type TroubleshootingLibrary struct {
+	Issues []TroubleshootingIssue `yaml:"issues"`
+}
+
+// LoadCommonTroubleshooting loads the shared troubleshooting issues from
+// common/troubleshooting in the config directory. A missing directory is not an error.
+func (cl *ConfigLoader) LoadCommonTroubleshooting() error {
+	libraryDir := filepath.Join(cl.configDir, "common", "troubleshooting")
+
+	if _, err := os.Stat(libraryDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(libraryDir)
+	if err != nil {
+		return fmt.Errorf("failed to read troubleshooting library directory: %v", err)
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+
+		libraryPath := filepath.Join(libraryDir, file.Name())
+		data, err := ioutil.ReadFile(libraryPath)
+		if err != nil {
+			return fmt.Errorf("failed to read troubleshooting library %s: %v", libraryPath, err)
+		}
+
+		var library TroubleshootingLibrary
+		if err := yaml.Unmarshal(data, &library); err != nil {
+			return fmt.Errorf("failed to parse troubleshooting library %s: %v", libraryPath, err)
+		}
+
+		for _, issue := range library.Issues {
+			if issue.ID == "" {
+				return fmt.Errorf("troubleshooting library %s: issue %q has no id", libraryPath, issue.Issue)
+			}
+			if err := issue.Provenance.validate(); err != nil {
+				return fmt.Errorf("troubleshooting library %s: issue '%s': %v", libraryPath, issue.ID, err)
+			}
+			if err := issue.compileErrorPatterns(); err != nil {
+				return fmt.Errorf("troubleshooting library %s: issue '%s': %v", libraryPath, issue.ID, err)
+			}
+			if _, exists := cl.commonIssues[issue.ID]; exists {
+				return fmt.Errorf("troubleshooting library %s: duplicate issue id '%s'", libraryPath, issue.ID)
+			}
+			cl.commonIssues[issue.ID] = issue
+		}
+	}
+
+	return nil
+}
+
+// GetCommonIssue returns a shared troubleshooting issue by ID
+func (cl *ConfigLoader) GetCommonIssue(id string) (TroubleshootingIssue, bool) {
+	issue, exists := cl.commonIssues[id]
+	return issue, exists
+}
+
+// GetCommonIssueIDs returns the IDs of all shared troubleshooting issues in sorted order
+func (cl *ConfigLoader) GetCommonIssueIDs() []string {
+	ids := make([]string, 0, len(cl.commonIssues))
+	for id := range cl.commonIssues {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// resolveIssueReferences replaces issues that reference the common library
+// with the library entry, keeping the reference so callers can tell them apart.
+// Provenance set on the reference, such as a service-specific verification,
+// replaces the library entry's. Other fields next to a reference would be
+// lost, so they are an error.
+func (cl *ConfigLoader) resolveIssueReferences(config *ServiceConfig) error {
+	for i, issue := range config.Troubleshooting.CommonIssues {
+		if issue.Ref == "" {
+			continue
+		}
+		if fields := localIssueFields(issue); len(fields) > 0 {
+			return fmt.Errorf("troubleshooting issue reference '%s' cannot also set %s; change the shared entry or write a separate issue",
+				issue.Ref, strings.Join(fields, ", "))
+		}
+
+		shared, exists := cl.commonIssues[issue.Ref]
+		if !exists {
+			return fmt.Errorf("unknown troubleshooting issue reference '%s'", issue.Ref)
+		}
+
+		shared.Ref = issue.Ref
+		if !issue.Provenance.IsZero() {
+			shared.Provenance = issue.Provenance
+		}
+		config.Troubleshooting.CommonIssues[i] = shared
+	}
+	return nil
+}
+
+// localIssueFields returns the YAML keys of the content fields set on an
+// issue, leaving out ref and provenance
+func localIssueFields(issue TroubleshootingIssue) []string {
+	var fields []string
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"id", issue.ID != ""},
+		{"issue", issue.Issue != ""},
+		{"solution", issue.Solution != ""},
+		{"solutions", len(issue.Solutions) > 0},
+		{"symptoms", len(issue.Symptoms) > 0},
+		{"causes", len(issue.Causes) > 0},
+		{"prevention", len(issue.Prevention) > 0},
+		{"error_patterns", len(issue.ErrorPatterns) > 0},
+	} {
+		if field.set {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigDir writes files, keyed by slash-separated path, to a new
+// config directory and returns it
+func writeConfigDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const agentLibrary = `issues:
+  - id: agent-not-enrolled
+    issue: Elastic Agent is not enrolled
+    symptoms: [No data in Kibana]
+    solutions: [Enroll the agent in Fleet]
+    verified_on: "2025-01-15"
+  - id: agent-unhealthy
+    issue: Elastic Agent is unhealthy
+    error_patterns: ['agent .* unhealthy']
+`
+
+func TestLoadCommonTroubleshooting(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		ids   []string
+		err   string
+	}{
+		{
+			name: "no library",
+		},
+		{
+			name: "issues from every YAML file",
+			files: map[string]string{
+				"common/troubleshooting/agent.yaml": agentLibrary,
+				"common/troubleshooting/tls.yml":    "issues:\n  - id: tls-unknown-authority\n    issue: Certificate signed by unknown authority\n",
+				"common/troubleshooting/notes.txt":  "not a library",
+			},
+			ids: []string{"agent-not-enrolled", "agent-unhealthy", "tls-unknown-authority"},
+		},
+		{
+			name:  "issue without id",
+			files: map[string]string{"common/troubleshooting/agent.yaml": "issues:\n  - issue: Agent is offline\n"},
+			err:   `agent.yaml: issue "Agent is offline" has no id`,
+		},
+		{
+			name: "duplicate id",
+			files: map[string]string{
+				"common/troubleshooting/agent.yaml": agentLibrary,
+				"common/troubleshooting/fleet.yaml": "issues:\n  - id: agent-unhealthy\n    issue: Agent is unhealthy in Fleet\n",
+			},
+			err: "fleet.yaml: duplicate issue id 'agent-unhealthy'",
+		},
+		{
+			name:  "invalid error pattern",
+			files: map[string]string{"common/troubleshooting/agent.yaml": "issues:\n  - id: agent-offline\n    error_patterns: ['(offline']\n"},
+			err:   "agent.yaml: issue 'agent-offline': invalid error pattern '(offline'",
+		},
+		{
+			name:  "invalid provenance",
+			files: map[string]string{"common/troubleshooting/agent.yaml": "issues:\n  - id: agent-offline\n    verified_on: 15/01/2025\n"},
+			err:   "agent.yaml: issue 'agent-offline': invalid verified_on '15/01/2025'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loader := NewConfigLoader(writeConfigDir(t, test.files))
+			err := loader.LoadCommonTroubleshooting()
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("LoadCommonTroubleshooting error = %v, want it to contain %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCommonTroubleshooting: %v", err)
+			}
+			if ids := loader.GetCommonIssueIDs(); strings.Join(ids, ",") != strings.Join(test.ids, ",") {
+				t.Errorf("GetCommonIssueIDs = %q, want %q", ids, test.ids)
+			}
+		})
+	}
+}
+
+func TestResolveIssueReferences(t *testing.T) {
+	loader := NewConfigLoader(writeConfigDir(t, map[string]string{"common/troubleshooting/agent.yaml": agentLibrary}))
+	if err := loader.LoadCommonTroubleshooting(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		issues     string
+		want       []string
+		verifiedOn []string
+		err        string
+	}{
+		{
+			name:       "local issues and references",
+			issues:     "    - issue: Access log is empty\n    - ref: agent-not-enrolled\n",
+			want:       []string{"|Access log is empty", "agent-not-enrolled|Elastic Agent is not enrolled"},
+			verifiedOn: []string{"", "2025-01-15"},
+		},
+		{
+			name:       "provenance on the reference replaces the library's",
+			issues:     "    - ref: agent-not-enrolled\n      verified_on: \"2025-06-01\"\n      author: nginx-team\n",
+			want:       []string{"agent-not-enrolled|Elastic Agent is not enrolled"},
+			verifiedOn: []string{"2025-06-01"},
+		},
+		{
+			name:   "unknown reference",
+			issues: "    - ref: agent-offline\n",
+			err:    "unknown troubleshooting issue reference 'agent-offline'",
+		},
+		{
+			name:   "fields next to a reference",
+			issues: "    - ref: agent-not-enrolled\n      issue: Agent missing\n      solutions: [Enroll it]\n",
+			err:    "troubleshooting issue reference 'agent-not-enrolled' cannot also set issue, solutions",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := "service_name: nginx\ntroubleshooting:\n  common_issues:\n" + test.issues
+			serviceConfig, err := loader.ParseServiceConfig([]byte(data), "nginx.yaml")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("ParseServiceConfig error = %v, want it to contain %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseServiceConfig: %v", err)
+			}
+
+			var got, verifiedOn []string
+			for _, issue := range serviceConfig.Troubleshooting.CommonIssues {
+				got = append(got, issue.Ref+"|"+issue.Issue)
+				verifiedOn = append(verifiedOn, issue.VerifiedOn)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("issues = %q, want %q", got, test.want)
+			}
+			if !reflect.DeepEqual(verifiedOn, test.verifiedOn) {
+				t.Errorf("verified_on = %q, want %q", verifiedOn, test.verifiedOn)
+			}
+		})
+	}
+}
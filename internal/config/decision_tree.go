@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// validate checks that the start node and every answer lead to a defined
+// node, that every node either asks a question or gives a solution, and that
+// no path loops back on itself, so that every session ends at a solution
+func (t *DecisionTree) validate() error {
+	if len(t.Nodes) == 0 {
+		return fmt.Errorf("decision_tree: no nodes are defined")
+	}
+	if !t.hasNode(t.Start) {
+		return fmt.Errorf("decision_tree: start node '%s' is not defined", t.Start)
+	}
+
+	names := sortedNodeNames(t.Nodes)
+	for _, name := range names {
+		node := t.Nodes[name]
+		if len(node.Answers) == 0 && node.Solution == "" {
+			return fmt.Errorf("decision_tree: node '%s' has neither answers nor a solution", name)
+		}
+		for _, answer := range sortedAnswerKeys(node.Answers) {
+			if next := node.Answers[answer]; !t.hasNode(next) {
+				return fmt.Errorf("decision_tree: answer '%s' of node '%s' leads to unknown node '%s'", answer, name, next)
+			}
+		}
+	}
+
+	// Depth-first search: a node reached again while still on the path closes a cycle
+	finished := make(map[string]bool)
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		for index, onPath := range path {
+			if onPath == name {
+				return fmt.Errorf("decision_tree: nodes form a cycle: %s -> %s", strings.Join(path[index:], " -> "), name)
+			}
+		}
+		if finished[name] {
+			return nil
+		}
+		path = append(path, name)
+		node := t.Nodes[name]
+		for _, answer := range sortedAnswerKeys(node.Answers) {
+			if err := visit(node.Answers[answer]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		finished[name] = true
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedNodeNames(nodes map[string]DecisionNode) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedAnswerKeys(answers map[string]string) []string {
+	keys := make([]string, 0, len(answers))
+	for answer := range answers {
+		keys = append(keys, answer)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (t *DecisionTree) hasNode(name string) bool {
+	_, exists := t.Nodes[name]
+	return exists
+}
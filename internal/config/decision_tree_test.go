@@ -0,0 +1,113 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseServiceConfigDecisionTree(t *testing.T) {
+	config := func(tree string) string {
+		return "service_name: demo\ntroubleshooting:\n  decision_tree:\n" + tree
+	}
+
+	tests := []struct {
+		name string
+		tree string
+		err  string
+	}{
+		{
+			name: "valid",
+			tree: `    start: running
+    nodes:
+      running:
+        question: Is the service running?
+        answers:
+          "yes": logs
+          "no": start
+      logs:
+        question: Are events indexed?
+        answers:
+          "yes": done
+          "no": start
+      start:
+        solution: Start the service
+      done:
+        solution: Nothing to fix
+`,
+		},
+		{
+			name: "no nodes",
+			tree: "    start: running\n",
+			err:  "troubleshooting: decision_tree: no nodes are defined",
+		},
+		{
+			name: "unknown start node",
+			tree: "    start: runing\n    nodes:\n      running:\n        solution: Start the service\n",
+			err:  "troubleshooting: decision_tree: start node 'runing' is not defined",
+		},
+		{
+			name: "unknown answer target",
+			tree: `    start: running
+    nodes:
+      running:
+        question: Is the service running?
+        answers:
+          "yes": done
+          "no": strat
+      done:
+        solution: Nothing to fix
+`,
+			err: "troubleshooting: decision_tree: answer 'no' of node 'running' leads to unknown node 'strat'",
+		},
+		{
+			name: "node without answers or solution",
+			tree: "    start: running\n    nodes:\n      running:\n        question: Is the service running?\n",
+			err:  "troubleshooting: decision_tree: node 'running' has neither answers nor a solution",
+		},
+		{
+			name: "cycle",
+			tree: `    start: running
+    nodes:
+      running:
+        question: Is the service running?
+        answers:
+          "yes": logs
+          "no": done
+      logs:
+        question: Are events indexed?
+        answers:
+          "yes": done
+          "no": restart
+      restart:
+        question: Did a restart help?
+        answers:
+          "yes": done
+          "no": running
+      done:
+        solution: Nothing to fix
+`,
+			err: "troubleshooting: decision_tree: nodes form a cycle: logs -> restart -> running -> logs",
+		},
+		{
+			name: "self loop",
+			tree: "    start: running\n    nodes:\n      running:\n        question: Is the service running?\n        answers:\n          \"no\": running\n",
+			err:  "troubleshooting: decision_tree: nodes form a cycle: running -> running",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(config(test.tree)), "demo.yaml")
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("ParseServiceConfig: %v", err)
+				}
+				return
+			}
+			want := "invalid config file demo.yaml: " + test.err
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("ParseServiceConfig error = %v, want %q", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of configuration changes
+const (
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeChanged   = "changed"
+	ChangeReordered = "reordered"
+)
+
+// maxDiffText bounds the length of before and after values quoted in change details
+const maxDiffText = 120
+
+// ConfigChange is one semantic difference between two versions of a service configuration
+type ConfigChange struct {
+	// Section is the part of the configuration, such as "Installation steps"
+	Section string `json:"section"`
+	Kind    string `json:"kind"`
+	// Item names what changed, such as a step or an issue
+	Item    string   `json:"item"`
+	Details []string `json:"details,omitempty"`
+}
+
+// DiffServiceConfigs reports the semantic changes from one version of a
+// service configuration to another. Steps are matched by title, Kibana steps
+// by instruction and troubleshooting issues by reference or issue text, so
+// renumbering a step reports a reorder rather than a change.
+func DiffServiceConfigs(before, after *ServiceConfig) []ConfigChange {
+	var changes []ConfigChange
+	changes = append(changes, diffText("Overview", "Title", before.Title, after.Title)...)
+	changes = append(changes, diffText("Overview", "Description", before.Description, after.Description)...)
+	changes = append(changes, diffList("Overview", "category", before.Categories, after.Categories)...)
+	changes = append(changes, diffList("Use cases", "use case", before.ServiceInfo.CommonUseCases, after.ServiceInfo.CommonUseCases)...)
+	changes = append(changes, diffList("Data types", "data type", before.ServiceInfo.DataTypesCollected, after.ServiceInfo.DataTypesCollected)...)
+
+	changes = append(changes, diffRange("Compatibility", "Elastic Stack versions",
+		before.ServiceInfo.Compatibility.ElasticStackVersions, after.ServiceInfo.Compatibility.ElasticStackVersions)...)
+	changes = append(changes, diffRange("Compatibility", "Service versions",
+		before.ServiceInfo.Compatibility.ServiceVersions, after.ServiceInfo.Compatibility.ServiceVersions)...)
+
+	oldScaling, newScaling := before.ServiceInfo.ScalingAndPerformance, after.ServiceInfo.ScalingAndPerformance
+	changes = append(changes, diffText("Scaling and performance", "Description", oldScaling.Description, newScaling.Description)...)
+	changes = append(changes, diffList("Scaling and performance", "performance expectation", oldScaling.PerformanceExpectations, newScaling.PerformanceExpectations)...)
+	changes = append(changes, diffList("Scaling and performance", "scaling guidance", oldScaling.ScalingGuidance, newScaling.ScalingGuidance)...)
+
+	changes = append(changes, diffList("Prerequisites", "prerequisite", before.SetupInstructions.Prerequisites, after.SetupInstructions.Prerequisites)...)
+	changes = append(changes, diffSequence("Installation steps", before.SetupInstructions.InstallationSteps, after.SetupInstructions.InstallationSteps,
+		func(step InstallationStep) string { return normalizeKey(step.Title) },
+		func(step InstallationStep) string { return fmt.Sprintf("Step %d: %s", step.Step, step.Title) },
+		diffInstallationStep)...)
+
+	changes = append(changes, diffKibanaSetup(before.KibanaSetupInstructions, after.KibanaSetupInstructions)...)
+
+	changes = append(changes, diffSequence("Validation steps", before.ValidationSteps.Steps, after.ValidationSteps.Steps,
+		func(step ValidationStep) string { return normalizeKey(step.Title) },
+		func(step ValidationStep) string { return fmt.Sprintf("Step %d: %s", step.Step, step.Title) },
+		diffValidationStep)...)
+
+	changes = append(changes, diffSequence("Troubleshooting", before.Troubleshooting.CommonIssues, after.Troubleshooting.CommonIssues,
+		issueKey, issueLabel, diffIssue)...)
+	changes = append(changes, diffList("Troubleshooting", "diagnostic command", before.Troubleshooting.DiagnosticCommands, after.Troubleshooting.DiagnosticCommands)...)
+	changes = append(changes, diffList("Troubleshooting", "log location", before.Troubleshooting.LogLocations, after.Troubleshooting.LogLocations)...)
+	changes = append(changes, diffList("Troubleshooting", "support resource", before.Troubleshooting.SupportResources, after.Troubleshooting.SupportResources)...)
+	changes = append(changes, diffDecisionTree(before.Troubleshooting.DecisionTree, after.Troubleshooting.DecisionTree)...)
+
+	changes = append(changes, diffList("Documentation sites", "documentation site", before.DocumentationSites, after.DocumentationSites)...)
+	changes = append(changes, diffStatuses(before, after)...)
+	return changes
+}
+
+// diffStatuses reports sections whose review status changed. Statuses are
+// compared as they apply, so sections inheriting the service status change
+// along with it.
+func diffStatuses(before, after *ServiceConfig) []ConfigChange {
+	oldStatuses := make(map[string]string)
+	for _, section := range before.ContentSections() {
+		oldStatuses[section.Name] = before.SectionStatus(section.Status)
+	}
+	var changes []ConfigChange
+	for _, section := range after.ContentSections() {
+		oldStatus, ok := oldStatuses[section.Name]
+		if status := after.SectionStatus(section.Status); ok && status != oldStatus {
+			changes = append(changes, ConfigChange{Section: "Review status", Kind: ChangeChanged, Item: section.Name,
+				Details: []string{fmt.Sprintf("from %s to %s", oldStatus, status)}})
+		}
+	}
+	return changes
+}
+
+// diffSequence matches the items of two lists by key and reports items that
+// were added, removed, changed or moved relative to the items they share
+func diffSequence[T any](section string, before, after []T, key func(T) string, label func(T) string, compare func(before, after T) []string) []ConfigChange {
+	oldIndex := make(map[string]int, len(before))
+	for index, item := range before {
+		oldIndex[key(item)] = index
+	}
+	newIndex := make(map[string]int, len(after))
+	for index, item := range after {
+		newIndex[key(item)] = index
+	}
+
+	var changes []ConfigChange
+	for _, item := range before {
+		if _, exists := newIndex[key(item)]; !exists {
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeRemoved, Item: label(item)})
+		}
+	}
+
+	// Items outside the longest run of shared items that kept their relative
+	// order are the ones that moved; the rest only shifted
+	var order []int
+	for _, item := range after {
+		if index, exists := oldIndex[key(item)]; exists {
+			order = append(order, index)
+		}
+	}
+	kept := longestIncreasing(order)
+
+	for _, item := range after {
+		index, exists := oldIndex[key(item)]
+		if !exists {
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeAdded, Item: label(item)})
+			continue
+		}
+		if !kept[index] {
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeReordered, Item: label(item),
+				Details: []string{fmt.Sprintf("moved from position %d to %d", index+1, newIndex[key(item)]+1)}})
+		}
+		if details := compare(before[index], item); len(details) > 0 {
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeChanged, Item: label(item), Details: details})
+		}
+	}
+	return changes
+}
+
+// longestIncreasing returns the values of a longest increasing subsequence
+func longestIncreasing(values []int) map[int]bool {
+	lengths := make([]int, len(values))
+	previous := make([]int, len(values))
+	best := -1
+	for i := range values {
+		lengths[i], previous[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if values[j] < values[i] && lengths[j]+1 > lengths[i] {
+				lengths[i], previous[i] = lengths[j]+1, j
+			}
+		}
+		if best < 0 || lengths[i] > lengths[best] {
+			best = i
+		}
+	}
+
+	kept := make(map[int]bool, len(values))
+	for i := best; i >= 0; i = previous[i] {
+		kept[values[i]] = true
+	}
+	return kept
+}
+
+// diffList reports the entries added to and removed from a list of strings
+func diffList(section, name string, before, after []string) []ConfigChange {
+	added, removed := listChanges(before, after)
+	var changes []ConfigChange
+	for _, entry := range removed {
+		changes = append(changes, ConfigChange{Section: section, Kind: ChangeRemoved, Item: fmt.Sprintf("%s: %s", name, entry)})
+	}
+	for _, entry := range added {
+		changes = append(changes, ConfigChange{Section: section, Kind: ChangeAdded, Item: fmt.Sprintf("%s: %s", name, entry)})
+	}
+	return changes
+}
+
+// diffRange reports a changed list of version ranges as one change
+func diffRange(section, name string, before, after []string) []ConfigChange {
+	oldRange, newRange := strings.Join(before, ", "), strings.Join(after, ", ")
+	if oldRange == newRange {
+		return nil
+	}
+	return []ConfigChange{{Section: section, Kind: ChangeChanged, Item: name,
+		Details: []string{fmt.Sprintf("from %s to %s", quoteRange(oldRange), quoteRange(newRange))}}}
+}
+
+func diffText(section, name, before, after string) []ConfigChange {
+	if detail := textDetail("value", before, after); detail != "" {
+		return []ConfigChange{{Section: section, Kind: ChangeChanged, Item: name, Details: []string{detail}}}
+	}
+	return nil
+}
+
+func diffInstallationStep(before, after InstallationStep) []string {
+	var details []string
+	details = appendText(details, "description", before.Description, after.Description)
+	details = append(details, listDetails("command", before.Commands, after.Commands)...)
+	details = append(details, diffSnippets(before.ConfigSnippets, after.ConfigSnippets)...)
+	details = appendText(details, "verification", before.Verification, after.Verification)
+	details = append(details, listDetails("platform restriction", before.OnlyPlatforms, after.OnlyPlatforms)...)
+	details = appendText(details, "version range", before.VersionRange, after.VersionRange)
+	details = append(details, diffProvenance(before.Provenance, after.Provenance)...)
+
+	for _, platform := range unionKeys(before.Platforms, after.Platforms) {
+		oldVariant, inOld := before.Platforms[platform]
+		newVariant, inNew := after.Platforms[platform]
+		switch {
+		case !inOld:
+			details = append(details, fmt.Sprintf("added %s variant", platform))
+		case !inNew:
+			details = append(details, fmt.Sprintf("removed %s variant", platform))
+		default:
+			details = append(details, prefixDetails(platform+" variant", diffVariant(oldVariant, newVariant))...)
+		}
+	}
+
+	oldVersions := make(map[string]PlatformVariant)
+	for _, variant := range before.Versions {
+		oldVersions[variant.Range] = variant.PlatformVariant
+	}
+	newVersions := make(map[string]PlatformVariant)
+	for _, variant := range after.Versions {
+		newVersions[variant.Range] = variant.PlatformVariant
+	}
+	for _, versionRange := range unionKeys(oldVersions, newVersions) {
+		oldVariant, inOld := oldVersions[versionRange]
+		newVariant, inNew := newVersions[versionRange]
+		switch {
+		case !inOld:
+			details = append(details, fmt.Sprintf("added variant for versions %s", versionRange))
+		case !inNew:
+			details = append(details, fmt.Sprintf("removed variant for versions %s", versionRange))
+		default:
+			details = append(details, prefixDetails("variant for versions "+versionRange, diffVariant(oldVariant, newVariant))...)
+		}
+	}
+	return details
+}
+
+func diffVariant(before, after PlatformVariant) []string {
+	var details []string
+	details = appendText(details, "description", before.Description, after.Description)
+	details = append(details, listDetails("command", before.Commands, after.Commands)...)
+	details = append(details, diffSnippets(before.ConfigSnippets, after.ConfigSnippets)...)
+	return appendText(details, "verification", before.Verification, after.Verification)
+}
+
+func diffSnippets(before, after []ConfigSnippet) []string {
+	oldSnippets := make(map[string]ConfigSnippet)
+	for _, snippet := range before {
+		oldSnippets[snippet.Filename] = snippet
+	}
+	newSnippets := make(map[string]ConfigSnippet)
+	for _, snippet := range after {
+		newSnippets[snippet.Filename] = snippet
+	}
+
+	var details []string
+	for _, filename := range unionKeys(oldSnippets, newSnippets) {
+		oldSnippet, inOld := oldSnippets[filename]
+		newSnippet, inNew := newSnippets[filename]
+		switch {
+		case !inOld:
+			details = append(details, fmt.Sprintf("added config snippet %s", filename))
+		case !inNew:
+			details = append(details, fmt.Sprintf("removed config snippet %s", filename))
+		default:
+			if oldSnippet.Content != newSnippet.Content {
+				added, removed := listChanges(strings.Split(oldSnippet.Content, "\n"), strings.Split(newSnippet.Content, "\n"))
+				details = append(details, fmt.Sprintf("changed config snippet %s (%d lines added, %d removed)", filename, len(added), len(removed)))
+			}
+			details = append(details, prefixDetails("config snippet "+filename, diffProvenance(oldSnippet.Provenance, newSnippet.Provenance))...)
+		}
+	}
+	return details
+}
+
+func diffKibanaSetup(before, after KibanaSetupInstructions) []ConfigChange {
+	var changes []ConfigChange
+	for _, inputType := range unionKeys(before, after) {
+		oldSteps, inOld := before[inputType]
+		newSteps, inNew := after[inputType]
+		switch {
+		case !inOld:
+			changes = append(changes, ConfigChange{Section: "Kibana setup", Kind: ChangeAdded,
+				Item: fmt.Sprintf("input type %s (%d steps)", inputType, len(newSteps.Steps))})
+		case !inNew:
+			changes = append(changes, ConfigChange{Section: "Kibana setup", Kind: ChangeRemoved,
+				Item: fmt.Sprintf("input type %s", inputType)})
+		default:
+			changes = append(changes, diffSequence("Kibana setup", oldSteps.Steps, newSteps.Steps,
+				func(step KibanaSetupStep) string { return normalizeKey(step.Instruction) },
+				func(step KibanaSetupStep) string {
+					return fmt.Sprintf("%s step %d: %s", inputType, step.Step, step.Instruction)
+				},
+				func(before, after KibanaSetupStep) []string {
+					details := appendText(nil, "version range", before.VersionRange, after.VersionRange)
+					return append(details, diffProvenance(before.Provenance, after.Provenance)...)
+				})...)
+		}
+	}
+	return changes
+}
+
+func diffValidationStep(before, after ValidationStep) []string {
+	var details []string
+	details = appendText(details, "description", before.Description, after.Description)
+	details = append(details, listDetails("command", before.Commands, after.Commands)...)
+	details = appendText(details, "expected output", before.ExpectedOutput, after.ExpectedOutput)
+	details = append(details, diffProvenance(before.Provenance, after.Provenance)...)
+
+	oldExpect, newExpect := before.Expect, after.Expect
+	if oldExpect == nil {
+		oldExpect = &StepExpectation{}
+	}
+	if newExpect == nil {
+		newExpect = &StepExpectation{}
+	}
+	if oldExpect.WantExitCode() != newExpect.WantExitCode() {
+		details = append(details, fmt.Sprintf("expected exit code changed from %d to %d", oldExpect.WantExitCode(), newExpect.WantExitCode()))
+	}
+	details = appendText(details, "expected stdout", oldExpect.Stdout, newExpect.Stdout)
+	details = appendText(details, "expected JSON path", oldExpect.JSONPath, newExpect.JSONPath)
+	details = appendText(details, "expected JSON value", oldExpect.JSONValue, newExpect.JSONValue)
+	return appendText(details, "timeout", oldExpect.Timeout, newExpect.Timeout)
+}
+
+func issueKey(issue TroubleshootingIssue) string {
+	if issue.Ref != "" {
+		return "ref:" + issue.Ref
+	}
+	return normalizeKey(issue.Issue)
+}
+
+func issueLabel(issue TroubleshootingIssue) string {
+	if issue.Ref != "" {
+		return fmt.Sprintf("%s (shared entry %s)", issue.Issue, issue.Ref)
+	}
+	return issue.Issue
+}
+
+func diffIssue(before, after TroubleshootingIssue) []string {
+	var details []string
+	details = append(details, listDetails("symptom", before.Symptoms, after.Symptoms)...)
+	details = append(details, listDetails("cause", before.Causes, after.Causes)...)
+	details = append(details, listDetails("solution", before.AllSolutions(), after.AllSolutions())...)
+	details = append(details, listDetails("prevention", before.Prevention, after.Prevention)...)
+	details = append(details, listDetails("error pattern", before.ErrorPatterns, after.ErrorPatterns)...)
+	return append(details, diffProvenance(before.Provenance, after.Provenance)...)
+}
+
+func diffProvenance(before, after Provenance) []string {
+	var details []string
+	details = appendText(details, "source URL", before.SourceURL, after.SourceURL)
+	details = appendText(details, "verified_on", before.VerifiedOn, after.VerifiedOn)
+	details = append(details, listDetails("verified version", before.VerifiedVersions, after.VerifiedVersions)...)
+	return appendText(details, "author", before.Author, after.Author)
+}
+
+func diffDecisionTree(before, after *DecisionTree) []ConfigChange {
+	const section = "Decision tree"
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []ConfigChange{{Section: section, Kind: ChangeAdded, Item: fmt.Sprintf("decision tree (%d nodes)", len(after.Nodes))}}
+	case after == nil:
+		return []ConfigChange{{Section: section, Kind: ChangeRemoved, Item: "decision tree"}}
+	}
+
+	var changes []ConfigChange
+	if before.Start != after.Start {
+		changes = append(changes, ConfigChange{Section: section, Kind: ChangeChanged, Item: "start node",
+			Details: []string{fmt.Sprintf("from %s to %s", before.Start, after.Start)}})
+	}
+	for _, id := range unionKeys(before.Nodes, after.Nodes) {
+		oldNode, inOld := before.Nodes[id]
+		newNode, inNew := after.Nodes[id]
+		switch {
+		case !inOld:
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeAdded, Item: "node " + id})
+		case !inNew:
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeRemoved, Item: "node " + id})
+		case !reflect.DeepEqual(oldNode, newNode):
+			var details []string
+			details = appendText(details, "question", oldNode.Question, newNode.Question)
+			details = append(details, listDetails("command", oldNode.Commands, newNode.Commands)...)
+			details = appendText(details, "solution", oldNode.Solution, newNode.Solution)
+			for _, answer := range unionKeys(oldNode.Answers, newNode.Answers) {
+				details = appendText(details, fmt.Sprintf("answer %q", answer), oldNode.Answers[answer], newNode.Answers[answer])
+			}
+			changes = append(changes, ConfigChange{Section: section, Kind: ChangeChanged, Item: "node " + id, Details: details})
+		}
+	}
+	return changes
+}
+
+// listChanges returns the entries only in after and only in before, in list order
+func listChanges(before, after []string) (added, removed []string) {
+	oldEntries := make(map[string]bool, len(before))
+	for _, entry := range before {
+		oldEntries[entry] = true
+	}
+	newEntries := make(map[string]bool, len(after))
+	for _, entry := range after {
+		newEntries[entry] = true
+		if !oldEntries[entry] {
+			added = append(added, entry)
+		}
+	}
+	for _, entry := range before {
+		if !newEntries[entry] {
+			removed = append(removed, entry)
+		}
+	}
+	return added, removed
+}
+
+func listDetails(name string, before, after []string) []string {
+	added, removed := listChanges(before, after)
+	var details []string
+	for _, entry := range removed {
+		details = append(details, fmt.Sprintf("removed %s: %s", name, entry))
+	}
+	for _, entry := range added {
+		details = append(details, fmt.Sprintf("added %s: %s", name, entry))
+	}
+	return details
+}
+
+func appendText(details []string, name, before, after string) []string {
+	if detail := textDetail(name, before, after); detail != "" {
+		return append(details, detail)
+	}
+	return details
+}
+
+func textDetail(name, before, after string) string {
+	before, after = strings.TrimSpace(before), strings.TrimSpace(after)
+	switch {
+	case before == after:
+		return ""
+	case before == "":
+		return fmt.Sprintf("added %s %q", name, truncateText(after))
+	case after == "":
+		return fmt.Sprintf("removed %s %q", name, truncateText(before))
+	default:
+		return fmt.Sprintf("%s changed from %q to %q", name, truncateText(before), truncateText(after))
+	}
+}
+
+func prefixDetails(prefix string, details []string) []string {
+	for index, detail := range details {
+		details[index] = prefix + ": " + detail
+	}
+	return details
+}
+
+func quoteRange(versionRange string) string {
+	if versionRange == "" {
+		return "(none)"
+	}
+	return "`" + versionRange + "`"
+}
+
+func truncateText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxDiffText {
+		return text[:maxDiffText] + "..."
+	}
+	return text
+}
+
+func normalizeKey(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// unionKeys returns the keys of both maps in sorted order
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatChanges formats changes as "section | kind | item | details"
+func formatChanges(changes []ConfigChange) []string {
+	var formatted []string
+	for _, change := range changes {
+		formatted = append(formatted, fmt.Sprintf("%s | %s | %s | %s", change.Section, change.Kind, change.Item, strings.Join(change.Details, "; ")))
+	}
+	return formatted
+}
+
+func TestDiffServiceConfigs(t *testing.T) {
+	steps := func(titles ...string) SetupInstructions {
+		var setup SetupInstructions
+		for index, title := range titles {
+			setup.InstallationSteps = append(setup.InstallationSteps, InstallationStep{Step: index + 1, Title: title})
+		}
+		return setup
+	}
+
+	tests := []struct {
+		name   string
+		before ServiceConfig
+		after  ServiceConfig
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			before: ServiceConfig{Title: "Nginx", SetupInstructions: steps("Install", "Configure")},
+			after:  ServiceConfig{Title: "Nginx", SetupInstructions: steps("Install", "Configure")},
+		},
+		{
+			name:   "renumbered step after an insert is not a reorder",
+			before: ServiceConfig{SetupInstructions: steps("Install", "Configure")},
+			after:  ServiceConfig{SetupInstructions: steps("Download", "Install", "Configure")},
+			want:   []string{"Installation steps | added | Step 1: Download | "},
+		},
+		{
+			name:   "moved and removed steps",
+			before: ServiceConfig{SetupInstructions: steps("Install", "Configure", "Start", "Verify")},
+			after:  ServiceConfig{SetupInstructions: steps("Verify", "Install", "Start")},
+			want: []string{
+				"Installation steps | removed | Step 2: Configure | ",
+				"Installation steps | reordered | Step 1: Verify | moved from position 4 to 1",
+			},
+		},
+		{
+			name: "changed commands and platform variants",
+			before: ServiceConfig{SetupInstructions: SetupInstructions{InstallationSteps: []InstallationStep{{
+				Step: 1, Title: "Install", Commands: []string{"apt-get update", "apt-get install nginx"},
+				Platforms: map[string]PlatformVariant{"rhel": {Commands: []string{"yum install nginx"}}, "macos": {}},
+			}}}},
+			after: ServiceConfig{SetupInstructions: SetupInstructions{InstallationSteps: []InstallationStep{{
+				Step: 1, Title: " install ", Commands: []string{"apt-get update", "apt-get install -y nginx"},
+				Platforms: map[string]PlatformVariant{"rhel": {Commands: []string{"dnf install nginx"}}, "docker": {}},
+			}}}},
+			want: []string{"Installation steps | changed | Step 1:  install  | " + strings.Join([]string{
+				"removed command: apt-get install nginx",
+				"added command: apt-get install -y nginx",
+				"added docker variant",
+				"removed macos variant",
+				"rhel variant: removed command: yum install nginx",
+				"rhel variant: added command: dnf install nginx",
+			}, "; ")},
+		},
+		{
+			name: "compatibility ranges and troubleshooting issues",
+			before: ServiceConfig{
+				ServiceInfo: ServiceInfo{Compatibility: Compatibility{ElasticStackVersions: []string{"^8.13.0"}}},
+				Troubleshooting: Troubleshooting{CommonIssues: []TroubleshootingIssue{
+					{Issue: "No data", Solutions: []string{"Check the agent"}},
+					{Ref: "agent-not-enrolled", Issue: "Agent is not enrolled"},
+				}},
+			},
+			after: ServiceConfig{
+				ServiceInfo: ServiceInfo{Compatibility: Compatibility{
+					ElasticStackVersions: []string{"^8.13.0", "^9.0.0"},
+					ServiceVersions:      []string{">=1.20"},
+				}},
+				Troubleshooting: Troubleshooting{CommonIssues: []TroubleshootingIssue{
+					{Issue: "No data", Solutions: []string{"Check the agent", "Check the output"}},
+					{Issue: "Permission denied"},
+				}},
+			},
+			want: []string{
+				"Compatibility | changed | Elastic Stack versions | from `^8.13.0` to `^8.13.0, ^9.0.0`",
+				"Compatibility | changed | Service versions | from (none) to `>=1.20`",
+				"Troubleshooting | removed | Agent is not enrolled (shared entry agent-not-enrolled) | ",
+				"Troubleshooting | changed | No data | added solution: Check the output",
+				"Troubleshooting | added | Permission denied | ",
+			},
+		},
+		{
+			name: "decision tree nodes",
+			before: ServiceConfig{Troubleshooting: Troubleshooting{DecisionTree: &DecisionTree{Start: "running", Nodes: map[string]DecisionNode{
+				"running": {Question: "Is it running?", Answers: map[string]string{"no": "start"}},
+				"start":   {Solution: "Start it"},
+			}}}},
+			after: ServiceConfig{Troubleshooting: Troubleshooting{DecisionTree: &DecisionTree{Start: "running", Nodes: map[string]DecisionNode{
+				"running": {Question: "Is the service running?", Answers: map[string]string{"no": "start", "yes": "done"}},
+				"start":   {Solution: "Start it"},
+				"done":    {Solution: "Nothing to do"},
+			}}}},
+			want: []string{
+				"Decision tree | added | node done | ",
+				`Decision tree | changed | node running | question changed from "Is it running?" to "Is the service running?"; added answer "yes" "done"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := formatChanges(DiffServiceConfigs(&test.before, &test.after))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DiffServiceConfigs =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLongestIncreasing(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   []int
+	}{
+		{values: nil, want: nil},
+		{values: []int{0, 1, 2}, want: []int{0, 1, 2}},
+		{values: []int{2, 0, 1}, want: []int{0, 1}},
+		{values: []int{3, 0, 1, 2}, want: []int{0, 1, 2}},
+	}
+	for _, test := range tests {
+		kept := longestIncreasing(test.values)
+		var got []int
+		for _, value := range test.values {
+			if kept[value] {
+				got = append(got, value)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("longestIncreasing(%v) keeps %v, want %v", test.values, got, test.want)
+		}
+	}
+}
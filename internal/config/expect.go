@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// StepExpectation represents machine-checkable assertions on the result of a
+// validation step's commands. Every assertion that is set must hold.
+type StepExpectation struct {
+	ExitCode  *int   `yaml:"exit_code,omitempty"`
+	Stdout    string `yaml:"stdout,omitempty"`
+	JSONPath  string `yaml:"json_path,omitempty"`
+	JSONValue string `yaml:"json_value,omitempty"`
+	Timeout   string `yaml:"timeout,omitempty"`
+}
+
+// WantExitCode returns the expected exit code, which is 0 unless set
+func (e *StepExpectation) WantExitCode() int {
+	if e == nil || e.ExitCode == nil {
+		return 0
+	}
+	return *e.ExitCode
+}
+
+// TimeoutDuration returns the step timeout, or fallback when none is set
+func (e *StepExpectation) TimeoutDuration(fallback time.Duration) time.Duration {
+	if e == nil || e.Timeout == "" {
+		return fallback
+	}
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil {
+		return fallback
+	}
+	return timeout
+}
+
+func (e *StepExpectation) validate() error {
+	if e.Stdout != "" {
+		if _, err := regexp.Compile(e.Stdout); err != nil {
+			return fmt.Errorf("invalid stdout pattern: %v", err)
+		}
+	}
+	if e.JSONValue != "" && e.JSONPath == "" {
+		return fmt.Errorf("json_value requires json_path")
+	}
+	if e.Timeout != "" {
+		if timeout, err := time.ParseDuration(e.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid timeout '%s'", e.Timeout)
+		}
+	}
+	return nil
+}
+
+// validateExpectations checks the expect blocks of validation steps
+func validateExpectations(steps []ValidationStep) error {
+	for _, step := range steps {
+		if step.Expect == nil {
+			continue
+		}
+		if err := step.Expect.validate(); err != nil {
+			return fmt.Errorf("validation step %d (%s): %v", step.Step, step.Title, err)
+		}
+	}
+	return nil
+}
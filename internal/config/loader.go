@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -15,6 +17,8 @@ type ServiceConfig struct {
 	ServiceName             string                  `yaml:"service_name"`
 	Title                   string                  `yaml:"title"`
 	Description             string                  `yaml:"description"`
+	Categories              []string                `yaml:"categories,omitempty"`
+	Status                  string                  `yaml:"status,omitempty"`
 	ServiceInfo             ServiceInfo             `yaml:"service_info"`
 	SetupInstructions       SetupInstructions       `yaml:"setup_instructions"`
 	KibanaSetupInstructions KibanaSetupInstructions `yaml:"kibana_setup_instructions"`
@@ -25,6 +29,7 @@ type ServiceConfig struct {
 
 // ServiceInfo represents service information for get_service_info tool
 type ServiceInfo struct {
+	Status                string                `yaml:"status,omitempty"`
 	CommonUseCases        []string              `yaml:"common_use_cases"`
 	DataTypesCollected    []string              `yaml:"data_types_collected"`
 	Compatibility         Compatibility         `yaml:"compatibility"`
@@ -46,85 +51,244 @@ type ScalingAndPerformance struct {
 
 // SetupInstructions represents setup instructions for get_service_setup_instructions tool
 type SetupInstructions struct {
+	Status            string             `yaml:"status,omitempty"`
 	Prerequisites     []string           `yaml:"prerequisites"`
 	InstallationSteps []InstallationStep `yaml:"installation_steps"`
 }
 
-// InstallationStep represents a single installation step
+// InstallationStep represents a single installation step. Platforms holds
+// per-platform overrides; OnlyPlatforms restricts the step to the listed platforms.
+// Versions holds overrides for service version ranges, and VersionRange
+// restricts the step to service versions in that range.
 type InstallationStep struct {
-	Step           int             `yaml:"step"`
-	Title          string          `yaml:"title"`
-	Description    string          `yaml:"description"`
-	Commands       []string        `yaml:"commands,omitempty"`
-	ConfigSnippets []ConfigSnippet `yaml:"config_snippets,omitempty"`
-	Verification   string          `yaml:"verification,omitempty"`
+	Step           int                        `yaml:"step"`
+	Title          string                     `yaml:"title"`
+	Description    string                     `yaml:"description"`
+	Commands       []string                   `yaml:"commands,omitempty"`
+	ConfigSnippets []ConfigSnippet            `yaml:"config_snippets,omitempty"`
+	Verification   string                     `yaml:"verification,omitempty"`
+	Platforms      map[string]PlatformVariant `yaml:"platforms,omitempty"`
+	OnlyPlatforms  []string                   `yaml:"only_platforms,omitempty"`
+	Versions       []VersionVariant           `yaml:"versions,omitempty"`
+	VersionRange   string                     `yaml:"version_range,omitempty"`
+	Provenance     `yaml:",inline"`
 }
 
 // ConfigSnippet represents a configuration snippet
 type ConfigSnippet struct {
-	Filename string `yaml:"filename"`
-	Content  string `yaml:"content"`
+	Filename   string `yaml:"filename"`
+	Content    string `yaml:"content"`
+	Provenance `yaml:",inline"`
 }
 
-// KibanaSetupInstructions represents Kibana setup instructions
-type KibanaSetupInstructions struct {
-	Default KibanaSetupSteps `yaml:"default"`
-	TCP     KibanaSetupSteps `yaml:"tcp,omitempty"`
-	UDP     KibanaSetupSteps `yaml:"udp,omitempty"`
+// KibanaSetupInstructions represents Kibana setup instructions keyed by
+// input type (for example aws-s3, httpjson or tcp). The "default" entry is
+// used when no input type is requested.
+type KibanaSetupInstructions map[string]KibanaSetupSteps
+
+// DefaultKibanaInputType is the key of the steps used when no input type is requested
+const DefaultKibanaInputType = "default"
+
+// InputTypes returns the configured input types in sorted order
+func (k KibanaSetupInstructions) InputTypes() []string {
+	inputTypes := make([]string, 0, len(k))
+	for inputType := range k {
+		inputTypes = append(inputTypes, inputType)
+	}
+	sort.Strings(inputTypes)
+	return inputTypes
+}
+
+// Lookup returns the steps for an input type. Matching ignores case and
+// treats hyphens and underscores alike, so aws_s3 finds aws-s3.
+func (k KibanaSetupInstructions) Lookup(inputType string) (KibanaSetupSteps, bool) {
+	if inputType == "" {
+		inputType = DefaultKibanaInputType
+	}
+	wanted := normalizeInputType(inputType)
+	for name, steps := range k {
+		if normalizeInputType(name) == wanted {
+			return steps, true
+		}
+	}
+	return KibanaSetupSteps{}, false
+}
+
+func normalizeInputType(inputType string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(inputType)), "_", "-")
 }
 
 // KibanaSetupSteps represents steps for Kibana setup
 type KibanaSetupSteps struct {
-	Steps []KibanaSetupStep `yaml:"steps"`
+	Status string            `yaml:"status,omitempty"`
+	Steps  []KibanaSetupStep `yaml:"steps"`
 }
 
 // KibanaSetupStep represents a single Kibana setup step
 type KibanaSetupStep struct {
-	Step        int    `yaml:"step"`
-	Instruction string `yaml:"instruction"`
+	Step         int    `yaml:"step"`
+	Instruction  string `yaml:"instruction"`
+	VersionRange string `yaml:"version_range,omitempty"`
+	Provenance   `yaml:",inline"`
 }
 
 // Troubleshooting represents troubleshooting information
 type Troubleshooting struct {
-	CommonIssues []TroubleshootingIssue `yaml:"common_issues"`
+	Status             string                 `yaml:"status,omitempty"`
+	CommonIssues       []TroubleshootingIssue `yaml:"common_issues"`
+	DiagnosticCommands []string               `yaml:"diagnostic_commands,omitempty"`
+	LogLocations       []string               `yaml:"log_locations,omitempty"`
+	SupportResources   []string               `yaml:"support_resources,omitempty"`
+	DecisionTree       *DecisionTree          `yaml:"decision_tree,omitempty"`
+}
+
+// DecisionTree represents a guided diagnosis as a graph of question nodes
+type DecisionTree struct {
+	Start string                  `yaml:"start"`
+	Nodes map[string]DecisionNode `yaml:"nodes"`
 }
 
-// TroubleshootingIssue represents a troubleshooting issue
+// DecisionNode represents a single question or final fix in a decision tree.
+// A node without answers is terminal and carries the solution.
+type DecisionNode struct {
+	Question string            `yaml:"question,omitempty"`
+	Commands []string          `yaml:"commands,omitempty"`
+	Answers  map[string]string `yaml:"answers,omitempty"`
+	Solution string            `yaml:"solution,omitempty"`
+}
+
+// TroubleshootingIssue represents a troubleshooting issue. The single
+// solution field is kept for older service files; newer ones may list
+// several solutions alongside causes and prevention advice. Service files
+// may set ref instead to reuse an issue from the common library by ID.
 type TroubleshootingIssue struct {
-	Issue    string `yaml:"issue"`
-	Solution string `yaml:"solution"`
+	ID            string   `yaml:"id,omitempty"`
+	Ref           string   `yaml:"ref,omitempty"`
+	Issue         string   `yaml:"issue,omitempty"`
+	Solution      string   `yaml:"solution,omitempty"`
+	Solutions     []string `yaml:"solutions,omitempty"`
+	Symptoms      []string `yaml:"symptoms,omitempty"`
+	Causes        []string `yaml:"causes,omitempty"`
+	Prevention    []string `yaml:"prevention,omitempty"`
+	ErrorPatterns []string `yaml:"error_patterns,omitempty"`
+	Provenance    `yaml:",inline"`
+
+	// compiledPatterns holds ErrorPatterns compiled when the issue was loaded
+	compiledPatterns []*regexp.Regexp
+}
+
+// compileErrorPattern compiles an error pattern of a troubleshooting issue.
+// Error patterns match case-insensitively.
+func compileErrorPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// compileErrorPatterns compiles every error pattern of the issue and keeps
+// the expressions for matching
+func (ti *TroubleshootingIssue) compileErrorPatterns() error {
+	ti.compiledPatterns = nil
+	for _, pattern := range ti.ErrorPatterns {
+		re, err := compileErrorPattern(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid error pattern '%s': %v", pattern, err)
+		}
+		ti.compiledPatterns = append(ti.compiledPatterns, re)
+	}
+	return nil
+}
+
+// MatchingErrorPatterns returns the error patterns that match the message.
+// Issues that were not loaded by a ConfigLoader compile their patterns on
+// each call and skip invalid ones.
+func (ti TroubleshootingIssue) MatchingErrorPatterns(message string) []string {
+	compiled := ti.compiledPatterns
+	if len(compiled) != len(ti.ErrorPatterns) {
+		compiled = make([]*regexp.Regexp, len(ti.ErrorPatterns))
+		for i, pattern := range ti.ErrorPatterns {
+			compiled[i], _ = compileErrorPattern(pattern)
+		}
+	}
+
+	var matching []string
+	for i, re := range compiled {
+		if re != nil && re.MatchString(message) {
+			matching = append(matching, ti.ErrorPatterns[i])
+		}
+	}
+	return matching
+}
+
+// AllSolutions returns the legacy single solution followed by any listed solutions
+func (ti TroubleshootingIssue) AllSolutions() []string {
+	var solutions []string
+	if ti.Solution != "" {
+		solutions = append(solutions, ti.Solution)
+	}
+	return append(solutions, ti.Solutions...)
 }
 
 // ValidationSteps represents validation steps
 type ValidationSteps struct {
-	Steps []ValidationStep `yaml:"steps"`
+	Status string           `yaml:"status,omitempty"`
+	Steps  []ValidationStep `yaml:"steps"`
 }
 
 // ValidationStep represents a single validation step
 type ValidationStep struct {
-	Step           int      `yaml:"step"`
-	Title          string   `yaml:"title"`
-	Description    string   `yaml:"description"`
-	Commands       []string `yaml:"commands"`
-	ExpectedOutput string   `yaml:"expected_output"`
+	Step           int              `yaml:"step"`
+	Title          string           `yaml:"title"`
+	Description    string           `yaml:"description"`
+	Commands       []string         `yaml:"commands"`
+	ExpectedOutput string           `yaml:"expected_output"`
+	Expect         *StepExpectation `yaml:"expect,omitempty"`
+	Provenance     `yaml:",inline"`
+}
+
+// FindConfigDir locates the config directory relative to the working directory
+func FindConfigDir() string {
+	// Try to find config directory relative to the executable
+	configDir := "config"
+	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+		// If not found, try relative to project root (go up one level from cmd/server)
+		configDir = "../config"
+		if _, err := os.Stat(configDir); os.IsNotExist(err) {
+			// If still not found, try absolute path from project root
+			configDir = "/Users/mwolf/git/docs-mcp/config"
+		}
+	}
+	return configDir
 }
 
 // ConfigLoader handles loading service configurations from YAML files
 type ConfigLoader struct {
-	configDir string
-	services  map[string]*ServiceConfig
+	configDir      string
+	services       map[string]*ServiceConfig
+	commonIssues   map[string]TroubleshootingIssue
+	contentMode    ContentMode
+	contentModeErr error
+	// loadErrors holds the errors of service files LoadAllServices skipped
+	loadErrors map[string]error
 }
 
 // NewConfigLoader creates a new configuration loader
 func NewConfigLoader(configDir string) *ConfigLoader {
 	return &ConfigLoader{
-		configDir: configDir,
-		services:  make(map[string]*ServiceConfig),
+		configDir:    configDir,
+		services:     make(map[string]*ServiceConfig),
+		commonIssues: make(map[string]TroubleshootingIssue),
+		loadErrors:   make(map[string]error),
 	}
 }
 
-// LoadAllServices loads all service configurations from the config directory
+// LoadAllServices loads all service configurations from the config directory.
+// A service file that fails to load is skipped, and its error is kept for
+// LoadErrors and GetServiceConfig, so that one broken file does not hide the
+// other services. In ContentModeReviewed, services and sections that are not
+// reviewed are left out.
 func (cl *ConfigLoader) LoadAllServices() error {
+	if cl.contentModeErr != nil {
+		return cl.contentModeErr
+	}
 	servicesDir := filepath.Join(cl.configDir, "services")
 
 	// Check if services directory exists
@@ -132,6 +296,10 @@ func (cl *ConfigLoader) LoadAllServices() error {
 		return fmt.Errorf("services directory does not exist: %s", servicesDir)
 	}
 
+	if err := cl.LoadCommonTroubleshooting(); err != nil {
+		return err
+	}
+
 	// Read all YAML files in the services directory
 	files, err := ioutil.ReadDir(servicesDir)
 	if err != nil {
@@ -146,7 +314,14 @@ func (cl *ConfigLoader) LoadAllServices() error {
 			configPath := filepath.Join(servicesDir, file.Name())
 			config, err := cl.LoadServiceConfig(configPath)
 			if err != nil {
-				return fmt.Errorf("failed to load config for %s: %v", serviceName, err)
+				cl.loadErrors[serviceName] = fmt.Errorf("failed to load config for %s: %v", serviceName, err)
+				continue
+			}
+			if cl.ContentMode() == ContentModeReviewed {
+				var reviewed bool
+				if config, reviewed = reviewedContent(config); !reviewed {
+					continue
+				}
 			}
 
 			cl.services[serviceName] = config
@@ -162,10 +337,49 @@ func (cl *ConfigLoader) LoadServiceConfig(configPath string) (*ServiceConfig, er
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %v", configPath, err)
 	}
+	return cl.ParseServiceConfig(data, configPath)
+}
 
+// ParseServiceConfig parses a service configuration, resolving references
+// to the common troubleshooting library. The source names the configuration
+// in error messages.
+func (cl *ConfigLoader) ParseServiceConfig(data []byte, source string) (*ServiceConfig, error) {
 	var config ServiceConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML config file %s: %v", configPath, err)
+		return nil, fmt.Errorf("failed to parse YAML config file %s: %v", source, err)
+	}
+
+	if err := cl.resolveIssueReferences(&config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", source, err)
+	}
+
+	for i := range config.Troubleshooting.CommonIssues {
+		issue := &config.Troubleshooting.CommonIssues[i]
+		if err := issue.compileErrorPatterns(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: troubleshooting issue %q: %v", source, issueLabel(*issue), err)
+		}
+	}
+
+	if tree := config.Troubleshooting.DecisionTree; tree != nil {
+		if err := tree.validate(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: troubleshooting: %v", source, err)
+		}
+	}
+
+	if err := validateExpectations(config.ValidationSteps.Steps); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", source, err)
+	}
+
+	if err := validateProvenance(&config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", source, err)
+	}
+
+	if err := validateStatuses(&config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", source, err)
+	}
+
+	if err := validatePlatforms(config.SetupInstructions); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", source, err)
 	}
 
 	return &config, nil
@@ -174,6 +388,9 @@ func (cl *ConfigLoader) LoadServiceConfig(configPath string) (*ServiceConfig, er
 // GetServiceConfig returns the configuration for a specific service
 func (cl *ConfigLoader) GetServiceConfig(serviceName string) (*ServiceConfig, error) {
 	config, exists := cl.services[strings.ToLower(serviceName)]
+	if err, failed := cl.loadErrors[strings.ToLower(serviceName)]; failed {
+		return nil, err
+	}
 	if !exists {
 		availableServices := make([]string, 0, len(cl.services))
 		for name := range cl.services {
@@ -184,6 +401,21 @@ func (cl *ConfigLoader) GetServiceConfig(serviceName string) (*ServiceConfig, er
 	return config, nil
 }
 
+// LoadErrors returns the errors of the service files LoadAllServices
+// skipped, sorted by service name
+func (cl *ConfigLoader) LoadErrors() []error {
+	names := make([]string, 0, len(cl.loadErrors))
+	for name := range cl.loadErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, cl.loadErrors[name])
+	}
+	return errs
+}
+
 // GetAllServiceNames returns all available service names
 func (cl *ConfigLoader) GetAllServiceNames() []string {
 	names := make([]string, 0, len(cl.services))
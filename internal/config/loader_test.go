@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseServiceConfigErrorPatterns(t *testing.T) {
+	data := `service_name: demo
+troubleshooting:
+  common_issues:
+    - issue: Connection refused
+      error_patterns:
+        - 'dial tcp .*: connection refused'
+        - 'ECONNREFUSED'
+    - issue: Permission denied
+      error_patterns:
+        - 'permission denied'
+`
+	serviceConfig, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(data), "demo.yaml")
+	if err != nil {
+		t.Fatalf("ParseServiceConfig: %v", err)
+	}
+
+	issues := serviceConfig.Troubleshooting.CommonIssues
+	for _, issue := range issues {
+		if len(issue.compiledPatterns) != len(issue.ErrorPatterns) {
+			t.Errorf("issue %q has %d compiled patterns, want %d", issue.Issue, len(issue.compiledPatterns), len(issue.ErrorPatterns))
+		}
+	}
+
+	tests := []struct {
+		message string
+		issue   int
+		want    []string
+	}{
+		{message: "Dial TCP 127.0.0.1:9200: Connection Refused", issue: 0, want: []string{"dial tcp .*: connection refused"}},
+		{message: "connect ECONNREFUSED 127.0.0.1:5601", issue: 0, want: []string{"ECONNREFUSED"}},
+		{message: "open /var/log/nginx/access.log: permission denied", issue: 0},
+		{message: "open /var/log/nginx/access.log: permission denied", issue: 1, want: []string{"permission denied"}},
+	}
+	for _, test := range tests {
+		if got := issues[test.issue].MatchingErrorPatterns(test.message); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("issue %q MatchingErrorPatterns(%q) = %q, want %q", issues[test.issue].Issue, test.message, got, test.want)
+		}
+	}
+}
+
+func TestMatchingErrorPatternsWithoutLoading(t *testing.T) {
+	issue := TroubleshootingIssue{Issue: "Timeout", ErrorPatterns: []string{"(unclosed", "timed out"}}
+	if got, want := issue.MatchingErrorPatterns("request Timed Out"), []string{"timed out"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchingErrorPatterns = %q, want %q", got, want)
+	}
+}
+
+func TestParseServiceConfigInvalidErrorPattern(t *testing.T) {
+	data := "service_name: demo\ntroubleshooting:\n  common_issues:\n    - issue: Broken\n      error_patterns: ['(unclosed']\n"
+	_, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(data), "demo.yaml")
+	want := `invalid config file demo.yaml: troubleshooting issue "Broken": invalid error pattern '(unclosed'`
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("ParseServiceConfig error = %v, want %q", err, want)
+	}
+}
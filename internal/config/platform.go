@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SupportedPlatforms lists the platforms installation steps can target
+var SupportedPlatforms = []string{"ubuntu", "rhel", "windows", "macos", "docker", "kubernetes"}
+
+// platformAliases maps common alternative names onto a supported platform
+var platformAliases = map[string]string{
+	"debian":    "ubuntu",
+	"centos":    "rhel",
+	"redhat":    "rhel",
+	"rocky":     "rhel",
+	"almalinux": "rhel",
+	"fedora":    "rhel",
+	"darwin":    "macos",
+	"mac":       "macos",
+	"osx":       "macos",
+	"k8s":       "kubernetes",
+}
+
+// PlatformVariant overrides parts of an installation step for one platform.
+// Version variants embed the same fields.
+type PlatformVariant struct {
+	Description    string          `yaml:"description,omitempty"`
+	Commands       []string        `yaml:"commands,omitempty"`
+	ConfigSnippets []ConfigSnippet `yaml:"config_snippets,omitempty"`
+	Verification   string          `yaml:"verification,omitempty"`
+}
+
+// NormalizePlatform resolves a platform name or alias to a supported platform
+func NormalizePlatform(platform string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(platform))
+	if alias, exists := platformAliases[name]; exists {
+		name = alias
+	}
+	for _, supported := range SupportedPlatforms {
+		if name == supported {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown platform '%s'. Supported platforms: %s", platform, strings.Join(SupportedPlatforms, ", "))
+}
+
+// validatePlatforms checks that the platforms of installation steps are
+// written as supported platform names. Steps are selected by the normalized
+// name, so an alias such as centos or a name such as Ubuntu never matches.
+func validatePlatforms(setup SetupInstructions) error {
+	for index, step := range setup.InstallationSteps {
+		for _, platform := range sortedPlatformKeys(step.Platforms) {
+			if err := checkPlatformName(platform); err != nil {
+				return fmt.Errorf("setup_instructions: installation step %d: platforms: %v", index+1, err)
+			}
+		}
+		for _, platform := range step.OnlyPlatforms {
+			if err := checkPlatformName(platform); err != nil {
+				return fmt.Errorf("setup_instructions: installation step %d: only_platforms: %v", index+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPlatformName reports a platform that is not written as a supported
+// platform name, suggesting the name it normalizes to
+func checkPlatformName(platform string) error {
+	normalized, err := NormalizePlatform(platform)
+	if err != nil {
+		return err
+	}
+	if normalized != platform {
+		return fmt.Errorf("write platform '%s' as '%s'", platform, normalized)
+	}
+	return nil
+}
+
+func sortedPlatformKeys(variants map[string]PlatformVariant) []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForPlatform returns the step as it applies to a platform, merging in the
+// platform's variant. The boolean is false when the step does not apply.
+func (step InstallationStep) ForPlatform(platform string) (InstallationStep, bool) {
+	if len(step.OnlyPlatforms) > 0 && !containsString(step.OnlyPlatforms, platform) {
+		return step, false
+	}
+
+	variant, exists := step.Platforms[platform]
+	if !exists {
+		return step, true
+	}
+	return step.merge(variant), true
+}
+
+// merge returns a copy of the step with the variant's non-empty fields applied
+func (step InstallationStep) merge(variant PlatformVariant) InstallationStep {
+	merged := step
+	if variant.Description != "" {
+		merged.Description = variant.Description
+	}
+	if len(variant.Commands) > 0 {
+		merged.Commands = variant.Commands
+	}
+	if len(variant.ConfigSnippets) > 0 {
+		merged.ConfigSnippets = variant.ConfigSnippets
+	}
+	if variant.Verification != "" {
+		merged.Verification = variant.Verification
+	}
+	return merged
+}
+
+// Platforms returns the platforms that have specific installation steps, in sorted order
+func (si SetupInstructions) Platforms() []string {
+	seen := make(map[string]bool)
+	for _, step := range si.InstallationSteps {
+		for platform := range step.Platforms {
+			seen[platform] = true
+		}
+		for _, platform := range step.OnlyPlatforms {
+			seen[platform] = true
+		}
+	}
+
+	platforms := make([]string, 0, len(seen))
+	for platform := range seen {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePlatform(t *testing.T) {
+	tests := []struct {
+		platform string
+		want     string
+		err      string
+	}{
+		{platform: "ubuntu", want: "ubuntu"},
+		{platform: " Windows ", want: "windows"},
+		{platform: "centos", want: "rhel"},
+		{platform: "Debian", want: "ubuntu"},
+		{platform: "darwin", want: "macos"},
+		{platform: "k8s", want: "kubernetes"},
+		{platform: "solaris", err: "unknown platform 'solaris'. Supported platforms: ubuntu, rhel, windows, macos, docker, kubernetes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.platform, func(t *testing.T) {
+			got, err := NormalizePlatform(test.platform)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("NormalizePlatform error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("NormalizePlatform = %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseServiceConfigPlatforms(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps string
+		err   string
+	}{
+		{
+			name:  "supported names",
+			steps: "    - step: 1\n      title: Install\n      platforms:\n        rhel:\n          commands: [dnf install nginx]\n      only_platforms: [ubuntu, rhel]\n",
+		},
+		{
+			name:  "alias in platforms",
+			steps: "    - step: 1\n      title: Install\n      platforms:\n        centos:\n          commands: [yum install nginx]\n",
+			err:   "setup_instructions: installation step 1: platforms: write platform 'centos' as 'rhel'",
+		},
+		{
+			name:  "capitalized name in only_platforms",
+			steps: "    - step: 1\n      title: Install\n    - step: 2\n      title: Start\n      only_platforms: [Windows]\n",
+			err:   "setup_instructions: installation step 2: only_platforms: write platform 'Windows' as 'windows'",
+		},
+		{
+			name:  "unknown platform",
+			steps: "    - step: 1\n      title: Install\n      only_platforms: [solaris]\n",
+			err:   "setup_instructions: installation step 1: only_platforms: unknown platform 'solaris'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := "service_name: nginx\nsetup_instructions:\n  installation_steps:\n" + test.steps
+			_, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(data), "nginx.yaml")
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("ParseServiceConfig: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("ParseServiceConfig error = %v, want it to contain %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestForPlatform(t *testing.T) {
+	step := InstallationStep{
+		Step:         1,
+		Title:        "Install",
+		Description:  "Install the package",
+		Commands:     []string{"apt-get install nginx"},
+		Verification: "nginx -v",
+		Platforms: map[string]PlatformVariant{
+			"rhel":   {Commands: []string{"dnf install nginx"}},
+			"docker": {Description: "Run the image", Commands: []string{"docker run nginx"}, Verification: "docker ps"},
+		},
+	}
+
+	tests := []struct {
+		platform     string
+		applies      bool
+		description  string
+		commands     []string
+		verification string
+	}{
+		{platform: "ubuntu", applies: true, description: "Install the package", commands: []string{"apt-get install nginx"}, verification: "nginx -v"},
+		{platform: "rhel", applies: true, description: "Install the package", commands: []string{"dnf install nginx"}, verification: "nginx -v"},
+		{platform: "docker", applies: true, description: "Run the image", commands: []string{"docker run nginx"}, verification: "docker ps"},
+	}
+	for _, test := range tests {
+		t.Run(test.platform, func(t *testing.T) {
+			got, applies := step.ForPlatform(test.platform)
+			if applies != test.applies || got.Description != test.description ||
+				!reflect.DeepEqual(got.Commands, test.commands) || got.Verification != test.verification {
+				t.Errorf("ForPlatform = %q %q %q, %v, want %q %q %q, %v", got.Description, got.Commands, got.Verification, applies,
+					test.description, test.commands, test.verification, test.applies)
+			}
+		})
+	}
+
+	only := InstallationStep{Step: 2, Title: "Enable the service", OnlyPlatforms: []string{"ubuntu", "rhel"}}
+	if _, applies := only.ForPlatform("windows"); applies {
+		t.Errorf("ForPlatform(windows) applies a step limited to %q", only.OnlyPlatforms)
+	}
+}
+
+func TestSetupPlatforms(t *testing.T) {
+	setup := SetupInstructions{InstallationSteps: []InstallationStep{
+		{Step: 1, Platforms: map[string]PlatformVariant{"rhel": {}, "docker": {}}},
+		{Step: 2, OnlyPlatforms: []string{"ubuntu", "rhel"}},
+		{Step: 3},
+	}}
+	if got, want := setup.Platforms(), []string{"docker", "rhel", "ubuntu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Platforms = %q, want %q", got, want)
+	}
+	if got := (SetupInstructions{}).Platforms(); len(got) != 0 {
+		t.Errorf("Platforms of setup without variants = %q, want none", got)
+	}
+}
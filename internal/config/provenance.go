@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"elastic-integration-docs-mcp/internal/version"
+)
+
+// VerifiedOnLayout is the date format of verified_on
+const VerifiedOnLayout = "2006-01-02"
+
+// Provenance records where a piece of curated content came from and when it
+// was last checked. All fields are optional. VerifiedVersions lists the
+// Elastic Stack versions the content was verified against.
+type Provenance struct {
+	SourceURL        string   `yaml:"source_url,omitempty"`
+	VerifiedOn       string   `yaml:"verified_on,omitempty"`
+	VerifiedVersions []string `yaml:"verified_versions,omitempty"`
+	Author           string   `yaml:"author,omitempty"`
+}
+
+// IsZero reports whether no provenance is recorded
+func (p Provenance) IsZero() bool {
+	return p.SourceURL == "" && p.VerifiedOn == "" && len(p.VerifiedVersions) == 0 && p.Author == ""
+}
+
+// VerifiedDate returns the parsed verified_on date; ok is false when none is set
+func (p Provenance) VerifiedDate() (date time.Time, ok bool) {
+	date, err := time.Parse(VerifiedOnLayout, p.VerifiedOn)
+	return date, err == nil
+}
+
+// NewestVerifiedVersion returns the newest of the verified versions; ok is
+// false when none is set
+func (p Provenance) NewestVerifiedVersion() (newest version.Version, ok bool) {
+	for _, entry := range p.VerifiedVersions {
+		v, err := version.Parse(entry)
+		if err != nil {
+			continue
+		}
+		if !ok || v.Compare(newest) > 0 {
+			newest, ok = v, true
+		}
+	}
+	return newest, ok
+}
+
+func (p Provenance) validate() error {
+	if p.VerifiedOn != "" {
+		if _, err := time.Parse(VerifiedOnLayout, p.VerifiedOn); err != nil {
+			return fmt.Errorf("invalid verified_on '%s', want YYYY-MM-DD", p.VerifiedOn)
+		}
+	}
+	for _, entry := range p.VerifiedVersions {
+		if _, err := version.Parse(entry); err != nil {
+			return fmt.Errorf("invalid verified_versions entry '%s': %v", entry, err)
+		}
+	}
+	return nil
+}
+
+// ProvenanceItem is a piece of curated content with its provenance
+type ProvenanceItem struct {
+	Section    string
+	Item       string
+	Provenance Provenance
+}
+
+// ProvenanceItems returns every step, config snippet and troubleshooting
+// issue of a service that can carry provenance, in file order. Snippets of
+// platform and version variants are included.
+func (c *ServiceConfig) ProvenanceItems() []ProvenanceItem {
+	var items []ProvenanceItem
+	addSnippets := func(section, prefix string, snippets []ConfigSnippet) {
+		for _, snippet := range snippets {
+			items = append(items, ProvenanceItem{Section: section, Item: prefix + "config snippet " + snippet.Filename, Provenance: snippet.Provenance})
+		}
+	}
+
+	for _, step := range c.SetupInstructions.InstallationSteps {
+		label := fmt.Sprintf("Step %d: %s", step.Step, step.Title)
+		items = append(items, ProvenanceItem{Section: "Installation steps", Item: label, Provenance: step.Provenance})
+		addSnippets("Installation steps", label+", ", step.ConfigSnippets)
+		platforms := make([]string, 0, len(step.Platforms))
+		for platform := range step.Platforms {
+			platforms = append(platforms, platform)
+		}
+		sort.Strings(platforms)
+		for _, platform := range platforms {
+			addSnippets("Installation steps", fmt.Sprintf("%s, %s variant, ", label, platform), step.Platforms[platform].ConfigSnippets)
+		}
+		for _, variant := range step.Versions {
+			addSnippets("Installation steps", fmt.Sprintf("%s, variant for versions %s, ", label, variant.Range), variant.ConfigSnippets)
+		}
+	}
+	for _, inputType := range c.KibanaSetupInstructions.InputTypes() {
+		for _, step := range c.KibanaSetupInstructions[inputType].Steps {
+			items = append(items, ProvenanceItem{Section: "Kibana setup",
+				Item: fmt.Sprintf("%s step %d: %s", inputType, step.Step, step.Instruction), Provenance: step.Provenance})
+		}
+	}
+	for _, issue := range c.Troubleshooting.CommonIssues {
+		items = append(items, ProvenanceItem{Section: "Troubleshooting", Item: issueLabel(issue), Provenance: issue.Provenance})
+	}
+	for _, step := range c.ValidationSteps.Steps {
+		items = append(items, ProvenanceItem{Section: "Validation steps",
+			Item: fmt.Sprintf("Step %d: %s", step.Step, step.Title), Provenance: step.Provenance})
+	}
+	return items
+}
+
+// validateProvenance checks the provenance of every item of a service
+func validateProvenance(config *ServiceConfig) error {
+	for _, item := range config.ProvenanceItems() {
+		if err := item.Provenance.validate(); err != nil {
+			return fmt.Errorf("%s, %s: %v", item.Section, item.Item, err)
+		}
+	}
+	return nil
+}
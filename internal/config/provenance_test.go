@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const provenanceService = `service_name: demo
+title: Demo
+setup_instructions:
+  installation_steps:
+    - step: 1
+      title: Install
+      verified_on: "2025-01-15"
+      verified_versions: ["8.15.0", "8.9.0"]
+      config_snippets:
+        - filename: demo.conf
+          content: "listen 80;"
+          author: jane
+      platforms:
+        rhel:
+          config_snippets:
+            - filename: rhel.conf
+              content: "listen 80;"
+      versions:
+        - range: ">=2.0"
+          config_snippets:
+            - filename: v2.conf
+              content: "listen 80;"
+kibana_setup_instructions:
+  default:
+    steps:
+      - step: 1
+        instruction: Add the integration
+troubleshooting:
+  common_issues:
+    - issue: No data
+      solution: Check the agent
+      source_url: https://example.com/no-data
+validation_steps:
+  steps:
+    - step: 1
+      title: Check the status page
+`
+
+func TestProvenanceItems(t *testing.T) {
+	serviceConfig, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(provenanceService), "demo.yaml")
+	if err != nil {
+		t.Fatalf("ParseServiceConfig: %v", err)
+	}
+
+	var items []string
+	for _, item := range serviceConfig.ProvenanceItems() {
+		items = append(items, fmt.Sprintf("%s | %s | %s %s %s %s", item.Section, item.Item,
+			item.Provenance.VerifiedOn, strings.Join(item.Provenance.VerifiedVersions, ","), item.Provenance.Author, item.Provenance.SourceURL))
+	}
+	want := []string{
+		"Installation steps | Step 1: Install | 2025-01-15 8.15.0,8.9.0  ",
+		"Installation steps | Step 1: Install, config snippet demo.conf |   jane ",
+		"Installation steps | Step 1: Install, rhel variant, config snippet rhel.conf |    ",
+		"Installation steps | Step 1: Install, variant for versions >=2.0, config snippet v2.conf |    ",
+		"Kibana setup | default step 1: Add the integration |    ",
+		"Troubleshooting | No data |    https://example.com/no-data",
+		"Validation steps | Step 1: Check the status page |    ",
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("ProvenanceItems =\n%s\nwant\n%s", strings.Join(items, "\n"), strings.Join(want, "\n"))
+	}
+
+	provenance := serviceConfig.SetupInstructions.InstallationSteps[0].Provenance
+	if date, ok := provenance.VerifiedDate(); !ok || date.Format(VerifiedOnLayout) != "2025-01-15" {
+		t.Errorf("VerifiedDate = %v, %v, want 2025-01-15", date, ok)
+	}
+	if newest, ok := provenance.NewestVerifiedVersion(); !ok || newest.String() != "8.15.0" {
+		t.Errorf("NewestVerifiedVersion = %v, %v, want 8.15.0", newest, ok)
+	}
+	if _, ok := (Provenance{}).VerifiedDate(); ok {
+		t.Errorf("VerifiedDate without verified_on reported a date")
+	}
+	if _, ok := (Provenance{}).NewestVerifiedVersion(); ok {
+		t.Errorf("NewestVerifiedVersion without verified_versions reported a version")
+	}
+	if !(Provenance{}).IsZero() || (Provenance{Author: "jane"}).IsZero() {
+		t.Errorf("IsZero does not match whether provenance is recorded")
+	}
+}
+
+func TestValidateProvenance(t *testing.T) {
+	tests := []struct {
+		name       string
+		provenance string
+		err        string
+	}{
+		{name: "valid", provenance: "verified_on: \"2025-01-15\"\n      verified_versions: [\"8.15.0\"]"},
+		{name: "date with a time", provenance: "verified_on: \"2025-01-15T10:00:00Z\"", err: "invalid verified_on '2025-01-15T10:00:00Z', want YYYY-MM-DD"},
+		{name: "day first date", provenance: "verified_on: \"15-01-2025\"", err: "invalid verified_on '15-01-2025', want YYYY-MM-DD"},
+		{name: "invalid version", provenance: "verified_versions: [\"8.x\"]", err: "invalid verified_versions entry '8.x': "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := "service_name: demo\ntroubleshooting:\n  common_issues:\n    - issue: No data\n      " + test.provenance + "\n"
+			_, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(data), "demo.yaml")
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("ParseServiceConfig: %v", err)
+				}
+				return
+			}
+			want := "invalid config file demo.yaml: Troubleshooting, No data: " + test.err
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("ParseServiceConfig error = %v, want %q", err, want)
+			}
+		})
+	}
+}
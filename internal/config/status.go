@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Review statuses of services and their sections
+const (
+	StatusDraft      = "draft"
+	StatusReviewed   = "reviewed"
+	StatusDeprecated = "deprecated"
+)
+
+// ContentMode selects how a loader serves content by review status
+type ContentMode string
+
+const (
+	// ContentModeAll serves every service and section as it is
+	ContentModeAll ContentMode = "all"
+	// ContentModeLabel serves everything, and tools label content that is
+	// not reviewed
+	ContentModeLabel ContentMode = "label"
+	// ContentModeReviewed serves only reviewed services and sections
+	ContentModeReviewed ContentMode = "reviewed"
+)
+
+// ContentModeEnv names the environment variable holding the content mode
+const ContentModeEnv = "CONTENT_MODE"
+
+// ParseContentMode parses a content mode; an empty mode is ContentModeAll
+func ParseContentMode(mode string) (ContentMode, error) {
+	switch ContentMode(strings.ToLower(strings.TrimSpace(mode))) {
+	case "", ContentModeAll:
+		return ContentModeAll, nil
+	case ContentModeLabel:
+		return ContentModeLabel, nil
+	case ContentModeReviewed:
+		return ContentModeReviewed, nil
+	}
+	return "", fmt.Errorf("invalid %s '%s': use all, label or reviewed", ContentModeEnv, mode)
+}
+
+// ContentModeFromEnv returns the content mode named by CONTENT_MODE
+func ContentModeFromEnv() (ContentMode, error) {
+	return ParseContentMode(os.Getenv(ContentModeEnv))
+}
+
+// NewConfigLoaderFromEnv creates a loader that serves content in the mode
+// named by CONTENT_MODE. An invalid mode is reported by LoadAllServices.
+func NewConfigLoaderFromEnv(configDir string) *ConfigLoader {
+	loader := NewConfigLoader(configDir)
+	loader.contentMode, loader.contentModeErr = ContentModeFromEnv()
+	return loader
+}
+
+// ContentMode returns the mode the loader serves content in
+func (cl *ConfigLoader) ContentMode() ContentMode {
+	if cl.contentMode == "" {
+		return ContentModeAll
+	}
+	return cl.contentMode
+}
+
+// ContentSection is a part of a service file with its own review status
+type ContentSection struct {
+	// Name is the YAML key of the section, such as setup_instructions or
+	// kibana_setup_instructions.aws-s3; "service" stands for the title,
+	// description, categories and documentation sites
+	Name string
+	// Status is the status set on the section, or "" when it inherits the
+	// status of the service
+	Status string
+	// Content is the value of the section
+	Content interface{}
+	// Empty reports whether the section has no content to review
+	Empty bool
+}
+
+// ServiceSection names the service-level fields in ContentSections
+const ServiceSection = "service"
+
+// ServiceStatus returns the status of the service; a service without one is a draft
+func (c *ServiceConfig) ServiceStatus() string {
+	if c.Status == "" {
+		return StatusDraft
+	}
+	return c.Status
+}
+
+// SectionStatus returns the status of a section, which defaults to the
+// status of the service
+func (c *ServiceConfig) SectionStatus(status string) string {
+	if status == "" {
+		return c.ServiceStatus()
+	}
+	return status
+}
+
+// ContentSections returns the service-level fields and every section that
+// can carry a status, in file order
+func (c *ServiceConfig) ContentSections() []ContentSection {
+	info, setup, troubleshooting := c.ServiceInfo, c.SetupInstructions, c.Troubleshooting
+	sections := []ContentSection{
+		{Name: ServiceSection, Status: c.Status,
+			Content: []interface{}{c.Title, c.Description, c.Categories, c.DocumentationSites}},
+		{Name: "service_info", Status: info.Status, Content: info,
+			Empty: len(info.CommonUseCases) == 0 && len(info.DataTypesCollected) == 0 &&
+				len(info.Compatibility.ElasticStackVersions) == 0 && len(info.Compatibility.ServiceVersions) == 0 &&
+				info.ScalingAndPerformance.Description == "" && len(info.ScalingAndPerformance.PerformanceExpectations) == 0 &&
+				len(info.ScalingAndPerformance.ScalingGuidance) == 0},
+		{Name: "setup_instructions", Status: setup.Status, Content: setup,
+			Empty: len(setup.Prerequisites) == 0 && len(setup.InstallationSteps) == 0},
+	}
+	for _, inputType := range c.KibanaSetupInstructions.InputTypes() {
+		steps := c.KibanaSetupInstructions[inputType]
+		sections = append(sections, ContentSection{Name: "kibana_setup_instructions." + inputType,
+			Status: steps.Status, Content: steps, Empty: len(steps.Steps) == 0})
+	}
+	return append(sections,
+		ContentSection{Name: "troubleshooting", Status: troubleshooting.Status, Content: troubleshooting,
+			Empty: len(troubleshooting.CommonIssues) == 0 && len(troubleshooting.DiagnosticCommands) == 0 &&
+				len(troubleshooting.LogLocations) == 0 && len(troubleshooting.SupportResources) == 0 &&
+				troubleshooting.DecisionTree == nil},
+		ContentSection{Name: "validation_steps", Status: c.ValidationSteps.Status, Content: c.ValidationSteps,
+			Empty: len(c.ValidationSteps.Steps) == 0})
+}
+
+// validateStatuses checks that every status is draft, reviewed or deprecated
+func validateStatuses(config *ServiceConfig) error {
+	for _, section := range config.ContentSections() {
+		switch section.Status {
+		case "", StatusDraft, StatusReviewed, StatusDeprecated:
+		default:
+			return fmt.Errorf("%s: invalid status '%s', want draft, reviewed or deprecated", section.Name, section.Status)
+		}
+	}
+	return nil
+}
+
+// reviewedContent returns the reviewed part of a service for
+// ContentModeReviewed: sections that are not reviewed are emptied. It
+// returns false when the service itself is not reviewed.
+func reviewedContent(config *ServiceConfig) (*ServiceConfig, bool) {
+	if config.ServiceStatus() != StatusReviewed {
+		return nil, false
+	}
+	reviewed := *config
+	if reviewed.SectionStatus(reviewed.ServiceInfo.Status) != StatusReviewed {
+		reviewed.ServiceInfo = ServiceInfo{}
+	}
+	if reviewed.SectionStatus(reviewed.SetupInstructions.Status) != StatusReviewed {
+		reviewed.SetupInstructions = SetupInstructions{}
+	}
+	kibana := KibanaSetupInstructions{}
+	for inputType, steps := range config.KibanaSetupInstructions {
+		if reviewed.SectionStatus(steps.Status) == StatusReviewed {
+			kibana[inputType] = steps
+		}
+	}
+	reviewed.KibanaSetupInstructions = kibana
+	if reviewed.SectionStatus(reviewed.Troubleshooting.Status) != StatusReviewed {
+		reviewed.Troubleshooting = Troubleshooting{}
+	}
+	if reviewed.SectionStatus(reviewed.ValidationSteps.Status) != StatusReviewed {
+		reviewed.ValidationSteps = ValidationSteps{}
+	}
+	return &reviewed, true
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseContentMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		want ContentMode
+		err  string
+	}{
+		{mode: "", want: ContentModeAll},
+		{mode: "all", want: ContentModeAll},
+		{mode: " Label ", want: ContentModeLabel},
+		{mode: "REVIEWED", want: ContentModeReviewed},
+		{mode: "draft", err: "invalid CONTENT_MODE 'draft': use all, label or reviewed"},
+	}
+	for _, test := range tests {
+		mode, err := ParseContentMode(test.mode)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("ParseContentMode(%q) error = %v, want %q", test.mode, err, test.err)
+			}
+			continue
+		}
+		if err != nil || mode != test.want {
+			t.Errorf("ParseContentMode(%q) = %q, %v, want %q", test.mode, mode, err, test.want)
+		}
+	}
+}
+
+func TestContentSections(t *testing.T) {
+	data := `service_name: demo
+title: Demo
+status: reviewed
+setup_instructions:
+  status: draft
+  prerequisites: [Demo is installed]
+kibana_setup_instructions:
+  tcp:
+    status: deprecated
+    steps: []
+  default:
+    steps:
+      - step: 1
+        instruction: Add the integration
+troubleshooting:
+  log_locations: [/var/log/demo.log]
+`
+	serviceConfig, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte(data), "demo.yaml")
+	if err != nil {
+		t.Fatalf("ParseServiceConfig: %v", err)
+	}
+
+	var sections []string
+	for _, section := range serviceConfig.ContentSections() {
+		line := section.Name + " " + serviceConfig.SectionStatus(section.Status)
+		if section.Empty {
+			line += " (empty)"
+		}
+		sections = append(sections, line)
+	}
+	want := []string{
+		"service reviewed",
+		"service_info reviewed (empty)",
+		"setup_instructions draft",
+		"kibana_setup_instructions.default reviewed",
+		"kibana_setup_instructions.tcp deprecated (empty)",
+		"troubleshooting reviewed",
+		"validation_steps reviewed (empty)",
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("ContentSections =\n%s\nwant\n%s", strings.Join(sections, "\n"), strings.Join(want, "\n"))
+	}
+
+	if status := (&ServiceConfig{}).ServiceStatus(); status != StatusDraft {
+		t.Errorf("ServiceStatus without a status = %q, want draft", status)
+	}
+}
+
+func TestValidateStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "valid", data: "status: deprecated\nvalidation_steps:\n  status: reviewed\n"},
+		{name: "service", data: "status: done\n", err: "service: invalid status 'done', want draft, reviewed or deprecated"},
+		{name: "section", data: "troubleshooting:\n  status: Reviewed\n", err: "troubleshooting: invalid status 'Reviewed'"},
+		{name: "kibana input type", data: "kibana_setup_instructions:\n  aws-s3:\n    status: final\n", err: "kibana_setup_instructions.aws-s3: invalid status 'final'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewConfigLoader(t.TempDir()).ParseServiceConfig([]byte("service_name: demo\n"+test.data), "demo.yaml")
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("ParseServiceConfig: %v", err)
+				}
+				return
+			}
+			want := "invalid config file demo.yaml: " + test.err
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("ParseServiceConfig error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestContentModeReviewed(t *testing.T) {
+	dir := writeConfigDir(t, map[string]string{
+		"services/nginx.yaml": `service_name: nginx
+status: reviewed
+setup_instructions:
+  prerequisites: [Nginx is installed]
+kibana_setup_instructions:
+  default:
+    steps:
+      - step: 1
+        instruction: Add the integration
+  tcp:
+    status: draft
+    steps:
+      - step: 1
+        instruction: Add a TCP input
+troubleshooting:
+  status: draft
+  log_locations: [/var/log/nginx/error.log]
+`,
+		"services/apache.yaml": "service_name: apache\nstatus: draft\n",
+	})
+
+	t.Setenv(ContentModeEnv, "reviewed")
+	loader := NewConfigLoaderFromEnv(dir)
+	if err := loader.LoadAllServices(); err != nil {
+		t.Fatalf("LoadAllServices: %v", err)
+	}
+	if names := loader.GetAllServiceNames(); !reflect.DeepEqual(names, []string{"nginx"}) {
+		t.Errorf("services = %q, want the reviewed service only", names)
+	}
+	nginx, err := loader.GetServiceConfig("nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(nginx.SetupInstructions.Prerequisites, []string{"Nginx is installed"}) {
+		t.Errorf("prerequisites = %q, want the reviewed section kept", nginx.SetupInstructions.Prerequisites)
+	}
+	if inputTypes := nginx.KibanaSetupInstructions.InputTypes(); !reflect.DeepEqual(inputTypes, []string{"default"}) {
+		t.Errorf("Kibana input types = %q, want default only", inputTypes)
+	}
+	if len(nginx.Troubleshooting.LogLocations) != 0 {
+		t.Errorf("log locations = %q, want the draft section emptied", nginx.Troubleshooting.LogLocations)
+	}
+
+	t.Setenv(ContentModeEnv, "strict")
+	if err := NewConfigLoaderFromEnv(dir).LoadAllServices(); err == nil || !strings.Contains(err.Error(), "invalid CONTENT_MODE 'strict'") {
+		t.Errorf("LoadAllServices error = %v, want the invalid content mode", err)
+	}
+}
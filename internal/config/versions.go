@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/version"
+)
+
+// VersionVariant overrides parts of an installation step for service
+// versions matching Range, for example ">=2.4 <3.0"
+type VersionVariant struct {
+	Range           string `yaml:"range"`
+	PlatformVariant `yaml:",inline"`
+}
+
+// ForVersion returns the step as it applies to a service version, merging in
+// the first matching version variant. The boolean is false when the step's
+// version range excludes the version.
+func (step InstallationStep) ForVersion(v version.Version) (InstallationStep, bool, error) {
+	if step.VersionRange != "" {
+		matches, err := matchesRange(step.VersionRange, v)
+		if err != nil {
+			return step, false, fmt.Errorf("step %d (%s): %v", step.Step, step.Title, err)
+		}
+		if !matches {
+			return step, false, nil
+		}
+	}
+
+	for _, variant := range step.Versions {
+		matches, err := matchesRange(variant.Range, v)
+		if err != nil {
+			return step, false, fmt.Errorf("step %d (%s): %v", step.Step, step.Title, err)
+		}
+		if matches {
+			return step.merge(variant.PlatformVariant), true, nil
+		}
+	}
+
+	return step, true, nil
+}
+
+// SelectSteps returns the installation steps that apply to a platform and
+// service version, renumbered so they stay sequential after filtering. An
+// empty platform or nil version skips that filter. Version variants are
+// applied first and platform variants on top, since version variants are
+// written for every platform.
+func (si SetupInstructions) SelectSteps(platform string, serviceVersion *version.Version) ([]InstallationStep, error) {
+	var steps []InstallationStep
+	for _, step := range si.InstallationSteps {
+		if serviceVersion != nil {
+			merged, applies, err := step.ForVersion(*serviceVersion)
+			if err != nil {
+				return nil, err
+			}
+			if !applies {
+				continue
+			}
+			step = merged
+		}
+
+		if platform != "" {
+			merged, applies := step.ForPlatform(platform)
+			if !applies {
+				continue
+			}
+			step = merged
+		}
+
+		step.Step = len(steps) + 1
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// SelectKibanaSteps returns the Kibana setup steps that apply to a service
+// version, renumbered so they stay sequential after filtering
+func SelectKibanaSteps(steps []KibanaSetupStep, serviceVersion *version.Version) ([]KibanaSetupStep, error) {
+	if serviceVersion == nil {
+		return steps, nil
+	}
+
+	var selected []KibanaSetupStep
+	for _, step := range steps {
+		if step.VersionRange != "" {
+			matches, err := matchesRange(step.VersionRange, *serviceVersion)
+			if err != nil {
+				return nil, fmt.Errorf("kibana step %d: %v", step.Step, err)
+			}
+			if !matches {
+				continue
+			}
+		}
+		step.Step = len(selected) + 1
+		selected = append(selected, step)
+	}
+	return selected, nil
+}
+
+// CheckServiceVersion reports whether a service version falls inside the
+// compatible service versions. Entries that are not version ranges, such as
+// free-text notes, are ignored; checked is false when no entry could be evaluated.
+func (c Compatibility) CheckServiceVersion(v version.Version) (supported bool, checked bool) {
+	for _, entry := range c.ServiceVersions {
+		constraint, err := version.ParseConstraint(entry)
+		if err != nil {
+			continue
+		}
+		checked = true
+		if constraint.Check(v) {
+			return true, true
+		}
+	}
+	return false, checked
+}
+
+func matchesRange(versionRange string, v version.Version) (bool, error) {
+	constraint, err := version.ParseConstraint(versionRange)
+	if err != nil {
+		return false, err
+	}
+	return constraint.Check(v), nil
+}
+
+// StackConstraint returns the Elastic Stack version constraint for the
+// service, joining all parseable elastic_stack_versions entries. The boolean
+// is false when no entry is a valid constraint.
+func (c Compatibility) StackConstraint() (version.Constraint, bool) {
+	var ranges []string
+	for _, entry := range c.ElasticStackVersions {
+		if _, err := version.ParseConstraint(entry); err == nil {
+			ranges = append(ranges, entry)
+		}
+	}
+	if len(ranges) == 0 {
+		return version.Constraint{}, false
+	}
+
+	constraint, err := version.ParseConstraint(strings.Join(ranges, " || "))
+	if err != nil {
+		return version.Constraint{}, false
+	}
+	return constraint, true
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/version"
+)
+
+// selectedStep is the part of a selected installation step the tests compare
+type selectedStep struct {
+	Step        int
+	Title       string
+	Description string
+	Commands    []string
+}
+
+func TestSelectSteps(t *testing.T) {
+	setup := SetupInstructions{InstallationSteps: []InstallationStep{
+		{
+			Step:        1,
+			Title:       "Install",
+			Description: "Install the package",
+			Commands:    []string{"apt-get install nginx"},
+			Versions: []VersionVariant{
+				{Range: "<1.19", PlatformVariant: PlatformVariant{Description: "Install the legacy package", Commands: []string{"apt-get install nginx-legacy"}}},
+				{Range: "<1.25", PlatformVariant: PlatformVariant{Commands: []string{"apt-get install nginx-1.2x"}}},
+			},
+			Platforms: map[string]PlatformVariant{
+				"rhel": {Commands: []string{"dnf install nginx"}},
+			},
+		},
+		{Step: 2, Title: "Enable HTTP/3", VersionRange: ">=1.25"},
+		{Step: 3, Title: "Start", Commands: []string{"systemctl start nginx"}, OnlyPlatforms: []string{"ubuntu", "rhel"}},
+	}}
+
+	tests := []struct {
+		name     string
+		platform string
+		version  string
+		want     []selectedStep
+	}{
+		{
+			name: "no filters",
+			want: []selectedStep{
+				{1, "Install", "Install the package", []string{"apt-get install nginx"}},
+				{2, "Enable HTTP/3", "", nil},
+				{3, "Start", "", []string{"systemctl start nginx"}},
+			},
+		},
+		{
+			name:    "first matching version variant",
+			version: "1.18.0",
+			want: []selectedStep{
+				{1, "Install", "Install the legacy package", []string{"apt-get install nginx-legacy"}},
+				{2, "Start", "", []string{"systemctl start nginx"}},
+			},
+		},
+		{
+			name:    "second version variant",
+			version: "1.24",
+			want: []selectedStep{
+				{1, "Install", "Install the package", []string{"apt-get install nginx-1.2x"}},
+				{2, "Start", "", []string{"systemctl start nginx"}},
+			},
+		},
+		{
+			name:    "version range includes step",
+			version: "1.25.3",
+			want: []selectedStep{
+				{1, "Install", "Install the package", []string{"apt-get install nginx"}},
+				{2, "Enable HTTP/3", "", nil},
+				{3, "Start", "", []string{"systemctl start nginx"}},
+			},
+		},
+		{
+			name:     "platform variant applied over version variant",
+			platform: "rhel",
+			version:  "1.18.0",
+			want: []selectedStep{
+				{1, "Install", "Install the legacy package", []string{"dnf install nginx"}},
+				{2, "Start", "", []string{"systemctl start nginx"}},
+			},
+		},
+		{
+			name:     "platform without the only_platforms step",
+			platform: "windows",
+			want: []selectedStep{
+				{1, "Install", "Install the package", []string{"apt-get install nginx"}},
+				{2, "Enable HTTP/3", "", nil},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var serviceVersion *version.Version
+			if test.version != "" {
+				v, err := version.Parse(test.version)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serviceVersion = &v
+			}
+
+			steps, err := setup.SelectSteps(test.platform, serviceVersion)
+			if err != nil {
+				t.Fatalf("SelectSteps: %v", err)
+			}
+			var got []selectedStep
+			for _, step := range steps {
+				got = append(got, selectedStep{step.Step, step.Title, step.Description, step.Commands})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("SelectSteps = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSelectStepsInvalidRange(t *testing.T) {
+	setup := SetupInstructions{InstallationSteps: []InstallationStep{
+		{Step: 1, Title: "Install"},
+		{Step: 2, Title: "Configure", VersionRange: ">=latest"},
+	}}
+	v := version.Version{Major: 1, Minor: 25}
+
+	_, err := setup.SelectSteps("", &v)
+	if err == nil || !strings.HasPrefix(err.Error(), "step 2 (Configure): invalid version constraint '>=latest'") {
+		t.Errorf("SelectSteps error = %v, want it to name step 2 and the constraint", err)
+	}
+}
+
+func TestSelectKibanaSteps(t *testing.T) {
+	steps := []KibanaSetupStep{
+		{Step: 1, Instruction: "Open Integrations"},
+		{Step: 2, Instruction: "Enable the legacy dashboards", VersionRange: "<1.19"},
+		{Step: 3, Instruction: "Save the policy"},
+	}
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{version: "1.18.0", want: []string{"1. Open Integrations", "2. Enable the legacy dashboards", "3. Save the policy"}},
+		{version: "1.25.0", want: []string{"1. Open Integrations", "2. Save the policy"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			v, err := version.Parse(test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			selected, err := SelectKibanaSteps(steps, &v)
+			if err != nil {
+				t.Fatalf("SelectKibanaSteps: %v", err)
+			}
+			var got []string
+			for _, step := range selected {
+				got = append(got, fmt.Sprintf("%d. %s", step.Step, step.Instruction))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("SelectKibanaSteps = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckServiceVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		serviceVersions []string
+		version         string
+		supported       bool
+		checked         bool
+	}{
+		{name: "inside a range", serviceVersions: []string{"1.18 - 1.20", ">=1.25"}, version: "1.26.1", supported: true, checked: true},
+		{name: "outside every range", serviceVersions: []string{"1.18 - 1.20", ">=1.25"}, version: "1.22.0", checked: true},
+		{name: "notes are ignored", serviceVersions: []string{"All supported Nginx releases", "^1.24"}, version: "1.24.0", supported: true, checked: true},
+		{name: "only notes", serviceVersions: []string{"All supported Nginx releases"}, version: "1.24.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := version.Parse(test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			supported, checked := Compatibility{ServiceVersions: test.serviceVersions}.CheckServiceVersion(v)
+			if supported != test.supported || checked != test.checked {
+				t.Errorf("CheckServiceVersion = %v, %v, want %v, %v", supported, checked, test.supported, test.checked)
+			}
+		})
+	}
+}
+
+func TestStackConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   []string
+		constraint string
+		ok         bool
+	}{
+		{name: "joined ranges", versions: []string{"^8.17.8", "^9.0.3"}, constraint: "^8.17.8 || ^9.0.3", ok: true},
+		{name: "notes skipped", versions: []string{"8.x and later", ">=8.10"}, constraint: ">=8.10", ok: true},
+		{name: "no ranges", versions: []string{"Any supported release"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			constraint, ok := Compatibility{ElasticStackVersions: test.versions}.StackConstraint()
+			if ok != test.ok || constraint.String() != test.constraint {
+				t.Errorf("StackConstraint = %q, %v, want %q, %v", constraint.String(), ok, test.constraint, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package ecs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldsFileEnv names the environment variable pointing at an ECS
+// ecs_flat.yml file, as published in the elastic/ecs repository under
+// generated/ecs/ecs_flat.yml
+const FieldsFileEnv = "ECS_FIELDS_FILE"
+
+// Field represents an ECS field definition
+type Field struct {
+	Name        string      `yaml:"flat_name"`
+	Type        string      `yaml:"type"`
+	Level       string      `yaml:"level"`
+	Short       string      `yaml:"short"`
+	Description string      `yaml:"description"`
+	Example     interface{} `yaml:"example"`
+}
+
+// Schema lazily loads ECS field definitions from an ecs_flat.yml file
+type Schema struct {
+	path   string
+	once   sync.Once
+	fields map[string]Field
+	err    error
+}
+
+// NewSchema creates a schema backed by an ecs_flat.yml file
+func NewSchema(path string) *Schema {
+	return &Schema{path: path}
+}
+
+// NewSchemaFromEnv creates a schema for the file named by ECS_FIELDS_FILE
+func NewSchemaFromEnv() *Schema {
+	return NewSchema(os.Getenv(FieldsFileEnv))
+}
+
+// Configured reports whether the schema has a file to read from
+func (s *Schema) Configured() bool {
+	return s.path != ""
+}
+
+// Fields returns all ECS fields keyed by their flat name. The file is read
+// on first use.
+func (s *Schema) Fields() (map[string]Field, error) {
+	s.once.Do(func() {
+		if !s.Configured() {
+			s.err = fmt.Errorf("no ECS field definitions configured; set %s to an ecs_flat.yml file", FieldsFileEnv)
+			return
+		}
+		s.fields, s.err = loadFlatFile(s.path)
+	})
+	return s.fields, s.err
+}
+
+// Lookup returns the ECS definition of a field
+func (s *Schema) Lookup(name string) (Field, bool, error) {
+	fields, err := s.Fields()
+	if err != nil {
+		return Field{}, false, err
+	}
+	field, exists := fields[name]
+	return field, exists, nil
+}
+
+// Source returns the path the definitions are read from
+func (s *Schema) Source() string {
+	return s.path
+}
+
+func loadFlatFile(path string) (map[string]Field, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ECS field definitions: %v", err)
+	}
+
+	var fields map[string]Field
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	for name, field := range fields {
+		if field.Name == "" {
+			field.Name = name
+			fields[name] = field
+		}
+	}
+	return fields, nil
+}
+
+// SameFamily reports whether two field types are interchangeable for
+// mapping purposes, such as keyword and constant_keyword
+func SameFamily(a, b string) bool {
+	return typeFamily(a) == typeFamily(b)
+}
+
+func typeFamily(fieldType string) string {
+	switch fieldType {
+	case "keyword", "constant_keyword", "wildcard":
+		return "keyword"
+	case "text", "match_only_text":
+		return "text"
+	default:
+		return fieldType
+	}
+}
@@ -0,0 +1,192 @@
+package ingest
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// offsetPattern matches fixed time zone offsets such as +02:00 or -0500
+var offsetPattern = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})$`)
+
+// ParseDate parses a date with one of the date processor's formats: ISO8601,
+// UNIX, UNIX_MS or a Java time pattern. Dates without a zone are in location,
+// and dates without a year take the year of now.
+func ParseDate(text, format string, location *time.Location, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	switch format {
+	case "ISO8601":
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04:05.999999999Z0700", "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+			if parsed, err := time.ParseInLocation(layout, text, location); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("not an ISO8601 date")
+	case "UNIX", "UNIX_MS":
+		seconds, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("not a %s timestamp", format)
+		}
+		if format == "UNIX_MS" {
+			seconds /= 1000
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(fraction*1e9))).In(location), nil
+	case "TAI64N":
+		return time.Time{}, fmt.Errorf("TAI64N dates are not supported by the simulator")
+	}
+
+	layout, err := JavaLayout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	parsed, err := time.ParseInLocation(layout, text, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("does not match format [%s]", format)
+	}
+	if parsed.Year() == 0 {
+		parsed = parsed.AddDate(now.Year(), 0, 0)
+	}
+	return parsed, nil
+}
+
+// JavaLayout converts a Java date-time pattern, as used by Elasticsearch, to
+// a Go time layout
+func JavaLayout(pattern string) (string, error) {
+	var layout strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+
+		if c == '\'' {
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated quote in date format [%s]", pattern)
+			}
+			if end == 0 {
+				layout.WriteByte('\'')
+			} else {
+				layout.WriteString(pattern[i+1 : i+1+end])
+			}
+			i += end + 2
+			continue
+		}
+
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			layout.WriteByte(c)
+			i++
+			continue
+		}
+
+		count := 1
+		for i+count < len(pattern) && pattern[i+count] == c {
+			count++
+		}
+		i += count
+
+		token, err := javaToken(c, count)
+		if err != nil {
+			return "", fmt.Errorf("date format [%s]: %v", pattern, err)
+		}
+		layout.WriteString(token)
+	}
+	return layout.String(), nil
+}
+
+// javaToken returns the Go layout element for a run of a Java pattern letter
+func javaToken(letter byte, count int) (string, error) {
+	switch letter {
+	case 'y', 'u', 'Y':
+		if count == 2 {
+			return "06", nil
+		}
+		return "2006", nil
+	case 'M', 'L':
+		switch {
+		case count >= 4:
+			return "January", nil
+		case count == 3:
+			return "Jan", nil
+		case count == 2:
+			return "01", nil
+		}
+		return "1", nil
+	case 'd':
+		if count >= 2 {
+			return "02", nil
+		}
+		return "2", nil
+	case 'D':
+		return "002", nil
+	case 'H', 'k':
+		return "15", nil
+	case 'h', 'K':
+		if count >= 2 {
+			return "03", nil
+		}
+		return "3", nil
+	case 'm':
+		if count >= 2 {
+			return "04", nil
+		}
+		return "4", nil
+	case 's':
+		if count >= 2 {
+			return "05", nil
+		}
+		return "5", nil
+	case 'S':
+		return strings.Repeat("0", count), nil
+	case 'a':
+		return "PM", nil
+	case 'E':
+		if count >= 4 {
+			return "Monday", nil
+		}
+		return "Mon", nil
+	case 'Z':
+		if count == 2 || count == 5 {
+			return "-07:00", nil
+		}
+		return "-0700", nil
+	case 'X':
+		switch count {
+		case 1:
+			return "Z07", nil
+		case 2, 4:
+			return "Z0700", nil
+		}
+		return "Z07:00", nil
+	case 'x':
+		switch count {
+		case 1:
+			return "-07", nil
+		case 2, 4:
+			return "-0700", nil
+		}
+		return "-07:00", nil
+	case 'z', 'V':
+		return "MST", nil
+	}
+	return "", fmt.Errorf("pattern letter '%c' is not supported", letter)
+}
+
+// loadLocation loads a time zone by name or fixed offset
+func loadLocation(timezone string) (*time.Location, error) {
+	if match := offsetPattern.FindStringSubmatch(timezone); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(timezone, offset), nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone [%s]", timezone)
+	}
+	return location, nil
+}
@@ -0,0 +1,185 @@
+package ingest
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// GrokMismatch explains where a line stopped matching a grok expression
+type GrokMismatch struct {
+	// Offset is the position in the line after the longest matching part of the expression
+	Offset int
+	// Matched is the part of the expression that matched, and MatchedText the text it matched
+	Matched     string
+	MatchedText string
+	// Next is the element of the expression that failed to match at Offset, and
+	// Remaining the rest of the expression from that element on
+	Next      string
+	Remaining string
+}
+
+// DiagnoseGrok finds the longest leading part of a grok expression that
+// matches the line, to show where matching failed. It returns nil when the
+// expression has top-level alternatives, which have no single failure point.
+func DiagnoseGrok(expression string, definitions map[string]string, line string) (*GrokMismatch, error) {
+	tokens, ok := splitExpression(expression)
+	if !ok || len(tokens) == 0 {
+		return nil, nil
+	}
+
+	mismatch := &GrokMismatch{Next: tokens[0], Remaining: expression}
+	prefix := ""
+	for index, token := range tokens {
+		candidate := prefix + token
+		grok, err := CompileGrok(candidate, definitions)
+		if err != nil {
+			return nil, err
+		}
+		location := grok.regexp.FindStringIndex(line)
+		if location == nil {
+			mismatch.Next = token
+			mismatch.Remaining = expression[len(prefix):]
+			return mismatch, nil
+		}
+
+		prefix = candidate
+		mismatch.Offset = location[1]
+		mismatch.Matched = prefix
+		mismatch.MatchedText = line[location[0]:location[1]]
+		if index == len(tokens)-1 {
+			mismatch.Next = ""
+			mismatch.Remaining = ""
+		}
+	}
+	return mismatch, nil
+}
+
+// splitExpression splits a grok expression into its top-level elements:
+// pattern references, groups, character classes, escapes and single
+// characters, each with its quantifier. It reports false when the expression
+// has top-level alternatives.
+func splitExpression(expression string) ([]string, bool) {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		start := i
+		switch expression[i] {
+		case '|':
+			return nil, false
+		case '%':
+			if strings.HasPrefix(expression[i:], "%{") {
+				if end := strings.IndexByte(expression[i:], '}'); end > 0 {
+					i += end + 1
+					break
+				}
+			}
+			i++
+		case '(':
+			i = skipGroup(expression, i)
+		case '[':
+			i = skipClass(expression, i)
+		case '\\':
+			i = skipEscape(expression, i)
+		default:
+			_, size := utf8.DecodeRuneInString(expression[i:])
+			i += size
+		}
+		i = skipQuantifier(expression, i)
+		tokens = append(tokens, expression[start:i])
+	}
+	return tokens, true
+}
+
+// skipGroup returns the position after the group starting at i
+func skipGroup(expression string, i int) int {
+	depth := 0
+	for i < len(expression) {
+		switch expression[i] {
+		case '\\':
+			i = skipEscape(expression, i)
+			continue
+		case '[':
+			i = skipClass(expression, i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return len(expression)
+}
+
+// skipClass returns the position after the character class starting at i
+func skipClass(expression string, i int) int {
+	i++
+	if i < len(expression) && expression[i] == '^' {
+		i++
+	}
+	if i < len(expression) && expression[i] == ']' {
+		i++
+	}
+	for i < len(expression) {
+		switch expression[i] {
+		case '\\':
+			i = skipEscape(expression, i)
+			continue
+		case '[':
+			if strings.HasPrefix(expression[i:], "[:") {
+				if end := strings.Index(expression[i:], ":]"); end > 0 {
+					i += end + 2
+					continue
+				}
+			}
+		case ']':
+			return i + 1
+		}
+		i++
+	}
+	return len(expression)
+}
+
+// skipEscape returns the position after the escape sequence starting at i
+func skipEscape(expression string, i int) int {
+	i++
+	if i >= len(expression) {
+		return len(expression)
+	}
+	escaped, size := utf8.DecodeRuneInString(expression[i:])
+	i += size
+	if i >= len(expression) {
+		return len(expression)
+	}
+	if expression[i] == '{' && strings.ContainsRune("pPx", escaped) {
+		if end := strings.IndexByte(expression[i:], '}'); end >= 0 {
+			return i + end + 1
+		}
+	}
+	return i
+}
+
+// skipQuantifier returns the position after the quantifier at i, if any
+func skipQuantifier(expression string, i int) int {
+	if i >= len(expression) {
+		return i
+	}
+	switch expression[i] {
+	case '*', '+', '?':
+		i++
+	case '{':
+		end := strings.IndexByte(expression[i:], '}')
+		if end < 1 || strings.Trim(expression[i+1:i+end], "0123456789,") != "" {
+			return i
+		}
+		i += end + 1
+	default:
+		return i
+	}
+	if i < len(expression) && (expression[i] == '?' || expression[i] == '+') {
+		i++
+	}
+	return i
+}
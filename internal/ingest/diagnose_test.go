@@ -0,0 +1,70 @@
+package ingest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnoseGrok(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		line       string
+		want       *GrokMismatch
+	}{
+		{
+			name:       "fails at the second reference",
+			expression: `%{IP:client} %{INT:status}`,
+			line:       "10.0.0.1 GET",
+			want:       &GrokMismatch{Offset: 9, Matched: `%{IP:client} `, MatchedText: "10.0.0.1 ", Next: `%{INT:status}`, Remaining: `%{INT:status}`},
+		},
+		{
+			name:       "non-ASCII literals",
+			expression: `é%{WORD:w} ü %{INT:n}`,
+			line:       "éabc üx",
+			want:       &GrokMismatch{Offset: 8, Matched: `é%{WORD:w} ü`, MatchedText: "éabc ü", Next: " ", Remaining: ` %{INT:n}`},
+		},
+		{
+			name:       "quantified groups and classes",
+			expression: `(?:ab)+[0-9]{2}x`,
+			line:       "abab12y",
+			want:       &GrokMismatch{Offset: 6, Matched: `(?:ab)+[0-9]{2}`, MatchedText: "abab12", Next: "x", Remaining: "x"},
+		},
+		{
+			name:       "top-level alternatives",
+			expression: `%{INT:a}|%{WORD:b}`,
+			line:       "-",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := DiagnoseGrok(test.expression, nil, test.line)
+			if err != nil {
+				t.Fatalf("DiagnoseGrok: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DiagnoseGrok = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []string
+	}{
+		{expression: `%{WORD:w} %{INT}`, want: []string{`%{WORD:w}`, " ", `%{INT}`}},
+		{expression: `\d+\p{L}{2}x?`, want: []string{`\d+`, `\p{L}{2}`, `x?`}},
+		{expression: `[^\]a]*(a(b)c)`, want: []string{`[^\]a]*`, `(a(b)c)`}},
+		{expression: `[[:alpha:]]é\ü`, want: []string{`[[:alpha:]]`, "é", `\ü`}},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			got, ok := splitExpression(test.expression)
+			if !ok || !reflect.DeepEqual(got, test.want) {
+				t.Errorf("splitExpression(%q) = %q, %v, want %q", test.expression, got, ok, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dissectKey matches the keys of a dissect pattern, such as %{source.ip} or %{+timestamp/2}
+var dissectKey = regexp.MustCompile(`%\{([^}]*)\}`)
+
+// dissectAppendOrder matches the ordering suffix of an append key, such as /2
+var dissectAppendOrder = regexp.MustCompile(`/(\d+)$`)
+
+// Dissect is a compiled dissect pattern
+type Dissect struct {
+	Pattern         string
+	appendSeparator string
+	prefix          string
+	keys            []dissectField
+}
+
+// dissectField is one key of a dissect pattern and the delimiter following it
+type dissectField struct {
+	name        string
+	modifier    byte
+	order       int
+	rightPad    bool
+	delimiter   string
+	patternSpan string
+}
+
+// DissectError reports where a source stopped matching a dissect pattern
+type DissectError struct {
+	Pattern string
+	Source  string
+	// Offset is the position in the source where the delimiter was expected
+	Offset int
+	// Key is the pattern key being matched, or "" for the leading text
+	Key       string
+	Delimiter string
+}
+
+func (e *DissectError) Error() string {
+	return fmt.Sprintf("Unable to find match for dissect pattern: %s against source: %s", e.Pattern, e.Source)
+}
+
+// CompileDissect parses a dissect pattern
+func CompileDissect(pattern, appendSeparator string) (*Dissect, error) {
+	locations := dissectKey.FindAllStringSubmatchIndex(pattern, -1)
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("Unable to parse pattern: %s", pattern)
+	}
+
+	dissect := &Dissect{Pattern: pattern, appendSeparator: appendSeparator, prefix: pattern[:locations[0][0]]}
+	for index, location := range locations {
+		end := len(pattern)
+		if index+1 < len(locations) {
+			end = locations[index+1][0]
+		}
+		field := dissectField{
+			name:        pattern[location[2]:location[3]],
+			delimiter:   pattern[location[1]:end],
+			patternSpan: pattern[location[0]:location[1]],
+		}
+		if index+1 < len(locations) && field.delimiter == "" {
+			return nil, fmt.Errorf("Unable to parse pattern: %s; keys must be separated by a delimiter", pattern)
+		}
+
+		if strings.HasSuffix(field.name, "->") {
+			field.rightPad = true
+			field.name = strings.TrimSuffix(field.name, "->")
+		}
+		if field.name != "" && strings.ContainsRune("+?*&", rune(field.name[0])) {
+			field.modifier = field.name[0]
+			field.name = field.name[1:]
+		}
+		if field.modifier == '+' {
+			if match := dissectAppendOrder.FindStringSubmatch(field.name); match != nil {
+				field.order, _ = strconv.Atoi(match[1])
+				field.name = strings.TrimSuffix(field.name, match[0])
+			}
+		}
+		dissect.keys = append(dissect.keys, field)
+	}
+	return dissect, nil
+}
+
+// Match splits a source string with the pattern's delimiters and returns the
+// extracted fields
+func (d *Dissect) Match(source string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(source, d.prefix) {
+		return nil, &DissectError{Pattern: d.Pattern, Source: source, Delimiter: d.prefix}
+	}
+	position := len(d.prefix)
+
+	values := make([]string, len(d.keys))
+	for index, key := range d.keys {
+		if key.delimiter == "" {
+			values[index] = source[position:]
+			position = len(source)
+			continue
+		}
+		end := strings.Index(source[position:], key.delimiter)
+		if end < 0 {
+			return nil, &DissectError{Pattern: d.Pattern, Source: source, Offset: position, Key: key.patternSpan, Delimiter: key.delimiter}
+		}
+		values[index] = source[position : position+end]
+		position += end + len(key.delimiter)
+		if key.rightPad {
+			for strings.HasPrefix(source[position:], key.delimiter) {
+				position += len(key.delimiter)
+			}
+		}
+	}
+
+	result := make(map[string]interface{})
+	appended := make(map[string][]dissectAppend)
+	references := make(map[string]string)
+	referenceValues := make(map[string]string)
+	for index, key := range d.keys {
+		switch {
+		case key.name == "" || key.modifier == '?':
+		case key.modifier == '+':
+			appended[key.name] = append(appended[key.name], dissectAppend{key.order, index, values[index]})
+		case key.modifier == '*':
+			references[key.name] = values[index]
+		case key.modifier == '&':
+			referenceValues[key.name] = values[index]
+		default:
+			if _, isAppended := appended[key.name]; isAppended {
+				appended[key.name] = append(appended[key.name], dissectAppend{0, index, values[index]})
+				continue
+			}
+			result[key.name] = values[index]
+		}
+	}
+
+	for name, parts := range appended {
+		if value, exists := result[name]; exists {
+			parts = append(parts, dissectAppend{0, -1, value.(string)})
+			delete(result, name)
+		}
+		sort.SliceStable(parts, func(a, b int) bool {
+			if parts[a].order != parts[b].order {
+				return parts[a].order < parts[b].order
+			}
+			return parts[a].index < parts[b].index
+		})
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			texts = append(texts, part.value)
+		}
+		result[name] = strings.Join(texts, d.appendSeparator)
+	}
+	for name, field := range references {
+		result[field] = referenceValues[name]
+	}
+	return result, nil
+}
+
+// dissectAppend is one value of an append key with its position for ordering
+type dissectAppend struct {
+	order int
+	index int
+	value string
+}
@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDissectMatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		pattern         string
+		appendSeparator string
+		source          string
+		want            map[string]interface{}
+	}{
+		{
+			name:    "keys and delimiters",
+			pattern: `%{client.ip} - %{user.name} [%{timestamp}]`,
+			source:  "10.0.0.1 - alice [10/Oct/2025:13:55:36]",
+			want:    map[string]interface{}{"client.ip": "10.0.0.1", "user.name": "alice", "timestamp": "10/Oct/2025:13:55:36"},
+		},
+		{
+			name:    "leading text",
+			pattern: `[%{level}] %{message}`,
+			source:  "[WARN] disk almost full",
+			want:    map[string]interface{}{"level": "WARN", "message": "disk almost full"},
+		},
+		{
+			name:            "append",
+			pattern:         `%{+name} %{+name} %{age}`,
+			appendSeparator: " ",
+			source:          "john smith 42",
+			want:            map[string]interface{}{"name": "john smith", "age": "42"},
+		},
+		{
+			name:    "append without separator",
+			pattern: `%{+name} %{+name}`,
+			source:  "john smith",
+			want:    map[string]interface{}{"name": "johnsmith"},
+		},
+		{
+			name:            "append order",
+			pattern:         `%{+name/2} %{+name/1}`,
+			appendSeparator: ",",
+			source:          "smith john",
+			want:            map[string]interface{}{"name": "john,smith"},
+		},
+		{
+			name:            "append to a plain key",
+			pattern:         `%{name} %{+name}`,
+			appendSeparator: "-",
+			source:          "a b",
+			want:            map[string]interface{}{"name": "a-b"},
+		},
+		{
+			name:    "reference keys",
+			pattern: `%{*key1}=%{&key1} %{*key2}=%{&key2}`,
+			source:  "user=alice host=web01",
+			want:    map[string]interface{}{"user": "alice", "host": "web01"},
+		},
+		{
+			name:    "skipped keys",
+			pattern: `%{?ignored} %{} %{kept}`,
+			source:  "a b c",
+			want:    map[string]interface{}{"kept": "c"},
+		},
+		{
+			name:    "right padding",
+			pattern: `%{level->} %{message}`,
+			source:  "INFO     started",
+			want:    map[string]interface{}{"level": "INFO", "message": "started"},
+		},
+		{
+			name:    "right padding on a skipped key",
+			pattern: `%{?pad->} %{message}`,
+			source:  "x   done",
+			want:    map[string]interface{}{"message": "done"},
+		},
+		{
+			name:    "last key takes the rest",
+			pattern: `%{a} %{b}`,
+			source:  "1 2 3",
+			want:    map[string]interface{}{"a": "1", "b": "2 3"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dissect, err := CompileDissect(test.pattern, test.appendSeparator)
+			if err != nil {
+				t.Fatalf("CompileDissect(%q): %v", test.pattern, err)
+			}
+			got, err := dissect.Match(test.source)
+			if err != nil {
+				t.Fatalf("Match(%q): %v", test.source, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Match(%q) = %#v, want %#v", test.source, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDissectMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		source  string
+		want    DissectError
+	}{
+		{
+			name:    "missing delimiter",
+			pattern: `%{a} - %{b} [%{c}]`,
+			source:  "x - y z",
+			want:    DissectError{Pattern: `%{a} - %{b} [%{c}]`, Source: "x - y z", Offset: 4, Key: "%{b}", Delimiter: " ["},
+		},
+		{
+			name:    "missing leading text",
+			pattern: `[%{level}] %{message}`,
+			source:  "WARN disk",
+			want:    DissectError{Pattern: `[%{level}] %{message}`, Source: "WARN disk", Delimiter: "["},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dissect, err := CompileDissect(test.pattern, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = dissect.Match(test.source)
+			var mismatch *DissectError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("Match(%q) error = %v, want a *DissectError", test.source, err)
+			}
+			if *mismatch != test.want {
+				t.Errorf("Match(%q) error = %+v, want %+v", test.source, *mismatch, test.want)
+			}
+		})
+	}
+}
+
+func TestCompileDissectErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr string
+	}{
+		{pattern: "no keys here", wantErr: "Unable to parse pattern"},
+		{pattern: "%{a}%{b}", wantErr: "keys must be separated by a delimiter"},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			_, err := CompileDissect(test.pattern, "")
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("CompileDissect(%q) error = %v, want it to contain %q", test.pattern, err, test.wantErr)
+			}
+		})
+	}
+}
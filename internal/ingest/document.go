@@ -0,0 +1,177 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document is an event being processed, with nested objects for dotted field names
+type Document map[string]interface{}
+
+// templatePattern matches mustache references such as {{field}} and {{{field}}}
+var templatePattern = regexp.MustCompile(`\{\{\{?\s*([^{}\s]+)\s*\}?\}\}`)
+
+// Get returns the value of a dotted field
+func (d Document) Get(path string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(d)
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[segment]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Set sets a dotted field, creating intermediate objects. It fails when a
+// parent of the field holds a value that is not an object.
+func (d Document) Set(path string, value interface{}) error {
+	segments := strings.Split(path, ".")
+	current := map[string]interface{}(d)
+	for index, segment := range segments[:len(segments)-1] {
+		next, exists := current[segment]
+		if !exists || next == nil {
+			child := make(map[string]interface{})
+			current[segment] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set [%s] because [%s] is not an object", path, strings.Join(segments[:index+1], "."))
+		}
+		current = child
+	}
+	current[segments[len(segments)-1]] = value
+	return nil
+}
+
+// SetAll sets several dotted fields in sorted order, so that a field is
+// always set before the fields below it and a conflict between fields such
+// as a and a.b is reported the same way on every run
+func (d Document) SetAll(fields map[string]interface{}) error {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := d.Set(path, fields[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes a dotted field and reports whether it existed
+func (d Document) Remove(path string) bool {
+	segments := strings.Split(path, ".")
+	current := map[string]interface{}(d)
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := current[segment].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		current = child
+	}
+	last := segments[len(segments)-1]
+	if _, exists := current[last]; !exists {
+		return false
+	}
+	delete(current, last)
+	return true
+}
+
+// render replaces mustache field references in a template with field values.
+// Missing fields render as empty strings, as in Elasticsearch.
+func (d Document) render(template string, metadata map[string]interface{}) string {
+	return templatePattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := templatePattern.FindStringSubmatch(match)[1]
+		if value, exists := metadata[name]; exists {
+			return valueString(value)
+		}
+		if value, exists := d.Get(name); exists {
+			return valueString(value)
+		}
+		return ""
+	})
+}
+
+// valueString formats a field value the way Elasticsearch renders it in templates
+func valueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// conditionClause matches a null or value comparison of a field, such as
+// "ctx.event?.original == null" or "ctx.event?.dataset != 'nginx.access'"
+var conditionClause = regexp.MustCompile(`^\(?\s*ctx((?:\??\.\w+)+)\s*(==|!=)\s*(null|'[^']*'|"[^"]*"|-?\d+(?:\.\d+)?|true|false)\s*\)?$`)
+
+// evaluateCondition evaluates the subset of Painless conditions made of field
+// comparisons joined by && or ||. It reports false for ok when the condition
+// uses anything else.
+func (d Document) evaluateCondition(condition string) (result bool, ok bool) {
+	condition = strings.TrimSpace(condition)
+	operator := "&&"
+	if strings.Contains(condition, "||") {
+		if strings.Contains(condition, "&&") {
+			return false, false
+		}
+		operator = "||"
+	}
+
+	result = operator == "&&"
+	for _, clause := range strings.Split(condition, operator) {
+		clause = strings.TrimSpace(clause)
+		match := conditionClause.FindStringSubmatch(clause)
+		if match == nil {
+			return false, false
+		}
+
+		path := strings.TrimPrefix(strings.ReplaceAll(match[1], "?.", "."), ".")
+		value, exists := d.Get(path)
+		var equal bool
+		switch literal := match[3]; {
+		case literal == "null":
+			equal = !exists || value == nil
+		case strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, `"`):
+			s, isString := value.(string)
+			equal = exists && isString && s == literal[1:len(literal)-1]
+		default:
+			equal = exists && valueString(value) == literal
+		}
+		if match[2] == "!=" {
+			equal = !equal
+		}
+
+		if operator == "&&" {
+			result = result && equal
+		} else {
+			result = result || equal
+		}
+	}
+	return result, true
+}
+
+// fieldPath converts a Logstash-style field reference such as [source][ip]
+// to a dotted path
+func fieldPath(name string) string {
+	if !strings.HasPrefix(name, "[") {
+		return name
+	}
+	return strings.ReplaceAll(strings.Trim(name, "[]"), "][", ".")
+}
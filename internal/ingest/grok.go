@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxGrokDepth bounds the nesting of pattern references, which also catches cycles
+const maxGrokDepth = 32
+
+// grokReference matches %{SYNTAX}, %{SYNTAX:SEMANTIC} and %{SYNTAX:SEMANTIC:TYPE}
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([^:}]+))?(?::(\w+))?\}`)
+
+// namedGroup matches Oniguruma named groups, (?<name>...), but not lookbehinds
+var namedGroup = regexp.MustCompile(`\(\?<([A-Za-z_@\[\]][^>]*)>`)
+
+// Grok is a compiled grok expression
+type Grok struct {
+	Expression string
+	regexp     *regexp.Regexp
+	captures   map[string]grokCapture
+}
+
+// grokCapture is the field and type of a named capture group
+type grokCapture struct {
+	field     string
+	valueType string
+}
+
+// CompileGrok expands the pattern references of a grok expression using the
+// custom definitions, then the bundled patterns, and compiles it. Go regular
+// expressions do not support lookaround or atomic groups, so expressions using
+// them fail to compile.
+func CompileGrok(expression string, definitions map[string]string) (*Grok, error) {
+	grok := &Grok{Expression: expression, captures: make(map[string]grokCapture)}
+	expanded, err := grok.expand(expression, definitions, 0)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grok expression [%s]: %v", expression, err)
+	}
+	grok.regexp = compiled
+	return grok, nil
+}
+
+// expand replaces pattern references and named groups with Go named groups
+func (g *Grok) expand(expression string, definitions map[string]string, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("grok pattern references nested more than %d levels deep; check for a recursive pattern definition", maxGrokDepth)
+	}
+
+	expression = namedGroup.ReplaceAllStringFunc(expression, func(match string) string {
+		name := namedGroup.FindStringSubmatch(match)[1]
+		return "(?P<" + g.addCapture(name, "") + ">"
+	})
+
+	var expandErr error
+	expanded := grokReference.ReplaceAllStringFunc(expression, func(match string) string {
+		if expandErr != nil {
+			return ""
+		}
+		parts := grokReference.FindStringSubmatch(match)
+		definition, exists := definitions[parts[1]]
+		if !exists {
+			definition, exists = grokPatterns[parts[1]]
+		}
+		if !exists {
+			expandErr = fmt.Errorf("Unable to find pattern [%s] in Grok's pattern dictionary", parts[1])
+			return ""
+		}
+
+		inner, err := g.expand(definition, definitions, depth+1)
+		if err != nil {
+			expandErr = err
+			return ""
+		}
+		if parts[2] == "" {
+			return "(?:" + inner + ")"
+		}
+		return "(?P<" + g.addCapture(parts[2], parts[3]) + ">" + inner + ")"
+	})
+	return expanded, expandErr
+}
+
+// addCapture registers a capture and returns its Go group name. Field names
+// such as [source][ip] or source.ip are not valid group names.
+func (g *Grok) addCapture(field, valueType string) string {
+	name := fmt.Sprintf("g%d", len(g.captures))
+	g.captures[name] = grokCapture{field: fieldPath(field), valueType: valueType}
+	return name
+}
+
+// Match searches text for the expression and returns the captured fields,
+// converted to their types
+func (g *Grok) Match(text string) (map[string]interface{}, bool) {
+	match := g.regexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return nil, false
+	}
+
+	captures := make(map[string]interface{})
+	for index, name := range g.regexp.SubexpNames() {
+		capture, named := g.captures[name]
+		if !named || match[2*index] < 0 {
+			continue
+		}
+		value := text[match[2*index]:match[2*index+1]]
+		captures[capture.field] = convertCapture(value, capture.valueType)
+	}
+	return captures, true
+}
+
+// convertCapture converts a captured value to the type named in the pattern
+func convertCapture(value, valueType string) interface{} {
+	switch valueType {
+	case "int", "long":
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+	case "float", "double":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		return strings.EqualFold(value, "true")
+	}
+	return value
+}
@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGrokMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		definitions map[string]string
+		text        string
+		want        map[string]interface{}
+	}{
+		{
+			name:       "bundled patterns",
+			expression: `%{IP:client.ip} %{WORD:http.request.method}`,
+			text:       "10.0.0.1 GET",
+			want:       map[string]interface{}{"client.ip": "10.0.0.1", "http.request.method": "GET"},
+		},
+		{
+			name:       "unnamed references are not captured",
+			expression: `%{IP} %{WORD:method}`,
+			text:       "10.0.0.1 GET",
+			want:       map[string]interface{}{"method": "GET"},
+		},
+		{
+			name:       "int and long",
+			expression: `%{INT:status:int} %{INT:bytes:long}`,
+			text:       "200 5120",
+			want:       map[string]interface{}{"status": int64(200), "bytes": int64(5120)},
+		},
+		{
+			name:       "float and double",
+			expression: `%{NUMBER:duration:float} %{NUMBER:ratio:double}`,
+			text:       "1.5 0.25",
+			want:       map[string]interface{}{"duration": 1.5, "ratio": 0.25},
+		},
+		{
+			name:       "boolean",
+			expression: `%{WORD:cached:boolean} %{WORD:secure:boolean}`,
+			text:       "TRUE no",
+			want:       map[string]interface{}{"cached": true, "secure": false},
+		},
+		{
+			name:       "failed conversion keeps the string",
+			expression: `%{WORD:status:int}`,
+			text:       "unknown",
+			want:       map[string]interface{}{"status": "unknown"},
+		},
+		{
+			name:        "custom definitions",
+			expression:  `%{RANGE:range}`,
+			definitions: map[string]string{"RANGE": `%{INT}-%{INT}`},
+			text:        "bytes 10-20",
+			want:        map[string]interface{}{"range": "10-20"},
+		},
+		{
+			name:        "custom definitions override bundled patterns",
+			expression:  `%{WORD:word}`,
+			definitions: map[string]string{"WORD": `[a-z]+`},
+			text:        "ABC def",
+			want:        map[string]interface{}{"word": "def"},
+		},
+		{
+			name:        "captures inside definitions",
+			expression:  `%{PAIR}`,
+			definitions: map[string]string{"PAIR": `%{WORD:key}=%{WORD:value}`},
+			text:        "user=alice",
+			want:        map[string]interface{}{"key": "user", "value": "alice"},
+		},
+		{
+			name:       "logstash field references",
+			expression: `%{IP:[source][ip]}`,
+			text:       "192.168.1.1",
+			want:       map[string]interface{}{"source.ip": "192.168.1.1"},
+		},
+		{
+			name:       "oniguruma named groups",
+			expression: `(?<user.name>\w+)@(?<[host][name]>\w+)`,
+			text:       "alice@web01",
+			want:       map[string]interface{}{"user.name": "alice", "host.name": "web01"},
+		},
+		{
+			name:       "optional groups that do not participate",
+			expression: `%{WORD:method}(?: %{INT:status})?`,
+			text:       "GET",
+			want:       map[string]interface{}{"method": "GET"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grok, err := CompileGrok(test.expression, test.definitions)
+			if err != nil {
+				t.Fatalf("CompileGrok(%q): %v", test.expression, err)
+			}
+			got, matched := grok.Match(test.text)
+			if !matched {
+				t.Fatalf("Match(%q) did not match", test.text)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Match(%q) = %#v, want %#v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGrokNoMatch(t *testing.T) {
+	grok, err := CompileGrok(`^%{INT:status} %{WORD:method}$`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captures, matched := grok.Match("GET 200"); matched {
+		t.Errorf("Match = %v, want no match", captures)
+	}
+}
+
+func TestCompileGrokErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		definitions map[string]string
+		wantErr     string
+	}{
+		{name: "unknown pattern", expression: `%{NOPE:x}`, wantErr: "Unable to find pattern [NOPE]"},
+		{name: "recursive definition", expression: `%{LOOP}`, definitions: map[string]string{"LOOP": `a%{LOOP}`}, wantErr: "nested more than"},
+		{name: "lookahead", expression: `(?=foo)%{WORD:w}`, wantErr: "invalid grok expression"},
+		{name: "unbalanced group", expression: `(%{WORD:w}`, wantErr: "invalid grok expression"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := CompileGrok(test.expression, test.definitions)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("CompileGrok(%q) error = %v, want it to contain %q", test.expression, err, test.wantErr)
+			}
+		})
+	}
+}
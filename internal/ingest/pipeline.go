@@ -0,0 +1,395 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxPipelineDepth bounds nested pipeline processors
+const maxPipelineDepth = 10
+
+// pipelineReference matches the package template used by pipeline processors
+// to call another pipeline of the same data stream, {{ IngestPipeline "name" }}
+var pipelineReference = regexp.MustCompile(`IngestPipeline\s+"([^"]+)"`)
+
+// Pipeline is an ingest pipeline definition
+type Pipeline struct {
+	Name        string
+	File        string
+	Description string
+	Processors  []Processor
+	OnFailure   []Processor
+}
+
+// Processor is one processor of a pipeline: its type, such as "grok", and its
+// configuration
+type Processor struct {
+	Type   string
+	Config map[string]interface{}
+}
+
+// Tag returns the processor's tag, if it has one
+func (p Processor) Tag() string {
+	return stringOption(p.Config, "tag")
+}
+
+// Condition returns the processor's "if" condition, if it has one
+func (p Processor) Condition() string {
+	return stringOption(p.Config, "if")
+}
+
+// Summary describes what the processor works on, such as "message -> event.original"
+func (p Processor) Summary() string {
+	var parts []string
+	field := stringOption(p.Config, "field")
+	if values := stringListOption(p.Config, "field"); field == "" && len(values) > 0 {
+		field = strings.Join(values, ", ")
+	}
+	target := stringOption(p.Config, "target_field")
+	switch {
+	case field != "" && target != "":
+		parts = append(parts, field+" -> "+target)
+	case field != "":
+		parts = append(parts, field)
+	}
+
+	switch p.Type {
+	case "set":
+		if copyFrom := stringOption(p.Config, "copy_from"); copyFrom != "" {
+			parts = append(parts, "copy from "+copyFrom)
+		} else if value, exists := p.Config["value"]; exists {
+			parts = append(parts, fmt.Sprintf("= %v", value))
+		}
+	case "convert":
+		parts = append(parts, "to "+stringOption(p.Config, "type"))
+	case "grok":
+		parts = append(parts, fmt.Sprintf("%d pattern(s)", len(stringListOption(p.Config, "patterns"))))
+	case "dissect":
+		parts = append(parts, stringOption(p.Config, "pattern"))
+	case "date":
+		parts = append(parts, "formats "+strings.Join(stringListOption(p.Config, "formats"), ", "))
+	case "pipeline":
+		parts = append(parts, stringOption(p.Config, "name"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// LoadPipelines reads the ingest pipelines of a data stream directory from
+// elasticsearch/ingest_pipeline/*.yml and *.json, sorted by name
+func LoadPipelines(dataStreamDir string) ([]*Pipeline, error) {
+	dir := filepath.Join(dataStreamDir, "elasticsearch", "ingest_pipeline")
+	var files []string
+	for _, pattern := range []string{"*.yml", "*.yaml", "*.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	var pipelines []*Pipeline
+	for _, file := range files {
+		pipeline, err := LoadPipeline(file)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+	return pipelines, nil
+}
+
+// LoadPipeline reads an ingest pipeline definition in YAML or JSON
+func LoadPipeline(path string) (*Pipeline, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition map[string]interface{}
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	base := filepath.Base(path)
+	pipeline := &Pipeline{
+		Name:        strings.TrimSuffix(base, filepath.Ext(base)),
+		File:        path,
+		Description: stringOption(definition, "description"),
+	}
+	if pipeline.Processors, err = parseProcessors(definition["processors"]); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if pipeline.OnFailure, err = parseProcessors(definition["on_failure"]); err != nil {
+		return nil, fmt.Errorf("%s: on_failure: %v", path, err)
+	}
+	return pipeline, nil
+}
+
+// parseProcessors converts a decoded list of single-key processor objects
+func parseProcessors(value interface{}) ([]Processor, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("processors must be a list")
+	}
+
+	processors := make([]Processor, 0, len(items))
+	for index, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok || len(object) != 1 {
+			return nil, fmt.Errorf("processor %d must be an object with a single processor type", index+1)
+		}
+		for processorType, config := range object {
+			configMap, _ := config.(map[string]interface{})
+			if configMap == nil {
+				configMap = make(map[string]interface{})
+			}
+			processors = append(processors, Processor{Type: processorType, Config: configMap})
+		}
+	}
+	return processors, nil
+}
+
+// ProcessorResult records what happened to one processor during a simulation
+type ProcessorResult struct {
+	// Position locates the processor, such as "3", "3.on_failure.1" or, for
+	// a processor of a called pipeline, "5.third-party/2"
+	Position string
+	Type     string
+	Tag      string
+	// Status is "ok", "skipped", "unsupported", "failed" or "ignored_failure"
+	Status  string
+	Message string
+}
+
+// SimulationResult is the outcome of running a pipeline against a document
+type SimulationResult struct {
+	Document Document
+	Trace    []ProcessorResult
+	// Failure is the processor that failed the pipeline, if any
+	Failure *ProcessorResult
+	// Handled reports whether the pipeline's on_failure handlers handled the failure
+	Handled bool
+}
+
+// PipelineResolver returns a pipeline called by a pipeline processor
+type PipelineResolver func(name string) (*Pipeline, error)
+
+// Simulator runs pipelines locally. Only a subset of processors is supported;
+// others are reported as unsupported and left out.
+type Simulator struct {
+	// Resolve looks up pipelines called by pipeline processors; when nil,
+	// pipeline processors are unsupported
+	Resolve PipelineResolver
+	// Now is the time used for {{_ingest.timestamp}} and year-less dates
+	Now time.Time
+
+	trace []ProcessorResult
+}
+
+// NewSimulator creates a simulator resolving pipeline references among the given pipelines
+func NewSimulator(pipelines []*Pipeline) *Simulator {
+	return &Simulator{
+		Resolve: func(name string) (*Pipeline, error) {
+			if match := pipelineReference.FindStringSubmatch(name); match != nil {
+				name = match[1]
+			}
+			for _, pipeline := range pipelines {
+				if pipeline.Name == name {
+					return pipeline, nil
+				}
+			}
+			return nil, fmt.Errorf("pipeline [%s] not found", name)
+		},
+		Now: time.Now().UTC(),
+	}
+}
+
+// Simulate runs a pipeline against a document. The document is modified in place.
+func (s *Simulator) Simulate(pipeline *Pipeline, document Document) *SimulationResult {
+	s.trace = nil
+	failed, err := s.runPipeline(pipeline, document, "", 0)
+	return &SimulationResult{
+		Document: document,
+		Trace:    s.trace,
+		Failure:  failed,
+		Handled:  failed != nil && err == nil,
+	}
+}
+
+// pipelineFailure carries the processor that failed a called pipeline up to
+// the pipeline processor that called it
+type pipelineFailure struct {
+	failed *ProcessorResult
+	err    error
+}
+
+func (p *pipelineFailure) Error() string {
+	return p.err.Error()
+}
+
+// runPipeline runs a pipeline's processors, and its on_failure handlers when
+// one of them fails. It returns the processor that failed, and an error
+// unless the on_failure handlers handled the failure.
+func (s *Simulator) runPipeline(pipeline *Pipeline, document Document, prefix string, depth int) (*ProcessorResult, error) {
+	failed, err := s.runProcessors(pipeline.Processors, document, prefix, depth)
+	if err == nil || len(pipeline.OnFailure) == 0 {
+		return failed, err
+	}
+	if handlerFailed, handlerErr := s.runHandlers(pipeline.OnFailure, document, prefix+"on_failure.", depth, failureMetadata(failed, err)); handlerErr != nil {
+		return handlerFailed, handlerErr
+	}
+	return failed, nil
+}
+
+// runProcessors runs processors in order and stops at the first failure that
+// is neither ignored nor handled by the processor's own on_failure handlers
+func (s *Simulator) runProcessors(processors []Processor, document Document, prefix string, depth int) (*ProcessorResult, error) {
+	for index, processor := range processors {
+		step := ProcessorResult{
+			Position: fmt.Sprintf("%s%d", prefix, index+1),
+			Type:     processor.Type,
+			Tag:      processor.Tag(),
+			Status:   "ok",
+		}
+
+		if condition := processor.Condition(); condition != "" {
+			matched, evaluated := document.evaluateCondition(condition)
+			if !evaluated || !matched {
+				step.Status = "skipped"
+				step.Message = "condition is false: " + condition
+				if !evaluated {
+					step.Message = "condition not evaluated (only field comparisons are supported): " + condition
+				}
+				s.trace = append(s.trace, step)
+				continue
+			}
+		}
+
+		run, supported := processorFuncs[processor.Type]
+		if processor.Type == "pipeline" {
+			run, supported = s.pipelineProcessor(step.Position, depth), s.Resolve != nil
+		}
+		if !supported {
+			step.Status = "unsupported"
+			step.Message = "processor type is not simulated; its changes are missing from the result"
+			s.trace = append(s.trace, step)
+			continue
+		}
+
+		traceIndex := len(s.trace)
+		s.trace = append(s.trace, step)
+		err := run(s, processor.Config, document)
+		if err == nil {
+			continue
+		}
+
+		step.Message = err.Error()
+		step.Status = "failed"
+		if boolOption(processor.Config, "ignore_failure", false) {
+			step.Status = "ignored_failure"
+		}
+		s.trace[traceIndex] = step
+		if step.Status == "ignored_failure" {
+			continue
+		}
+
+		failed := &step
+		var nested *pipelineFailure
+		if errors.As(err, &nested) {
+			failed = nested.failed
+		}
+
+		if handlers, _ := parseProcessors(processor.Config["on_failure"]); len(handlers) > 0 {
+			if handlerFailed, handlerErr := s.runHandlers(handlers, document, step.Position+".on_failure.", depth, failureMetadata(failed, err)); handlerErr != nil {
+				return handlerFailed, handlerErr
+			}
+			continue
+		}
+		return failed, err
+	}
+	return nil, nil
+}
+
+// runHandlers runs on_failure processors with the failure metadata available
+// to their templates as _ingest.*
+func (s *Simulator) runHandlers(handlers []Processor, document Document, prefix string, depth int, metadata map[string]interface{}) (*ProcessorResult, error) {
+	previous, hadIngest := document["_ingest"]
+	document["_ingest"] = metadata
+	defer func() {
+		if hadIngest {
+			document["_ingest"] = previous
+		} else {
+			delete(document, "_ingest")
+		}
+	}()
+	return s.runProcessors(handlers, document, prefix, depth)
+}
+
+// pipelineProcessor runs a called pipeline as part of the current one
+func (s *Simulator) pipelineProcessor(position string, depth int) processorFunc {
+	return func(_ *Simulator, config map[string]interface{}, document Document) error {
+		if depth >= maxPipelineDepth {
+			return fmt.Errorf("pipelines nested more than %d levels deep", maxPipelineDepth)
+		}
+		pipeline, err := s.Resolve(stringOption(config, "name"))
+		if err != nil {
+			if boolOption(config, "ignore_missing_pipeline", false) {
+				return nil
+			}
+			return err
+		}
+		failed, err := s.runPipeline(pipeline, document, position+"."+pipeline.Name+"/", depth+1)
+		if err != nil && failed != nil {
+			return &pipelineFailure{failed: failed, err: err}
+		}
+		return err
+	}
+}
+
+func failureMetadata(failure *ProcessorResult, err error) map[string]interface{} {
+	metadata := map[string]interface{}{"on_failure_message": err.Error()}
+	if failure != nil {
+		metadata["on_failure_processor_type"] = failure.Type
+		metadata["on_failure_processor_tag"] = failure.Tag
+	}
+	return metadata
+}
+
+func stringOption(config map[string]interface{}, key string) string {
+	value, _ := config[key].(string)
+	return value
+}
+
+// stringListOption returns an option given as a string or a list of strings
+func stringListOption(config map[string]interface{}, key string) []string {
+	switch value := config[key].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			values = append(values, valueString(item))
+		}
+		return values
+	}
+	return nil
+}
+
+func boolOption(config map[string]interface{}, key string, fallback bool) bool {
+	if value, ok := config[key].(bool); ok {
+		return value
+	}
+	return fallback
+}
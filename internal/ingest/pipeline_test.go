@@ -0,0 +1,213 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadTestPipeline writes a pipeline definition to a file named after the
+// pipeline and loads it
+func loadTestPipeline(t *testing.T, name, definition string) *Pipeline {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name+".yml")
+	if err := os.WriteFile(path, []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := LoadPipeline(path)
+	if err != nil {
+		t.Fatalf("LoadPipeline: %v", err)
+	}
+	return pipeline
+}
+
+// traceStatuses returns the position and status of every traced processor
+func traceStatuses(trace []ProcessorResult) []string {
+	var statuses []string
+	for _, step := range trace {
+		statuses = append(statuses, step.Position+" "+step.Status)
+	}
+	return statuses
+}
+
+func TestSimulateFailureFlow(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline string
+		document Document
+		want     Document
+		trace    []string
+		failure  string
+		handled  bool
+	}{
+		{
+			name: "ignore_failure continues with the next processor",
+			pipeline: `
+processors:
+  - rename: {field: missing, target_field: other, ignore_failure: true}
+  - set: {field: event.kind, value: event}
+`,
+			document: Document{"message": "x"},
+			want:     Document{"message": "x", "event": map[string]interface{}{"kind": "event"}},
+			trace:    []string{"1 ignored_failure", "2 ok"},
+		},
+		{
+			name: "processor on_failure handles the failure and continues",
+			pipeline: `
+processors:
+  - rename:
+      field: missing
+      target_field: other
+      tag: rename_missing
+      on_failure:
+        - set: {field: error.message, value: "{{_ingest.on_failure_message}}"}
+        - set: {field: error.processor, value: "{{ _ingest.on_failure_processor_type }}/{{{_ingest.on_failure_processor_tag}}}"}
+  - set: {field: done, value: true}
+`,
+			document: Document{},
+			want: Document{
+				"error": map[string]interface{}{"message": "field [missing] doesn't exist", "processor": "rename/rename_missing"},
+				"done":  true,
+			},
+			trace: []string{"1 failed", "1.on_failure.1 ok", "1.on_failure.2 ok", "2 ok"},
+		},
+		{
+			name: "pipeline on_failure stops the processors",
+			pipeline: `
+processors:
+  - set: {field: first, value: 1}
+  - convert: {field: first, type: ip}
+  - set: {field: never, value: 1}
+on_failure:
+  - set: {field: event.kind, value: pipeline_error}
+`,
+			document: Document{},
+			want:     Document{"first": 1, "event": map[string]interface{}{"kind": "pipeline_error"}},
+			trace:    []string{"1 ok", "2 failed", "on_failure.1 ok"},
+			failure:  "2",
+			handled:  true,
+		},
+		{
+			name: "failing on_failure handler fails the pipeline",
+			pipeline: `
+processors:
+  - remove: {field: missing}
+on_failure:
+  - rename: {field: also_missing, target_field: x}
+`,
+			document: Document{},
+			want:     Document{},
+			trace:    []string{"1 failed", "on_failure.1 failed"},
+			failure:  "on_failure.1",
+		},
+		{
+			name: "unhandled failure",
+			pipeline: `
+processors:
+  - json: {field: message}
+  - set: {field: never, value: 1}
+`,
+			document: Document{"message": "{not json"},
+			want:     Document{"message": "{not json"},
+			trace:    []string{"1 failed"},
+			failure:  "1",
+		},
+		{
+			name: "conditions and unsupported processors",
+			pipeline: `
+processors:
+  - set: {field: a, value: 1, if: "ctx.event?.kind == 'alert'"}
+  - set: {field: b, value: 2, if: "ctx.message != null"}
+  - set: {field: c, value: 3, if: "ctx.message.contains('x')"}
+  - user_agent: {field: agent}
+`,
+			document: Document{"message": "x"},
+			want:     Document{"message": "x", "b": 2},
+			trace:    []string{"1 skipped", "2 ok", "3 skipped", "4 unsupported"},
+		},
+		{
+			name: "_ingest metadata is removed after the handlers",
+			pipeline: `
+processors:
+  - remove: {field: missing, on_failure: [{set: {field: handled, value: true}}]}
+`,
+			document: Document{},
+			want:     Document{"handled": true},
+			trace:    []string{"1 failed", "1.on_failure.1 ok"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pipeline := loadTestPipeline(t, "default", test.pipeline)
+			result := NewSimulator([]*Pipeline{pipeline}).Simulate(pipeline, test.document)
+
+			if !reflect.DeepEqual(result.Document, test.want) {
+				t.Errorf("document = %#v, want %#v", result.Document, test.want)
+			}
+			if got := traceStatuses(result.Trace); !reflect.DeepEqual(got, test.trace) {
+				t.Errorf("trace = %q, want %q", got, test.trace)
+			}
+			failure := ""
+			if result.Failure != nil {
+				failure = result.Failure.Position
+			}
+			if failure != test.failure {
+				t.Errorf("failure = %q, want %q", failure, test.failure)
+			}
+			if result.Handled != test.handled {
+				t.Errorf("handled = %v, want %v", result.Handled, test.handled)
+			}
+		})
+	}
+}
+
+func TestSimulatePipelineProcessor(t *testing.T) {
+	main := loadTestPipeline(t, "default", `
+processors:
+  - set: {field: stage, value: main}
+  - pipeline: {name: '{{ IngestPipeline "third-party" }}'}
+  - set: {field: never, value: true}
+`)
+	thirdParty := loadTestPipeline(t, "third-party", `
+processors:
+  - set: {field: stage, value: third-party}
+  - date: {field: missing, formats: [ISO8601]}
+`)
+	result := NewSimulator([]*Pipeline{main, thirdParty}).Simulate(main, Document{})
+
+	wantTrace := []string{"1 ok", "2 failed", "2.third-party/1 ok", "2.third-party/2 failed"}
+	if got := traceStatuses(result.Trace); !reflect.DeepEqual(got, wantTrace) {
+		t.Errorf("trace = %q, want %q", got, wantTrace)
+	}
+	if result.Failure == nil || result.Failure.Position != "2.third-party/2" || result.Failure.Type != "date" {
+		t.Errorf("failure = %+v, want the date processor of third-party", result.Failure)
+	}
+	if got := result.Document["stage"]; got != "third-party" {
+		t.Errorf("stage = %v, want third-party", got)
+	}
+
+	missing := loadTestPipeline(t, "default", `
+processors:
+  - pipeline: {name: nope, ignore_missing_pipeline: true}
+  - pipeline: {name: nope}
+`)
+	result = NewSimulator([]*Pipeline{missing}).Simulate(missing, Document{})
+	if got := traceStatuses(result.Trace); !reflect.DeepEqual(got, []string{"1 ok", "2 failed"}) {
+		t.Errorf("trace = %q", got)
+	}
+	if result.Failure == nil || result.Failure.Message != "pipeline [nope] not found" {
+		t.Errorf("failure = %+v, want pipeline [nope] not found", result.Failure)
+	}
+}
+
+func TestSimulateRecursivePipeline(t *testing.T) {
+	loop := loadTestPipeline(t, "loop", `
+processors:
+  - pipeline: {name: loop}
+`)
+	result := NewSimulator([]*Pipeline{loop}).Simulate(loop, Document{})
+	if result.Failure == nil || result.Failure.Message != "pipelines nested more than 10 levels deep" {
+		t.Errorf("failure = %+v, want the nesting limit", result.Failure)
+	}
+}
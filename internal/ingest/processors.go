@@ -0,0 +1,312 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// processorFunc applies a processor's configuration to a document
+type processorFunc func(s *Simulator, config map[string]interface{}, document Document) error
+
+// processorFuncs holds the processor types the simulator supports
+var processorFuncs = map[string]processorFunc{
+	"grok":    grokProcessor,
+	"dissect": dissectProcessor,
+	"rename":  renameProcessor,
+	"set":     setProcessor,
+	"convert": convertProcessor,
+	"date":    dateProcessor,
+	"json":    jsonProcessor,
+	"remove":  removeProcessor,
+}
+
+// SupportedProcessors returns the processor types the simulator runs
+func SupportedProcessors() []string {
+	return []string{"grok", "dissect", "rename", "set", "convert", "date", "json", "remove", "pipeline"}
+}
+
+// sourceString returns the string value of a processor's source field. It
+// returns "" without an error when the field is missing and ignore_missing is set.
+func sourceString(config map[string]interface{}, document Document) (string, bool, error) {
+	field := stringOption(config, "field")
+	value, exists := document.Get(field)
+	if !exists || value == nil {
+		if boolOption(config, "ignore_missing", false) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("field [%s] not present as part of path [%s]", field, field)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", false, fmt.Errorf("field [%s] of type [%T] cannot be cast to [java.lang.String]", field, value)
+	}
+	return text, true, nil
+}
+
+func grokProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	text, found, err := sourceString(config, document)
+	if err != nil || !found {
+		return err
+	}
+
+	definitions := make(map[string]string)
+	if custom, ok := config["pattern_definitions"].(map[string]interface{}); ok {
+		for name, definition := range custom {
+			definitions[name] = valueString(definition)
+		}
+	}
+
+	patterns := stringListOption(config, "patterns")
+	for _, pattern := range patterns {
+		grok, err := CompileGrok(pattern, definitions)
+		if err != nil {
+			return err
+		}
+		captures, matched := grok.Match(text)
+		if !matched {
+			continue
+		}
+		return document.SetAll(captures)
+	}
+	return fmt.Errorf("Provided Grok expressions do not match field value: [%s]", text)
+}
+
+func dissectProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	text, found, err := sourceString(config, document)
+	if err != nil || !found {
+		return err
+	}
+
+	dissect, err := CompileDissect(stringOption(config, "pattern"), stringOption(config, "append_separator"))
+	if err != nil {
+		return err
+	}
+	values, err := dissect.Match(text)
+	if err != nil {
+		return err
+	}
+	return document.SetAll(values)
+}
+
+func renameProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	field := stringOption(config, "field")
+	target := stringOption(config, "target_field")
+	value, exists := document.Get(field)
+	if !exists {
+		if boolOption(config, "ignore_missing", false) {
+			return nil
+		}
+		return fmt.Errorf("field [%s] doesn't exist", field)
+	}
+	if _, taken := document.Get(target); taken && !boolOption(config, "override", false) {
+		return fmt.Errorf("field [%s] already exists", target)
+	}
+	document.Remove(field)
+	return document.Set(target, value)
+}
+
+func setProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	field := document.render(stringOption(config, "field"), s.metadata())
+	if _, exists := document.Get(field); exists && !boolOption(config, "override", true) {
+		return nil
+	}
+
+	var value interface{}
+	if copyFrom := stringOption(config, "copy_from"); copyFrom != "" {
+		copied, exists := document.Get(copyFrom)
+		if !exists {
+			return fmt.Errorf("field [%s] not present as part of path [%s]", copyFrom, copyFrom)
+		}
+		value = copied
+	} else {
+		value = config["value"]
+		if template, ok := value.(string); ok {
+			value = document.render(template, s.metadata())
+		}
+	}
+
+	if boolOption(config, "ignore_empty_value", false) && (value == nil || value == "") {
+		return nil
+	}
+	return document.Set(field, value)
+}
+
+func convertProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	field := stringOption(config, "field")
+	target := stringOption(config, "target_field")
+	if target == "" {
+		target = field
+	}
+	targetType := stringOption(config, "type")
+
+	value, exists := document.Get(field)
+	if !exists || value == nil {
+		if boolOption(config, "ignore_missing", false) {
+			return nil
+		}
+		return fmt.Errorf("field [%s] not present as part of path [%s]", field, field)
+	}
+
+	if items, ok := value.([]interface{}); ok {
+		converted := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			result, err := convertValue(item, targetType)
+			if err != nil {
+				return err
+			}
+			converted = append(converted, result)
+		}
+		return document.Set(target, converted)
+	}
+
+	converted, err := convertValue(value, targetType)
+	if err != nil {
+		return err
+	}
+	return document.Set(target, converted)
+}
+
+// convertValue converts a value to one of the convert processor's types
+func convertValue(value interface{}, targetType string) (interface{}, error) {
+	text := strings.TrimSpace(valueString(value))
+	switch targetType {
+	case "integer", "long":
+		if number, err := strconv.ParseInt(text, 0, 64); err == nil {
+			return number, nil
+		}
+		if number, ok := value.(float64); ok && number == float64(int64(number)) {
+			return int64(number), nil
+		}
+	case "float", "double":
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number, nil
+		}
+	case "boolean":
+		switch strings.ToLower(text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	case "string":
+		return valueString(value), nil
+	case "ip":
+		if net.ParseIP(text) != nil {
+			return text, nil
+		}
+		return nil, fmt.Errorf("'%s' is not an IP string literal", text)
+	case "auto":
+		if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return number, nil
+		}
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number, nil
+		}
+		if b, err := strconv.ParseBool(text); err == nil && (text == "true" || text == "false") {
+			return b, nil
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("type [%s] not supported, cannot convert field", targetType)
+	}
+	return nil, fmt.Errorf("unable to convert [%s] to %s", text, targetType)
+}
+
+func dateProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	field := stringOption(config, "field")
+	target := stringOption(config, "target_field")
+	if target == "" {
+		target = "@timestamp"
+	}
+
+	value, exists := document.Get(field)
+	if !exists || value == nil {
+		if boolOption(config, "ignore_missing", false) {
+			return nil
+		}
+		return fmt.Errorf("field [%s] not present as part of path [%s]", field, field)
+	}
+	text := valueString(value)
+
+	location := time.UTC
+	if timezone := document.render(stringOption(config, "timezone"), s.metadata()); timezone != "" {
+		loaded, err := loadLocation(timezone)
+		if err != nil {
+			return err
+		}
+		location = loaded
+	}
+
+	formats := stringListOption(config, "formats")
+	var lastErr error
+	for _, format := range formats {
+		parsed, err := ParseDate(text, format, location, s.Now)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		outputFormat := stringOption(config, "output_format")
+		if outputFormat == "" {
+			return document.Set(target, parsed.Format("2006-01-02T15:04:05.000Z07:00"))
+		}
+		layout, err := JavaLayout(outputFormat)
+		if err != nil {
+			return err
+		}
+		return document.Set(target, parsed.Format(layout))
+	}
+	if lastErr != nil {
+		return fmt.Errorf("unable to parse date [%s]: %v", text, lastErr)
+	}
+	return fmt.Errorf("unable to parse date [%s]", text)
+}
+
+func jsonProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	field := stringOption(config, "field")
+	text, found, err := sourceString(config, document)
+	if err != nil || !found {
+		return err
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		return fmt.Errorf("cannot parse [%s] as JSON: %v", field, err)
+	}
+
+	if boolOption(config, "add_to_root", false) {
+		object, ok := parsed.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot add non-map fields to root of document")
+		}
+		for key, value := range object {
+			document[key] = value
+		}
+		return nil
+	}
+
+	target := stringOption(config, "target_field")
+	if target == "" {
+		target = field
+	}
+	return document.Set(target, parsed)
+}
+
+func removeProcessor(s *Simulator, config map[string]interface{}, document Document) error {
+	for _, field := range stringListOption(config, "field") {
+		if !document.Remove(field) && !boolOption(config, "ignore_missing", false) {
+			return fmt.Errorf("field [%s] not present as part of path [%s]", field, field)
+		}
+	}
+	return nil
+}
+
+// metadata returns the ingest metadata available to templates
+func (s *Simulator) metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"_ingest.timestamp": s.Now.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,329 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessors(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor string
+		config    map[string]interface{}
+		document  Document
+		want      Document
+		wantErr   string
+	}{
+		// grok
+		{
+			name:      "grok first matching pattern",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`^%{INT:code:int}$`, `^%{WORD:word}$`}},
+			document:  Document{"message": "hello"},
+			want:      Document{"message": "hello", "word": "hello"},
+		},
+		{
+			name:      "grok pattern definitions",
+			processor: "grok",
+			config: map[string]interface{}{"field": "message", "patterns": []interface{}{`%{LEVEL:log.level}`},
+				"pattern_definitions": map[string]interface{}{"LEVEL": "INFO|WARN"}},
+			document: Document{"message": "WARN x"},
+			want:     Document{"message": "WARN x", "log": map[string]interface{}{"level": "WARN"}},
+		},
+		{
+			name:      "grok no match",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`^%{INT:code}$`}},
+			document:  Document{"message": "hello"},
+			wantErr:   "Provided Grok expressions do not match field value: [hello]",
+		},
+		{
+			name:      "grok missing field",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`%{WORD:w}`}},
+			document:  Document{},
+			wantErr:   "field [message] not present as part of path [message]",
+		},
+		{
+			name:      "grok missing field with ignore_missing",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`%{WORD:w}`}, "ignore_missing": true},
+			document:  Document{},
+			want:      Document{},
+		},
+		{
+			name:      "grok field that is not a string",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`%{WORD:w}`}},
+			document:  Document{"message": 42},
+			wantErr:   "field [message] of type [int] cannot be cast to [java.lang.String]",
+		},
+		{
+			name:      "grok invalid pattern",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`%{NOPE:w}`}},
+			document:  Document{"message": "x"},
+			wantErr:   "Unable to find pattern [NOPE]",
+		},
+		{
+			name:      "grok conflicting captures",
+			processor: "grok",
+			config:    map[string]interface{}{"field": "message", "patterns": []interface{}{`%{WORD:a.b} %{WORD:a}`}},
+			document:  Document{"message": "x y"},
+			wantErr:   "cannot set [a.b] because [a] is not an object",
+		},
+		// dissect
+		{
+			name:      "dissect",
+			processor: "dissect",
+			config:    map[string]interface{}{"field": "message", "pattern": "%{+name} %{+name}", "append_separator": " "},
+			document:  Document{"message": "john smith"},
+			want:      Document{"message": "john smith", "name": "john smith"},
+		},
+		{
+			name:      "dissect mismatch",
+			processor: "dissect",
+			config:    map[string]interface{}{"field": "message", "pattern": "%{a}|%{b}"},
+			document:  Document{"message": "x y"},
+			wantErr:   "Unable to find match for dissect pattern: %{a}|%{b} against source: x y",
+		},
+		{
+			name:      "dissect invalid pattern",
+			processor: "dissect",
+			config:    map[string]interface{}{"field": "message", "pattern": "%{a}%{b}"},
+			document:  Document{"message": "x"},
+			wantErr:   "keys must be separated by a delimiter",
+		},
+		{
+			name:      "dissect missing field",
+			processor: "dissect",
+			config:    map[string]interface{}{"field": "message", "pattern": "%{a}"},
+			document:  Document{},
+			wantErr:   "field [message] not present",
+		},
+		// rename
+		{
+			name:      "rename",
+			processor: "rename",
+			config:    map[string]interface{}{"field": "a.b", "target_field": "c"},
+			document:  Document{"a": map[string]interface{}{"b": "x"}},
+			want:      Document{"a": map[string]interface{}{}, "c": "x"},
+		},
+		{
+			name:      "rename missing field",
+			processor: "rename",
+			config:    map[string]interface{}{"field": "a", "target_field": "b"},
+			document:  Document{},
+			wantErr:   "field [a] doesn't exist",
+		},
+		{
+			name:      "rename onto an existing field",
+			processor: "rename",
+			config:    map[string]interface{}{"field": "a", "target_field": "b"},
+			document:  Document{"a": 1, "b": 2},
+			wantErr:   "field [b] already exists",
+		},
+		{
+			name:      "rename onto an existing field with override",
+			processor: "rename",
+			config:    map[string]interface{}{"field": "a", "target_field": "b", "override": true},
+			document:  Document{"a": 1, "b": 2},
+			want:      Document{"b": 1},
+		},
+		// set
+		{
+			name:      "set template",
+			processor: "set",
+			config:    map[string]interface{}{"field": "event.{{kind}}", "value": "{{message}}-{{missing}}"},
+			document:  Document{"kind": "alert", "message": "x"},
+			want:      Document{"kind": "alert", "message": "x", "event": map[string]interface{}{"alert": "x-"}},
+		},
+		{
+			name:      "set without override",
+			processor: "set",
+			config:    map[string]interface{}{"field": "a", "value": 2, "override": false},
+			document:  Document{"a": 1},
+			want:      Document{"a": 1},
+		},
+		{
+			name:      "set ignore_empty_value",
+			processor: "set",
+			config:    map[string]interface{}{"field": "a", "value": "{{missing}}", "ignore_empty_value": true},
+			document:  Document{},
+			want:      Document{},
+		},
+		{
+			name:      "set copy_from missing field",
+			processor: "set",
+			config:    map[string]interface{}{"field": "a", "copy_from": "b"},
+			document:  Document{},
+			wantErr:   "field [b] not present as part of path [b]",
+		},
+		{
+			name:      "set below a scalar",
+			processor: "set",
+			config:    map[string]interface{}{"field": "a.b", "value": 1},
+			document:  Document{"a": "x"},
+			wantErr:   "cannot set [a.b] because [a] is not an object",
+		},
+		// convert
+		{
+			name:      "convert list",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "type": "integer"},
+			document:  Document{"a": []interface{}{"1", " 0x10 "}},
+			want:      Document{"a": []interface{}{int64(1), int64(16)}},
+		},
+		{
+			name:      "convert auto to target field",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "target_field": "b", "type": "auto"},
+			document:  Document{"a": "1.5"},
+			want:      Document{"a": "1.5", "b": 1.5},
+		},
+		{
+			name:      "convert unparsable value",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "type": "long"},
+			document:  Document{"a": "ten"},
+			wantErr:   "unable to convert [ten] to long",
+		},
+		{
+			name:      "convert invalid ip",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "type": "ip"},
+			document:  Document{"a": "300.1.1.1"},
+			wantErr:   "'300.1.1.1' is not an IP string literal",
+		},
+		{
+			name:      "convert unsupported type",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "type": "date"},
+			document:  Document{"a": "x"},
+			wantErr:   "type [date] not supported, cannot convert field",
+		},
+		{
+			name:      "convert list with a bad item",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "type": "boolean"},
+			document:  Document{"a": []interface{}{"true", "maybe"}},
+			wantErr:   "unable to convert [maybe] to boolean",
+		},
+		{
+			name:      "convert missing field",
+			processor: "convert",
+			config:    map[string]interface{}{"field": "a", "type": "long"},
+			document:  Document{},
+			wantErr:   "field [a] not present as part of path [a]",
+		},
+		// date
+		{
+			name:      "date java pattern in a time zone",
+			processor: "date",
+			config: map[string]interface{}{"field": "ts", "formats": []interface{}{"ISO8601", "dd/MMM/yyyy:HH:mm:ss"},
+				"timezone": "+02:00"},
+			document: Document{"ts": "10/Oct/2025:13:55:36"},
+			want:     Document{"ts": "10/Oct/2025:13:55:36", "@timestamp": "2025-10-10T13:55:36.000+02:00"},
+		},
+		{
+			name:      "date year-less pattern and output format",
+			processor: "date",
+			config: map[string]interface{}{"field": "ts", "formats": "MMM d HH:mm:ss", "target_field": "event.created",
+				"output_format": "yyyy-MM-dd"},
+			document: Document{"ts": "Oct 5 01:02:03"},
+			want:     Document{"ts": "Oct 5 01:02:03", "event": map[string]interface{}{"created": "2025-10-05"}},
+		},
+		{
+			name:      "date that matches no format",
+			processor: "date",
+			config:    map[string]interface{}{"field": "ts", "formats": []interface{}{"UNIX", "ISO8601"}},
+			document:  Document{"ts": "yesterday"},
+			wantErr:   "unable to parse date [yesterday]: not an ISO8601 date",
+		},
+		{
+			name:      "date invalid time zone",
+			processor: "date",
+			config:    map[string]interface{}{"field": "ts", "formats": []interface{}{"ISO8601"}, "timezone": "Mars/Olympus"},
+			document:  Document{"ts": "2025-01-01"},
+			wantErr:   "Mars/Olympus",
+		},
+		{
+			name:      "date missing field",
+			processor: "date",
+			config:    map[string]interface{}{"field": "ts", "formats": []interface{}{"ISO8601"}},
+			document:  Document{},
+			wantErr:   "field [ts] not present as part of path [ts]",
+		},
+		// json
+		{
+			name:      "json to target field",
+			processor: "json",
+			config:    map[string]interface{}{"field": "message", "target_field": "parsed"},
+			document:  Document{"message": `{"a": 1}`},
+			want:      Document{"message": `{"a": 1}`, "parsed": map[string]interface{}{"a": 1.0}},
+		},
+		{
+			name:      "json add_to_root",
+			processor: "json",
+			config:    map[string]interface{}{"field": "message", "add_to_root": true},
+			document:  Document{"message": `{"a": 1}`},
+			want:      Document{"message": `{"a": 1}`, "a": 1.0},
+		},
+		{
+			name:      "json invalid",
+			processor: "json",
+			config:    map[string]interface{}{"field": "message"},
+			document:  Document{"message": "{"},
+			wantErr:   "cannot parse [message] as JSON",
+		},
+		{
+			name:      "json add_to_root with an array",
+			processor: "json",
+			config:    map[string]interface{}{"field": "message", "add_to_root": true},
+			document:  Document{"message": "[1]"},
+			wantErr:   "cannot add non-map fields to root of document",
+		},
+		// remove
+		{
+			name:      "remove several fields",
+			processor: "remove",
+			config:    map[string]interface{}{"field": []interface{}{"a", "b.c"}},
+			document:  Document{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}},
+			want:      Document{"b": map[string]interface{}{"d": 3}},
+		},
+		{
+			name:      "remove missing field",
+			processor: "remove",
+			config:    map[string]interface{}{"field": "a"},
+			document:  Document{},
+			wantErr:   "field [a] not present as part of path [a]",
+		},
+		{
+			name:      "remove missing field with ignore_missing",
+			processor: "remove",
+			config:    map[string]interface{}{"field": "a", "ignore_missing": true},
+			document:  Document{},
+			want:      Document{},
+		},
+	}
+	simulator := &Simulator{Now: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := processorFuncs[test.processor](simulator, test.config, test.document)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(test.document, test.want) {
+				t.Errorf("document = %#v, want %#v", test.document, test.want)
+			}
+		})
+	}
+}
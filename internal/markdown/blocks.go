@@ -0,0 +1,157 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Block kinds returned by Parse
+const (
+	BlockHeading   = "heading"
+	BlockParagraph = "paragraph"
+	BlockList      = "list"
+	BlockCode      = "code"
+	BlockTable     = "table"
+)
+
+// Block is a top-level element of a Markdown document
+type Block struct {
+	Kind string
+	// Level is the level of a heading
+	Level int
+	// Text is the text of a heading or paragraph, or the content of a code block
+	Text string
+	// Language is the info string of a code block
+	Language string
+	// Ordered, Start and Items describe a list; Start is the number of the
+	// first item of an ordered list
+	Ordered bool
+	Start   int
+	Items   []ListItem
+	// Rows are the cells of a table, header row first
+	Rows [][]string
+}
+
+// ListItem is an item of a list: its first paragraph, and any blocks
+// indented under it such as code blocks or nested lists
+type ListItem struct {
+	Text   string
+	Blocks []Block
+}
+
+// Parse splits a Markdown document into blocks. ToHTML renders these blocks,
+// so both support the same syntax.
+func Parse(source string) []Block {
+	return parseBlocks(strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n"))
+}
+
+func parseBlocks(lines []string) []Block {
+	var blocks []Block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case strings.HasPrefix(trimmed, "```"):
+			var block Block
+			block, i = parseCode(lines, i)
+			blocks = append(blocks, block)
+		case headingLevel(line) > 0:
+			level := headingLevel(line)
+			blocks = append(blocks, Block{Kind: BlockHeading, Level: level, Text: strings.TrimSpace(line[level:])})
+			i++
+		case strings.HasPrefix(line, "|") && i+1 < len(lines) && tableDivider.MatchString(strings.TrimSpace(lines[i+1])):
+			block := Block{Kind: BlockTable, Rows: [][]string{tableCells(line)}}
+			for i += 2; i < len(lines) && strings.HasPrefix(lines[i], "|"); i++ {
+				block.Rows = append(block.Rows, tableCells(lines[i]))
+			}
+			blocks = append(blocks, block)
+		case listMarker(line) > 0:
+			var block Block
+			block, i = parseList(lines, i)
+			blocks = append(blocks, block)
+		default:
+			var text []string
+			start := i
+			for ; i < len(lines); i++ {
+				line := lines[i]
+				if strings.TrimSpace(line) == "" || headingLevel(line) > 0 || listMarker(line) > 0 ||
+					strings.HasPrefix(strings.TrimLeft(line, " "), "```") || (i > start && strings.HasPrefix(line, "|")) {
+					break
+				}
+				text = append(text, strings.TrimSpace(line))
+			}
+			blocks = append(blocks, Block{Kind: BlockParagraph, Text: strings.Join(text, "\n")})
+		}
+	}
+	return blocks
+}
+
+func parseCode(lines []string, start int) (Block, int) {
+	opening := lines[start]
+	indent := indentation(opening)
+	block := Block{Kind: BlockCode, Language: strings.TrimSpace(strings.TrimLeft(opening, " ")[3:])}
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+			i++
+			break
+		}
+		code = append(code, dedent(lines[i], indent))
+	}
+	block.Text = strings.Join(code, "\n")
+	return block, i
+}
+
+// parseList parses consecutive items of one kind of list. Lines indented
+// under an item, such as code blocks, belong to the item, and items separated
+// by blank lines still form one list.
+func parseList(lines []string, start int) (Block, int) {
+	ordered := orderedItem.MatchString(lines[start])
+	block := Block{Kind: BlockList, Ordered: ordered}
+	if ordered {
+		block.Start, _ = strconv.Atoi(orderedItem.FindStringSubmatch(lines[start])[1])
+	}
+
+	i := start
+	for i < len(lines) && listMarker(lines[i]) > 0 && orderedItem.MatchString(lines[i]) == ordered {
+		width := listMarker(lines[i])
+		body := []string{lines[i][width:]}
+		i++
+		for i < len(lines) {
+			if strings.TrimSpace(lines[i]) == "" {
+				next := nextNonBlank(lines, i)
+				if next < len(lines) && indentation(lines[next]) >= 2 {
+					body = append(body, lines[i:next]...)
+					i = next
+					continue
+				}
+				break
+			}
+			if indentation(lines[i]) < 2 {
+				break
+			}
+			body = append(body, dedent(lines[i], width))
+			i++
+		}
+
+		// A single line is the item's text, even when it looks like a heading
+		item := ListItem{}
+		if len(body) == 1 {
+			item.Text = strings.TrimSpace(body[0])
+		} else if nested := parseBlocks(body); len(nested) > 0 && nested[0].Kind == BlockParagraph {
+			item.Text, item.Blocks = nested[0].Text, nested[1:]
+		} else {
+			item.Blocks = nested
+		}
+		block.Items = append(block.Items, item)
+
+		if next := nextNonBlank(lines, i); next < len(lines) && listMarker(lines[next]) > 0 && orderedItem.MatchString(lines[next]) == ordered {
+			i = next
+		}
+	}
+	return block, i
+}
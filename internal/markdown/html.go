@@ -0,0 +1,229 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	orderedItem  = regexp.MustCompile(`^(\d+)\. `)
+	tableDivider = regexp.MustCompile(`^\|[-:| ]+\|$`)
+	boldText     = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	linkText     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// ToHTML converts the Markdown written by the README and site renderers to
+// HTML. It supports ATX headings, paragraphs, bullet and numbered lists,
+// fenced code blocks, tables, inline code, bold text and links; anything else
+// is kept as text. Link targets are passed through link when it is not nil.
+func ToHTML(source string, link func(string) string) string {
+	if link == nil {
+		link = func(target string) string { return target }
+	}
+	var result strings.Builder
+	writeBlocks(&result, Parse(source), link)
+	return result.String()
+}
+
+// Slug returns the anchor ToHTML gives a heading
+func Slug(text string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return slug.String()
+}
+
+// writeBlocks writes parsed blocks as HTML
+func writeBlocks(result *strings.Builder, blocks []Block, link func(string) string) {
+	for _, block := range blocks {
+		switch block.Kind {
+		case BlockHeading:
+			fmt.Fprintf(result, "<h%d id=\"%s\">%s</h%d>\n", block.Level, Slug(block.Text), inline(block.Text, link), block.Level)
+		case BlockCode:
+			writeCode(result, block)
+		case BlockTable:
+			writeTable(result, block, link)
+		case BlockList:
+			writeList(result, block, link)
+		default:
+			fmt.Fprintf(result, "<p>%s</p>\n", inline(block.Text, link))
+		}
+	}
+}
+
+// headingLevel returns the level of an ATX heading line, or 0
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level >= len(line) || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// listMarker returns the width of a list item's marker, or 0 when the line
+// does not start a list item
+func listMarker(line string) int {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+		return 2
+	}
+	if match := orderedItem.FindString(line); match != "" {
+		return len(match)
+	}
+	return 0
+}
+
+func writeCode(result *strings.Builder, block Block) {
+	if block.Language != "" {
+		fmt.Fprintf(result, "<pre><code class=\"language-%s\">", html.EscapeString(block.Language))
+	} else {
+		result.WriteString("<pre><code>")
+	}
+	result.WriteString(html.EscapeString(block.Text))
+	result.WriteString("</code></pre>\n")
+}
+
+func writeTable(result *strings.Builder, block Block, link func(string) string) {
+	result.WriteString("<table>\n<thead>\n<tr>")
+	for _, cell := range block.Rows[0] {
+		fmt.Fprintf(result, "<th>%s</th>", inline(cell, link))
+	}
+	result.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range block.Rows[1:] {
+		result.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(result, "<td>%s</td>", inline(cell, link))
+		}
+		result.WriteString("</tr>\n")
+	}
+	result.WriteString("</tbody>\n</table>\n")
+}
+
+// tableCells splits a table row on unescaped pipes
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// writeList writes a list. Items with blocks indented under them, such as
+// code blocks, get their first paragraph as a paragraph of its own.
+func writeList(result *strings.Builder, block Block, link func(string) string) {
+	switch {
+	case !block.Ordered:
+		result.WriteString("<ul>\n")
+	case block.Start != 1:
+		fmt.Fprintf(result, "<ol start=\"%d\">\n", block.Start)
+	default:
+		result.WriteString("<ol>\n")
+	}
+
+	for _, item := range block.Items {
+		if len(item.Blocks) == 0 {
+			fmt.Fprintf(result, "<li>%s</li>\n", inline(item.Text, link))
+			continue
+		}
+		result.WriteString("<li>")
+		if item.Text != "" {
+			fmt.Fprintf(result, "<p>%s</p>\n", inline(item.Text, link))
+		}
+		writeBlocks(result, item.Blocks, link)
+		result.WriteString("</li>\n")
+	}
+
+	if block.Ordered {
+		result.WriteString("</ol>\n")
+	} else {
+		result.WriteString("</ul>\n")
+	}
+}
+
+// inline converts code spans, bold text and links, escaping everything else
+func inline(text string, link func(string) string) string {
+	var result strings.Builder
+	parts := strings.Split(text, "`")
+	for index, part := range parts {
+		escaped := html.EscapeString(part)
+		// Odd parts are code spans, unless the last backtick is unmatched
+		if index%2 == 1 && index < len(parts)-1 {
+			result.WriteString("<code>" + escaped + "</code>")
+			continue
+		}
+		if index%2 == 1 {
+			result.WriteString("`")
+		}
+		escaped = boldText.ReplaceAllString(escaped, "<strong>$1</strong>")
+		escaped = linkText.ReplaceAllStringFunc(escaped, func(match string) string {
+			groups := linkText.FindStringSubmatch(match)
+			target := html.EscapeString(link(html.UnescapeString(groups[2])))
+			return fmt.Sprintf("<a href=\"%s\">%s</a>", target, groups[1])
+		})
+		result.WriteString(escaped)
+	}
+	return result.String()
+}
+
+func nextNonBlank(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// dedent removes up to width leading spaces from a line
+func dedent(line string, width int) string {
+	if indent := indentation(line); indent < width {
+		width = indent
+	}
+	return line[width:]
+}
+
+// Headings returns the text of the second and deeper level headings of a
+// document, outside code blocks
+func Headings(source string) []string {
+	var headings []string
+	inCode := false
+	for _, line := range strings.Split(source, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if level := headingLevel(line); !inCode && level > 1 {
+			headings = append(headings, strings.TrimSpace(line[level:]))
+		}
+	}
+	return headings
+}
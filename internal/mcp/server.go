@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"elastic-integration-docs-mcp/internal/config"
 	"elastic-integration-docs-mcp/internal/services"
 	"elastic-integration-docs-mcp/internal/shared"
 )
@@ -17,31 +18,37 @@ type Server struct {
 	setupGuide    *services.SetupGuideProvider
 	documentation *services.DocumentationProvider
 	validation    *services.ValidationProvider
+	compatibility *services.CompatibilityProvider
+	integration   *services.IntegrationProvider
+	ingestion     *services.IngestionProvider
+	readme        *services.ReadmeProvider
+	diff          *services.DiffProvider
+	stale         *services.StaleProvider
 }
 
 func NewServer() *Server {
-	// Try to find config directory relative to the executable
-	configDir := "config"
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		// If not found, try relative to project root (go up one level from cmd/server)
-		configDir = "../config"
-		if _, err := os.Stat(configDir); os.IsNotExist(err) {
-			// If still not found, try absolute path from project root
-			configDir = "/Users/mwolf/git/docs-mcp/config"
-		}
-	}
+	configDir := config.FindConfigDir()
 
 	return &Server{
 		serviceInfo:   services.NewServiceInfoProvider(configDir),
 		setupGuide:    services.NewSetupGuideProvider(configDir),
 		documentation: services.NewDocumentationProvider(configDir),
 		validation:    services.NewValidationProvider(configDir),
+		compatibility: services.NewCompatibilityProvider(configDir),
+		integration:   services.NewIntegrationProvider(),
+		ingestion:     services.NewIngestionProvider(),
+		readme:        services.NewReadmeProvider(configDir),
+		diff:          services.NewDiffProvider(configDir),
+		stale:         services.NewStaleProvider(configDir),
 	}
 }
 
 func (s *Server) Run() error {
 	log.SetOutput(os.Stderr)
 	log.Println("Elastic Integration Docs MCP server running on stdio")
+	for _, err := range s.serviceInfo.LoadErrors() {
+		log.Printf("Skipped service: %v", err)
+	}
 
 	scanner := bufio.NewScanner(os.Stdin)
 
@@ -159,6 +166,10 @@ func (s *Server) handleListTools(request JSONRPCRequest) JSONRPCResponse {
 						"type":        "string",
 						"description": "Service version (optional)",
 					},
+					"platform": map[string]interface{}{
+						"type":        "string",
+						"description": "Target platform (optional): ubuntu, rhel, windows, macos, docker or kubernetes",
+					},
 				},
 				"required": []string{"service_name"},
 			},
@@ -175,7 +186,7 @@ func (s *Server) handleListTools(request JSONRPCRequest) JSONRPCResponse {
 					},
 					"input_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Input type (optional, e.g., tcp, udp)",
+						"description": "Input type (optional, e.g., aws-s3, httpjson, filestream, tcp). Use list_kibana_input_types to see the options for a service",
 					},
 					"version": map[string]interface{}{
 						"type":        "string",
@@ -185,9 +196,23 @@ func (s *Server) handleListTools(request JSONRPCRequest) JSONRPCResponse {
 				"required": []string{"service_name"},
 			},
 		},
+		{
+			Name:        "list_kibana_input_types",
+			Description: "List the input types that have Kibana setup instructions for the service",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
 		{
 			Name:        "get_troubleshooting_help",
-			Description: "Return a list of common problems and solutions for the service",
+			Description: "Return a list of common problems and solutions for the service, optionally ranked by how well they match an error message",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -195,10 +220,35 @@ func (s *Server) handleListTools(request JSONRPCRequest) JSONRPCResponse {
 						"type":        "string",
 						"description": "Name of the service",
 					},
+					"error_message": map[string]interface{}{
+						"type":        "string",
+						"description": "Error message or symptom to match against known issues (optional)",
+					},
 				},
 				"required": []string{"service_name"},
 			},
 		},
+		{
+			Name:        "troubleshoot_step",
+			Description: "Walk through a guided troubleshooting decision tree for the service. Start without session_id, then answer each question using the returned session_id until a final fix is reached",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service (required to start a session)",
+					},
+					"session_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Session ID returned by a previous troubleshoot_step call (optional)",
+					},
+					"answer": map[string]interface{}{
+						"type":        "string",
+						"description": "Answer to the current question, one of the listed answers (optional)",
+					},
+				},
+			},
+		},
 		{
 			Name:        "get_validation_steps",
 			Description: "Return a list of steps for how to validate that the integration is running properly",
@@ -213,6 +263,434 @@ func (s *Server) handleListTools(request JSONRPCRequest) JSONRPCResponse {
 				"required": []string{"service_name"},
 			},
 		},
+		{
+			Name:        "validate_configuration",
+			Description: "Validate a Fleet package policy or standalone agent policy for a service, checking required variables, types, known-bad values and data streams",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"configuration": map[string]interface{}{
+						"type":        "string",
+						"description": "Configuration content to validate",
+					},
+					"config_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Format of the configuration: yaml or json (optional, detected if omitted)",
+					},
+				},
+				"required": []string{"service_name", "configuration"},
+			},
+		},
+		{
+			Name:        "validate_service_config",
+			Description: "Validate a source service configuration file (nginx, Apache httpd, MySQL my.cnf, rsyslog or syslog-ng) for settings that stop the integration from collecting or parsing data",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"configuration": map[string]interface{}{
+						"type":        "string",
+						"description": "Contents of the service configuration file",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "Configuration format: nginx, apache, mysql, rsyslog or syslog-ng (optional, defaults to the service name)",
+					},
+				},
+				"required": []string{"service_name", "configuration"},
+			},
+		},
+		{
+			Name:        "check_data_ingestion",
+			Description: "Query Elasticsearch for an integration's data streams and report document counts, the last document timestamp, and error.message and ingest pipeline failure counts",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx, mysql, aws)",
+					},
+					"elasticsearch_url": map[string]interface{}{
+						"type":        "string",
+						"description": "Elasticsearch endpoint (e.g., https://localhost:9200)",
+					},
+					"api_key": map[string]interface{}{
+						"type":        "string",
+						"description": "Encoded Elasticsearch API key (optional)",
+					},
+					"username": map[string]interface{}{
+						"type":        "string",
+						"description": "Username for basic authentication (optional)",
+					},
+					"password": map[string]interface{}{
+						"type":        "string",
+						"description": "Password for basic authentication (optional)",
+					},
+					"data_streams": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Data streams to check (optional, all if omitted)",
+					},
+					"window": map[string]interface{}{
+						"type":        "string",
+						"description": "How far back documents count as recent, e.g. 15m, 1h or 1d (optional, defaults to 15m)",
+					},
+					"insecure_skip_verify": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip TLS certificate verification (optional)",
+					},
+				},
+				"required": []string{"integration", "elasticsearch_url"},
+			},
+		},
+		{
+			Name:        "get_data_stream_fields",
+			Description: "List the fields of an integration's data streams from its package field definitions, optionally filtered by name prefix and type",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx)",
+					},
+					"data_stream": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream name (optional, all data streams if omitted)",
+					},
+					"prefix": map[string]interface{}{
+						"type":        "string",
+						"description": "Only list fields whose name starts with this prefix (optional, e.g., nginx.access)",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"description": "Only list fields of this type (optional, e.g., keyword, ip, long)",
+					},
+				},
+				"required": []string{"integration"},
+			},
+		},
+		{
+			Name:        "lookup_field",
+			Description: "Resolve a field name to its ECS definition and the integration data streams that define it, and report whether it is an ECS field, integration-specific, or conflicting in type with ECS",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"field": map[string]interface{}{
+						"type":        "string",
+						"description": "Dotted field name (e.g., source.ip)",
+					},
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Search this integration's data streams, including packages from the package tree (optional; only the built-in example integrations are searched if omitted)",
+					},
+				},
+				"required": []string{"field"},
+			},
+		},
+		{
+			Name:        "get_ecs_mapping_report",
+			Description: "Report which fields of an integration's data streams are ECS fields, custom fields, or conflict in type with ECS",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx)",
+					},
+					"data_stream": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream name (optional, all data streams if omitted)",
+					},
+				},
+				"required": []string{"integration"},
+			},
+		},
+		{
+			Name:        "get_sample_event",
+			Description: "Get an example event for an integration's data streams, from the package's sample_event.json or synthesized from the field definitions",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx)",
+					},
+					"data_stream": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream name (optional, all data streams if omitted)",
+					},
+				},
+				"required": []string{"integration"},
+			},
+		},
+		{
+			Name:        "get_ingest_pipelines",
+			Description: "List the ingest pipeline processors of an integration's data streams, read from the package's elasticsearch/ingest_pipeline files",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx)",
+					},
+					"data_stream": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream name (optional, all data streams if omitted)",
+					},
+				},
+				"required": []string{"integration"},
+			},
+		},
+		{
+			Name:        "simulate_pipeline",
+			Description: "Run a sample log line through a data stream's ingest pipeline locally (grok, dissect, rename, set, convert, date, json, remove and pipeline processors) and show the resulting document and which processor failed",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx)",
+					},
+					"data_stream": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream name (e.g., access)",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "Sample log line, set as the message field",
+					},
+					"document": map[string]interface{}{
+						"type":        "string",
+						"description": "JSON object to use as the document instead of, or in addition to, message (optional)",
+					},
+					"pipeline": map[string]interface{}{
+						"type":        "string",
+						"description": "Pipeline name (optional, defaults to default)",
+					},
+				},
+				"required": []string{"integration", "data_stream"},
+			},
+		},
+		{
+			Name:        "test_log_parsing",
+			Description: "Test a grok or dissect pattern against sample log lines, returning the captured fields of each line or the position where matching failed. Grok uses the standard Elasticsearch pattern library.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"pattern": map[string]interface{}{
+						"type":        "string",
+						"description": "Grok expression (e.g., %{IP:source.ip} %{WORD:http.request.method}) or dissect pattern (e.g., %{source.ip} %{http.request.method})",
+					},
+					"lines": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Sample log lines",
+					},
+					"processor": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"grok", "dissect"},
+						"description": "Pattern type (optional, defaults to grok)",
+					},
+					"pattern_definitions": map[string]interface{}{
+						"type":        "object",
+						"description": "Custom grok patterns by name, as in the grok processor's pattern_definitions (optional)",
+					},
+					"append_separator": map[string]interface{}{
+						"type":        "string",
+						"description": "Separator for dissect append keys (optional, defaults to empty)",
+					},
+				},
+				"required": []string{"pattern", "lines"},
+			},
+		},
+		{
+			Name:        "render_readme",
+			Description: "Render a complete integration README from a service's configuration and the integration's data streams, fields and sample events",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service",
+					},
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Integration package for the data stream reference (optional, defaults to the service name)",
+					},
+					"template": map[string]interface{}{
+						"type":        "string",
+						"description": "Go text/template to render instead of the configured or built-in README template (optional)",
+					},
+				},
+				"required": []string{"service_name"},
+			},
+		},
+		{
+			Name:        "compare_service_versions",
+			Description: "Compare two versions of a service's configuration and report semantic changes: steps added, removed, reordered or changed, troubleshooting issues and compatibility ranges",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Service whose config/services file to compare between git revisions",
+					},
+					"from": map[string]interface{}{
+						"type":        "string",
+						"description": "Git revision of the old version (optional, defaults to HEAD)",
+					},
+					"to": map[string]interface{}{
+						"type":        "string",
+						"description": "Git revision of the new version (optional, defaults to the working tree file)",
+					},
+					"old_file": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of the old version under the config directory, to compare two files instead of git revisions",
+					},
+					"new_file": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of the new version under the config directory, to compare two files instead of git revisions",
+					},
+				},
+			},
+		},
+		{
+			Name:        "find_stale_content",
+			Description: "List setup steps, config snippets, troubleshooting issues and validation steps verified too long ago, or against an Elastic Stack version older than the service's supported range",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Service to check (optional, all services if omitted)",
+					},
+					"max_age": map[string]interface{}{
+						"type":        "string",
+						"description": "How long ago content may have been verified, e.g. 90d, 12w, 6m or 1y (optional, defaults to 1y)",
+					},
+					"include_unverified": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also list content without a verified_on date (optional)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "check_compatibility",
+			Description: "Check whether a service, or every service, supports a given Elastic Stack (Kibana) version",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"stack_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Elastic Stack version to check (e.g., 8.16.2)",
+					},
+					"service_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the service (optional, all services if omitted)",
+					},
+				},
+				"required": []string{"stack_version"},
+			},
+		},
+		{
+			Name:        "export_compatibility_matrix",
+			Description: "Export a matrix of which services support which Elastic Stack minor versions",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"stack_versions": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Stack minor versions to include as columns (optional, e.g., [\"8.18\", \"9.0\"])",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "Output format: markdown (default), csv or json",
+					},
+				},
+			},
+		},
+		{
+			Name:        "generate_policy",
+			Description: "Validate variable values and generate a Fleet package policy body and a standalone elastic-agent.yml inputs block for an integration",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx, mysql, aws)",
+					},
+					"policy_templates": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Policy templates to enable (optional, all if omitted)",
+					},
+					"data_streams": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Data streams to enable (optional, all streams of the selected policy templates if omitted)",
+					},
+					"vars": map[string]interface{}{
+						"type":        "object",
+						"description": "Variable values keyed by name, or by \"<input type>.<name>\" to target a single input",
+					},
+					"namespace": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream namespace (optional, defaults to default)",
+					},
+					"policy_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Agent policy ID to add the package policy to (optional)",
+					},
+				},
+				"required": []string{"integration"},
+			},
+		},
+		{
+			Name:        "render_agent_config",
+			Description: "Render a complete standalone elastic-agent.yml (outputs, inputs and streams) for an integration and chosen data streams",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"integration": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the Elastic integration (e.g., nginx, mysql, aws)",
+					},
+					"policy_templates": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Policy templates to enable (optional, all if omitted)",
+					},
+					"data_streams": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Data streams to enable (optional, all streams of the selected policy templates if omitted)",
+					},
+					"vars": map[string]interface{}{
+						"type":        "object",
+						"description": "Variable values keyed by name, or by \"<input type>.<name>\" to target a single input",
+					},
+					"namespace": map[string]interface{}{
+						"type":        "string",
+						"description": "Data stream namespace (optional, defaults to default)",
+					},
+					"elasticsearch_hosts": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Elasticsearch URLs for the default output (optional, defaults to https://localhost:9200)",
+					},
+				},
+				"required": []string{"integration"},
+			},
+		},
 	}
 
 	result := ListToolsResult{
@@ -271,7 +749,8 @@ func (s *Server) handleCallTool(request JSONRPCRequest) JSONRPCResponse {
 			break
 		}
 		version, _ := callRequest.Arguments["version"].(string)
-		result, err = s.setupGuide.GetServiceSetupInstructions(serviceName, version)
+		platform, _ := callRequest.Arguments["platform"].(string)
+		result, err = s.setupGuide.GetServiceSetupInstructions(serviceName, version, platform)
 
 	case "get_kibana_setup_instructions":
 		serviceName, ok := callRequest.Arguments["service_name"].(string)
@@ -283,13 +762,32 @@ func (s *Server) handleCallTool(request JSONRPCRequest) JSONRPCResponse {
 		version, _ := callRequest.Arguments["version"].(string)
 		result, err = s.setupGuide.GetKibanaSetupInstructions(serviceName, inputType, version)
 
+	case "list_kibana_input_types":
+		serviceName, ok := callRequest.Arguments["service_name"].(string)
+		if !ok {
+			err = fmt.Errorf("service_name is required")
+			break
+		}
+		result, err = s.setupGuide.ListKibanaInputTypes(serviceName)
+
 	case "get_troubleshooting_help":
 		serviceName, ok := callRequest.Arguments["service_name"].(string)
 		if !ok {
 			err = fmt.Errorf("service_name is required")
 			break
 		}
-		result, err = s.documentation.GetTroubleshootingHelp(serviceName)
+		errorMessage, _ := callRequest.Arguments["error_message"].(string)
+		result, err = s.documentation.GetTroubleshootingHelp(serviceName, errorMessage)
+
+	case "troubleshoot_step":
+		serviceName, _ := callRequest.Arguments["service_name"].(string)
+		sessionID, _ := callRequest.Arguments["session_id"].(string)
+		answer, _ := callRequest.Arguments["answer"].(string)
+		if serviceName == "" && sessionID == "" {
+			err = fmt.Errorf("service_name or session_id is required")
+			break
+		}
+		result, err = s.documentation.TroubleshootStep(serviceName, sessionID, answer)
 
 	case "get_validation_steps":
 		serviceName, ok := callRequest.Arguments["service_name"].(string)
@@ -299,6 +797,226 @@ func (s *Server) handleCallTool(request JSONRPCRequest) JSONRPCResponse {
 		}
 		result, err = s.validation.GetValidationSteps(serviceName)
 
+	case "validate_configuration":
+		serviceName, ok := callRequest.Arguments["service_name"].(string)
+		if !ok {
+			err = fmt.Errorf("service_name is required")
+			break
+		}
+		configuration, ok := callRequest.Arguments["configuration"].(string)
+		if !ok {
+			err = fmt.Errorf("configuration is required")
+			break
+		}
+		configType, _ := callRequest.Arguments["config_type"].(string)
+		result, err = s.validation.ValidateConfiguration(serviceName, configuration, configType)
+
+	case "validate_service_config":
+		serviceName, ok := callRequest.Arguments["service_name"].(string)
+		if !ok {
+			err = fmt.Errorf("service_name is required")
+			break
+		}
+		configuration, ok := callRequest.Arguments["configuration"].(string)
+		if !ok {
+			err = fmt.Errorf("configuration is required")
+			break
+		}
+		format, _ := callRequest.Arguments["format"].(string)
+		result, err = s.validation.ValidateServiceConfig(serviceName, format, configuration)
+
+	case "check_data_ingestion":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		elasticsearchURL, ok := callRequest.Arguments["elasticsearch_url"].(string)
+		if !ok {
+			err = fmt.Errorf("elasticsearch_url is required")
+			break
+		}
+		apiKey, _ := callRequest.Arguments["api_key"].(string)
+		username, _ := callRequest.Arguments["username"].(string)
+		password, _ := callRequest.Arguments["password"].(string)
+		window, _ := callRequest.Arguments["window"].(string)
+		insecure, _ := callRequest.Arguments["insecure_skip_verify"].(bool)
+		result, err = s.ingestion.CheckDataIngestion(integration,
+			stringSliceArgument(callRequest.Arguments["data_streams"]), window,
+			services.ElasticsearchConnection{
+				URL:                elasticsearchURL,
+				APIKey:             apiKey,
+				Username:           username,
+				Password:           password,
+				InsecureSkipVerify: insecure,
+			})
+
+	case "get_data_stream_fields":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		dataStream, _ := callRequest.Arguments["data_stream"].(string)
+		prefix, _ := callRequest.Arguments["prefix"].(string)
+		fieldType, _ := callRequest.Arguments["type"].(string)
+		result, err = s.integration.GetDataStreamFields(integration, dataStream, prefix, fieldType)
+
+	case "lookup_field":
+		field, ok := callRequest.Arguments["field"].(string)
+		if !ok {
+			err = fmt.Errorf("field is required")
+			break
+		}
+		integration, _ := callRequest.Arguments["integration"].(string)
+		result, err = s.integration.LookupField(field, integration)
+
+	case "get_ecs_mapping_report":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		dataStream, _ := callRequest.Arguments["data_stream"].(string)
+		result, err = s.integration.GetECSMappingReport(integration, dataStream)
+
+	case "get_sample_event":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		dataStream, _ := callRequest.Arguments["data_stream"].(string)
+		result, err = s.integration.GetSampleEvent(integration, dataStream)
+
+	case "get_ingest_pipelines":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		dataStream, _ := callRequest.Arguments["data_stream"].(string)
+		result, err = s.integration.GetIngestPipelines(integration, dataStream)
+
+	case "simulate_pipeline":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		dataStream, ok := callRequest.Arguments["data_stream"].(string)
+		if !ok {
+			err = fmt.Errorf("data_stream is required")
+			break
+		}
+		message, _ := callRequest.Arguments["message"].(string)
+		document, _ := callRequest.Arguments["document"].(string)
+		pipeline, _ := callRequest.Arguments["pipeline"].(string)
+		result, err = s.integration.SimulatePipeline(integration, dataStream, pipeline, message, document)
+
+	case "test_log_parsing":
+		pattern, ok := callRequest.Arguments["pattern"].(string)
+		if !ok {
+			err = fmt.Errorf("pattern is required")
+			break
+		}
+		processor, _ := callRequest.Arguments["processor"].(string)
+		appendSeparator, _ := callRequest.Arguments["append_separator"].(string)
+		definitions := make(map[string]string)
+		if custom, ok := callRequest.Arguments["pattern_definitions"].(map[string]interface{}); ok {
+			for name, definition := range custom {
+				definitions[name] = fmt.Sprint(definition)
+			}
+		}
+		result, err = s.validation.TestLogParsing(services.LogParsingRequest{
+			Processor:          processor,
+			Pattern:            pattern,
+			Lines:              stringSliceArgument(callRequest.Arguments["lines"]),
+			PatternDefinitions: definitions,
+			AppendSeparator:    appendSeparator,
+		})
+
+	case "render_readme":
+		serviceName, ok := callRequest.Arguments["service_name"].(string)
+		if !ok {
+			err = fmt.Errorf("service_name is required")
+			break
+		}
+		integration, _ := callRequest.Arguments["integration"].(string)
+		template, _ := callRequest.Arguments["template"].(string)
+		result, err = s.readme.RenderReadme(serviceName, integration, template)
+
+	case "compare_service_versions":
+		serviceName, _ := callRequest.Arguments["service_name"].(string)
+		from, _ := callRequest.Arguments["from"].(string)
+		to, _ := callRequest.Arguments["to"].(string)
+		oldFile, _ := callRequest.Arguments["old_file"].(string)
+		newFile, _ := callRequest.Arguments["new_file"].(string)
+		result, err = s.diff.CompareServiceVersions(services.DiffRequest{
+			ServiceName: serviceName,
+			From:        from,
+			To:          to,
+			OldFile:     oldFile,
+			NewFile:     newFile,
+		})
+
+	case "find_stale_content":
+		serviceName, _ := callRequest.Arguments["service_name"].(string)
+		maxAge, _ := callRequest.Arguments["max_age"].(string)
+		includeUnverified, _ := callRequest.Arguments["include_unverified"].(bool)
+		result, err = s.stale.FindStaleContent(serviceName, maxAge, includeUnverified)
+
+	case "check_compatibility":
+		stackVersion, ok := callRequest.Arguments["stack_version"].(string)
+		if !ok {
+			err = fmt.Errorf("stack_version is required")
+			break
+		}
+		serviceName, _ := callRequest.Arguments["service_name"].(string)
+		result, err = s.compatibility.CheckCompatibility(serviceName, stackVersion)
+
+	case "export_compatibility_matrix":
+		stackVersions := stringSliceArgument(callRequest.Arguments["stack_versions"])
+		format, _ := callRequest.Arguments["format"].(string)
+		result, err = s.compatibility.GetCompatibilityMatrix(stackVersions, format)
+
+	case "generate_policy":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		vars, _ := callRequest.Arguments["vars"].(map[string]interface{})
+		namespace, _ := callRequest.Arguments["namespace"].(string)
+		policyID, _ := callRequest.Arguments["policy_id"].(string)
+		result, err = s.integration.GeneratePolicy(services.PolicyRequest{
+			Integration:     integration,
+			PolicyTemplates: stringSliceArgument(callRequest.Arguments["policy_templates"]),
+			DataStreams:     stringSliceArgument(callRequest.Arguments["data_streams"]),
+			Vars:            vars,
+			Namespace:       namespace,
+			PolicyID:        policyID,
+		})
+
+	case "render_agent_config":
+		integration, ok := callRequest.Arguments["integration"].(string)
+		if !ok {
+			err = fmt.Errorf("integration is required")
+			break
+		}
+		vars, _ := callRequest.Arguments["vars"].(map[string]interface{})
+		namespace, _ := callRequest.Arguments["namespace"].(string)
+		result, err = s.integration.RenderAgentConfig(services.AgentConfigRequest{
+			PolicyRequest: services.PolicyRequest{
+				Integration:     integration,
+				PolicyTemplates: stringSliceArgument(callRequest.Arguments["policy_templates"]),
+				DataStreams:     stringSliceArgument(callRequest.Arguments["data_streams"]),
+				Vars:            vars,
+				Namespace:       namespace,
+			},
+			ElasticsearchHosts: stringSliceArgument(callRequest.Arguments["elasticsearch_hosts"]),
+		})
+
 	default:
 		err = fmt.Errorf("unknown tool: %s", callRequest.Name)
 	}
@@ -325,3 +1043,16 @@ func (s *Server) handleCallTool(request JSONRPCRequest) JSONRPCResponse {
 		Result:  result,
 	}
 }
+
+// stringSliceArgument converts a JSON array argument into a string slice,
+// skipping non-string elements
+func stringSliceArgument(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
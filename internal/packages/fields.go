@@ -0,0 +1,71 @@
+package packages
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Field represents a leaf field of a data stream with its full dotted name.
+// Fields declared with "external: ecs" have no type of their own until they
+// are resolved against the ECS definitions.
+type Field struct {
+	Name        string
+	Type        string
+	Description string
+	Example     string
+	External    string
+	File        string
+}
+
+// fieldDefinition is an entry of a fields/*.yml file. Groups nest their
+// children's names under their own.
+type fieldDefinition struct {
+	Name        string            `yaml:"name"`
+	Type        string            `yaml:"type"`
+	Description string            `yaml:"description"`
+	Example     interface{}       `yaml:"example"`
+	External    string            `yaml:"external"`
+	Fields      []fieldDefinition `yaml:"fields"`
+}
+
+func loadFieldsFile(path string) ([]Field, error) {
+	var definitions []fieldDefinition
+	if err := readYAML(path, &definitions); err != nil {
+		return nil, err
+	}
+	return flattenFields(definitions, "", filepath.Base(path)), nil
+}
+
+// flattenFields expands groups into leaf fields with dotted names. Nested
+// fields keep their own entry as well as their children's.
+func flattenFields(definitions []fieldDefinition, prefix, file string) []Field {
+	var fields []Field
+	for _, definition := range definitions {
+		name := definition.Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if definition.Type == "group" || (definition.Type == "" && len(definition.Fields) > 0) {
+			fields = append(fields, flattenFields(definition.Fields, name, file)...)
+			continue
+		}
+
+		field := Field{
+			Name:        name,
+			Type:        definition.Type,
+			Description: definition.Description,
+			External:    definition.External,
+			File:        file,
+		}
+		if definition.Example != nil {
+			field.Example = fmt.Sprint(definition.Example)
+		}
+		fields = append(fields, field)
+
+		if definition.Type == "nested" || definition.Type == "object" {
+			fields = append(fields, flattenFields(definition.Fields, name, file)...)
+		}
+	}
+	return fields
+}
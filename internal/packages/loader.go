@@ -0,0 +1,230 @@
+package packages
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"elastic-integration-docs-mcp/internal/ingest"
+)
+
+// PackagesDirEnv names the environment variable pointing at a local checkout
+// of the elastic/integrations repository or its packages directory
+const PackagesDirEnv = "INTEGRATIONS_PACKAGES_DIR"
+
+// Package represents an integration package read from a package tree
+type Package struct {
+	Name        string       `yaml:"name"`
+	Title       string       `yaml:"title"`
+	Version     string       `yaml:"version"`
+	Description string       `yaml:"description"`
+	Categories  []string     `yaml:"categories"`
+	Dir         string       `yaml:"-"`
+	DataStreams []DataStream `yaml:"-"`
+	// PolicyTemplates are the policy templates of the manifest with their inputs and input variables
+	PolicyTemplates []PolicyTemplate `yaml:"policy_templates"`
+}
+
+// PolicyTemplate represents a policy template of a package manifest. A
+// template without data streams applies to all of the package's data streams.
+type PolicyTemplate struct {
+	Name        string   `yaml:"name"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Categories  []string `yaml:"categories"`
+	DataStreams []string `yaml:"data_streams"`
+	Inputs      []Input  `yaml:"inputs"`
+}
+
+// Input represents an input of a policy template
+type Input struct {
+	Type        string     `yaml:"type"`
+	Title       string     `yaml:"title"`
+	Description string     `yaml:"description"`
+	Vars        []Variable `yaml:"vars"`
+}
+
+// Variable represents a variable definition of a package manifest
+type Variable struct {
+	Name        string      `yaml:"name"`
+	Type        string      `yaml:"type"`
+	Title       string      `yaml:"title"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Multi       bool        `yaml:"multi"`
+	Secret      bool        `yaml:"secret"`
+	Default     interface{} `yaml:"default"`
+}
+
+// DataStream represents a data stream of a package
+type DataStream struct {
+	Name   string  `yaml:"-"`
+	Title  string  `yaml:"title"`
+	Type   string  `yaml:"type"`
+	Dir    string  `yaml:"-"`
+	Fields []Field `yaml:"-"`
+	// SampleEvent is the content of sample_event.json, if the data stream has one
+	SampleEvent []byte `yaml:"-"`
+	// Pipelines are the ingest pipelines of the data stream; "default" is the entry point
+	Pipelines []*ingest.Pipeline `yaml:"-"`
+}
+
+// Loader reads integration packages from a package tree and caches them
+type Loader struct {
+	root     string
+	mu       sync.Mutex
+	packages map[string]*Package
+}
+
+// NewLoader creates a loader for a package tree. The root may be an
+// integrations repository checkout or its packages directory.
+func NewLoader(root string) *Loader {
+	if root != "" {
+		if info, err := os.Stat(filepath.Join(root, "packages")); err == nil && info.IsDir() {
+			root = filepath.Join(root, "packages")
+		}
+	}
+	return &Loader{
+		root:     root,
+		packages: make(map[string]*Package),
+	}
+}
+
+// ErrPackageNotFound is wrapped by LoadPackage errors for packages that are
+// not in the tree, as opposed to packages that fail to load
+var ErrPackageNotFound = errors.New("package not found")
+
+// NewLoaderFromEnv creates a loader for the tree named by INTEGRATIONS_PACKAGES_DIR
+func NewLoaderFromEnv() *Loader {
+	return NewLoader(os.Getenv(PackagesDirEnv))
+}
+
+// Configured reports whether the loader has a package tree to read from
+func (l *Loader) Configured() bool {
+	return l.root != ""
+}
+
+// LoadPackage reads a package's manifest and data streams, including their
+// field definitions. Results are cached. The name must be a single directory
+// name, so that it cannot reach outside the package tree.
+func (l *Loader) LoadPackage(name string) (*Package, error) {
+	if !l.Configured() {
+		return nil, fmt.Errorf("no integrations package tree configured; set %s", PackagesDirEnv)
+	}
+	if name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid package name '%s'", name)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if pkg, exists := l.packages[name]; exists {
+		return pkg, nil
+	}
+
+	dir := filepath.Join(l.root, name)
+	var pkg Package
+	if err := readYAML(filepath.Join(dir, "manifest.yml"), &pkg); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: '%s' is not in %s", ErrPackageNotFound, name, l.root)
+		}
+		return nil, fmt.Errorf("failed to load package %s: %v", name, err)
+	}
+	pkg.Dir = dir
+	if pkg.Name == "" {
+		pkg.Name = name
+	}
+
+	dataStreamDirs, err := ioutil.ReadDir(filepath.Join(dir, "data_stream"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read data streams of package %s: %v", name, err)
+	}
+	for _, entry := range dataStreamDirs {
+		if !entry.IsDir() {
+			continue
+		}
+		dataStream, err := loadDataStream(filepath.Join(dir, "data_stream", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", name, err)
+		}
+		pkg.DataStreams = append(pkg.DataStreams, *dataStream)
+	}
+
+	l.packages[name] = &pkg
+	return &pkg, nil
+}
+
+// DataStream returns a data stream of the package by name
+func (p *Package) DataStream(name string) (*DataStream, bool) {
+	for i := range p.DataStreams {
+		if p.DataStreams[i].Name == name {
+			return &p.DataStreams[i], true
+		}
+	}
+	return nil, false
+}
+
+// DataStreamNames returns the names of the package's data streams
+func (p *Package) DataStreamNames() []string {
+	names := make([]string, 0, len(p.DataStreams))
+	for _, dataStream := range p.DataStreams {
+		names = append(names, dataStream.Name)
+	}
+	return names
+}
+
+func loadDataStream(dir string) (*DataStream, error) {
+	dataStream := DataStream{Name: filepath.Base(dir), Dir: dir}
+	if err := readYAML(filepath.Join(dir, "manifest.yml"), &dataStream); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("data stream %s: %v", dataStream.Name, err)
+	}
+	if dataStream.Type == "" {
+		dataStream.Type = "logs"
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "fields", "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		fields, err := loadFieldsFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("data stream %s: %v", dataStream.Name, err)
+		}
+		dataStream.Fields = append(dataStream.Fields, fields...)
+	}
+	sort.Slice(dataStream.Fields, func(i, j int) bool {
+		return dataStream.Fields[i].Name < dataStream.Fields[j].Name
+	})
+
+	sampleEvent, err := ioutil.ReadFile(filepath.Join(dir, "sample_event.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("data stream %s: %v", dataStream.Name, err)
+	}
+	dataStream.SampleEvent = sampleEvent
+
+	if dataStream.Pipelines, err = ingest.LoadPipelines(dir); err != nil {
+		return nil, fmt.Errorf("data stream %s: %v", dataStream.Name, err)
+	}
+	return &dataStream, nil
+}
+
+func readYAML(path string, value interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package packages
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writePackageTree writes files, keyed by slash-separated path, under root
+func writePackageTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const nginxFields = `- name: nginx.access
+  type: group
+  fields:
+    - name: remote_ip_list
+      type: array
+      description: Remote IP addresses
+    - name: upstream
+      fields:
+        - name: status
+          type: long
+- name: http.request.headers
+  type: nested
+  fields:
+    - name: name
+      type: keyword
+- name: source.ip
+  external: ecs
+- name: event.duration
+  type: long
+  example: 1500
+`
+
+func TestLoadPackage(t *testing.T) {
+	root := t.TempDir()
+	writePackageTree(t, root, map[string]string{
+		"packages/nginx/manifest.yml":                              "name: nginx\ntitle: Nginx\nversion: 1.20.0\n",
+		"packages/nginx/data_stream/access/manifest.yml":           "title: Access logs\ntype: logs\n",
+		"packages/nginx/data_stream/access/fields/fields.yml":      nginxFields,
+		"packages/nginx/data_stream/access/fields/base-fields.yml": "- name: '@timestamp'\n  type: date\n",
+		"packages/nginx/data_stream/access/fields/README.md":       "not a fields file",
+		"packages/nginx/data_stream/access/sample_event.json":      `{"message": "GET /"}`,
+		"packages/nginx/data_stream/stubstatus/manifest.yml":       "title: Stub status\ntype: metrics\n",
+		"packages/nginx/data_stream/error/fields/ecs.yml":          "- name: log.level\n  external: ecs\n",
+		"packages/unnamed/manifest.yml":                            "title: Unnamed\n",
+	})
+	// The loader accepts a repository checkout and uses its packages directory
+	loader := NewLoader(root)
+
+	pkg, err := loader.LoadPackage("Nginx")
+	if err != nil {
+		t.Fatalf("LoadPackage: %v", err)
+	}
+	if pkg.Name != "nginx" || pkg.Version != "1.20.0" || pkg.Dir != filepath.Join(root, "packages", "nginx") {
+		t.Errorf("package = %s %s in %s", pkg.Name, pkg.Version, pkg.Dir)
+	}
+	if got, want := pkg.DataStreamNames(), []string{"access", "error", "stubstatus"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DataStreamNames = %q, want %q", got, want)
+	}
+
+	access, _ := pkg.DataStream("access")
+	var fields []string
+	for _, field := range access.Fields {
+		fields = append(fields, fmt.Sprintf("%s %s %s %s %s", field.Name, field.Type, field.External, field.Example, field.File))
+	}
+	wantFields := []string{
+		"@timestamp date   base-fields.yml",
+		"event.duration long  1500 fields.yml",
+		"http.request.headers nested   fields.yml",
+		"http.request.headers.name keyword   fields.yml",
+		"nginx.access.remote_ip_list array   fields.yml",
+		"nginx.access.upstream.status long   fields.yml",
+		"source.ip  ecs  fields.yml",
+	}
+	if !reflect.DeepEqual(fields, wantFields) {
+		t.Errorf("access fields =\n%s\nwant\n%s", strings.Join(fields, "\n"), strings.Join(wantFields, "\n"))
+	}
+	if string(access.SampleEvent) != `{"message": "GET /"}` {
+		t.Errorf("access sample event = %q", access.SampleEvent)
+	}
+
+	errorStream, _ := pkg.DataStream("error")
+	if errorStream.Type != "logs" || errorStream.SampleEvent != nil {
+		t.Errorf("data stream without a manifest has type %q and sample event %q, want logs and none", errorStream.Type, errorStream.SampleEvent)
+	}
+	if stubstatus, _ := pkg.DataStream("stubstatus"); stubstatus.Type != "metrics" || len(stubstatus.Fields) != 0 {
+		t.Errorf("stubstatus has type %q and %d fields, want metrics and none", stubstatus.Type, len(stubstatus.Fields))
+	}
+
+	if cached, _ := loader.LoadPackage("nginx"); cached != pkg {
+		t.Errorf("LoadPackage did not return the cached package")
+	}
+	if unnamed, err := loader.LoadPackage("unnamed"); err != nil || unnamed.Name != "unnamed" {
+		t.Errorf("LoadPackage(unnamed) = %v, %v, want the directory name as package name", unnamed, err)
+	}
+}
+
+func TestLoadPackageErrors(t *testing.T) {
+	root := t.TempDir()
+	writePackageTree(t, root, map[string]string{
+		"broken/manifest.yml":                            "name: broken\ntitle: [\n",
+		"badfields/manifest.yml":                         "name: badfields\n",
+		"badfields/data_stream/events/fields/fields.yml": "- name: [\n",
+		"outside/manifest.yml":                           "name: outside\n",
+	})
+	loader := NewLoader(root)
+
+	tests := []struct {
+		name     string
+		err      string
+		notFound bool
+	}{
+		{name: "missing", err: "package not found: 'missing' is not in " + root, notFound: true},
+		{name: "broken", err: "failed to load package broken: failed to parse"},
+		{name: "badfields", err: "package badfields: data stream events: failed to parse"},
+		{name: "", err: "invalid package name ''"},
+		{name: ".", err: "invalid package name '.'"},
+		{name: "../outside", err: "invalid package name '../outside'"},
+		{name: "nginx/data_stream", err: "invalid package name 'nginx/data_stream'"},
+		{name: `nginx\access`, err: `invalid package name 'nginx\access'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loader.LoadPackage(test.name)
+			if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+				t.Errorf("LoadPackage error = %v, want it to start with %q", err, test.err)
+			}
+			if notFound := errors.Is(err, ErrPackageNotFound); notFound != test.notFound {
+				t.Errorf("errors.Is(err, ErrPackageNotFound) = %v, want %v", notFound, test.notFound)
+			}
+		})
+	}
+
+	if _, err := NewLoader("").LoadPackage("nginx"); err == nil || !strings.Contains(err.Error(), PackagesDirEnv) {
+		t.Errorf("LoadPackage without a tree error = %v, want it to name %s", err, PackagesDirEnv)
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// defaultElasticsearchHost is used when no Elasticsearch hosts are given
+const defaultElasticsearchHost = "https://localhost:9200"
+
+// standaloneStreamNote says that standalone streams are not rendered through
+// the package's stream templates, which Fleet uses to build the real settings
+const standaloneStreamNote = "**Approximation**: stream settings are written under the package's variable names. Fleet renders each stream through the package's `agent/stream/*.yml.hbs` template, which can rename, nest or leave out settings, so check the streams against those templates or a Fleet-generated policy before deploying."
+
+// standaloneStreamComment carries the same warning inside the rendered YAML,
+// so that it stays with the file once copied
+const standaloneStreamComment = "# Approximation: stream settings use the package's variable names, not its\n# agent/stream templates. Check them against those templates or a\n# Fleet-generated policy before deploying.\n"
+
+// AgentConfigRequest describes the standalone agent configuration to render
+type AgentConfigRequest struct {
+	PolicyRequest
+	ElasticsearchHosts []string
+}
+
+// agentConfig is a standalone elastic-agent.yml
+type agentConfig struct {
+	Outputs map[string]agentOutput `yaml:"outputs"`
+	Agent   agentSettings          `yaml:"agent"`
+	Inputs  []standaloneInput      `yaml:"inputs"`
+}
+
+type agentOutput struct {
+	Type   string   `yaml:"type"`
+	Hosts  []string `yaml:"hosts"`
+	APIKey string   `yaml:"api_key"`
+}
+
+type agentSettings struct {
+	Monitoring agentMonitoring `yaml:"monitoring"`
+}
+
+type agentMonitoring struct {
+	Enabled   bool   `yaml:"enabled"`
+	UseOutput string `yaml:"use_output"`
+	Logs      bool   `yaml:"logs"`
+	Metrics   bool   `yaml:"metrics"`
+}
+
+// RenderAgentConfig assembles a complete standalone elastic-agent.yml for an
+// integration. Secret variables are written as ${env.NAME} references so that
+// credentials stay out of the file. Variables become stream settings as they
+// are named in the package, without the package's stream templates, so the
+// output is labelled as an approximation.
+func (i *IntegrationProvider) RenderAgentConfig(request AgentConfigRequest) (shared.CallToolResult, error) {
+	plan, err := i.planPolicy(request.PolicyRequest)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	if len(plan.errors) > 0 {
+		return errorResult(fmt.Sprintf("# %s Agent Configuration Validation Failed\n\n## Errors\n%s%s",
+			plan.integration.Title, formatList(plan.errors), formatWarnings(plan.warnings))), nil
+	}
+
+	hosts := request.ElasticsearchHosts
+	if len(hosts) == 0 {
+		hosts = []string{defaultElasticsearchHost}
+	}
+
+	envVars := []string{"ES_API_KEY"}
+	secretRef := func(name string) interface{} {
+		envVar := strings.ToUpper(plan.integration.Name + "_" + name)
+		if !containsString(envVars, envVar) {
+			envVars = append(envVars, envVar)
+		}
+		return "${env." + envVar + "}"
+	}
+
+	config := agentConfig{
+		Outputs: map[string]agentOutput{
+			"default": {
+				Type:   "elasticsearch",
+				Hosts:  hosts,
+				APIKey: "${env.ES_API_KEY}",
+			},
+		},
+		Agent: agentSettings{
+			Monitoring: agentMonitoring{Enabled: true, UseOutput: "default", Logs: true, Metrics: true},
+		},
+		Inputs: plan.standaloneInputs(secretRef),
+	}
+
+	rendered, err := formatYAML(config)
+	if err != nil {
+		return shared.CallToolResult{}, fmt.Errorf("failed to format agent configuration: %v", err)
+	}
+	if err := checkAgentConfig(rendered, config); err != nil {
+		return shared.CallToolResult{}, fmt.Errorf("rendered agent configuration does not round-trip: %v", err)
+	}
+
+	return textResult(fmt.Sprintf("# %s Standalone Agent Configuration\n\n%s\n%s\n## Environment Variables\nSet these before starting Elastic Agent:\n%s\n## elastic-agent.yml\n```yaml\n%s%s```\n",
+		plan.integration.Title, standaloneStreamNote, formatWarnings(plan.warnings), formatList(envVars), standaloneStreamComment, rendered)), nil
+}
+
+// checkAgentConfig parses rendered YAML back and verifies that the outputs,
+// inputs and stream datasets survived the round trip
+func checkAgentConfig(rendered string, expected agentConfig) error {
+	var parsed agentConfig
+	if err := yaml.Unmarshal([]byte(rendered), &parsed); err != nil {
+		return err
+	}
+
+	if _, exists := parsed.Outputs["default"]; !exists {
+		return fmt.Errorf("default output is missing")
+	}
+	if len(parsed.Inputs) != len(expected.Inputs) {
+		return fmt.Errorf("expected %d inputs, found %d", len(expected.Inputs), len(parsed.Inputs))
+	}
+	for index, input := range parsed.Inputs {
+		want := expected.Inputs[index]
+		if input.ID != want.ID || input.Type != want.Type {
+			return fmt.Errorf("input %d is %s (%s), expected %s (%s)", index, input.ID, input.Type, want.ID, want.Type)
+		}
+		if len(input.Streams) != len(want.Streams) {
+			return fmt.Errorf("input %s has %d streams, expected %d", input.ID, len(input.Streams), len(want.Streams))
+		}
+		for streamIndex, stream := range input.Streams {
+			if stream.DataStream.Dataset != want.Streams[streamIndex].DataStream.Dataset {
+				return fmt.Errorf("stream %s has dataset '%s', expected '%s'", stream.ID,
+					stream.DataStream.Dataset, want.Streams[streamIndex].DataStream.Dataset)
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
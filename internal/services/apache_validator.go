@@ -0,0 +1,270 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// apacheAccessFields are the format directives the Apache integration's
+// access log pipeline needs, with the alternatives it also accepts
+var apacheAccessFields = [][]string{{"%h", "%a"}, {"%t"}, {"%r"}, {"%>s", "%s"}, {"%b", "%B"}}
+
+// apacheOptionalFields populate referrer and user agent fields when present
+var apacheOptionalFields = []string{"%{referer}i", "%{user-agent}i"}
+
+// apacheFormatDirective matches one format directive of a LogFormat string,
+// such as %h, %>s, %400,501{User-agent}i or %^ti, or the literal %%
+var apacheFormatDirective = regexp.MustCompile(`%(?:%|!?(?:\d{3}(?:,\d{3})*)?([<>]?)(\{[^}]*\})?(\^t[io]|[a-zA-Z]))`)
+
+// apacheStandardFormats are the nicknames defined by the stock httpd.conf or apache2.conf
+var apacheStandardFormats = []string{"combined", "common", "vhost_combined"}
+
+// apacheDirective is a directive from httpd.conf with the sections enclosing it
+type apacheDirective struct {
+	name    string
+	args    []string
+	line    int
+	context []string
+}
+
+type apacheValidator struct{}
+
+func (apacheValidator) Name() string {
+	return "apache"
+}
+
+func (apacheValidator) Description() string {
+	return "Apache httpd.conf, apache2.conf and virtual host files"
+}
+
+func (apacheValidator) Validate(content string) shared.ValidationResult {
+	f := newFindings()
+	directives := parseApacheConfig(content, f)
+
+	formats := make(map[string]string)
+	var customLogs []apacheDirective
+	hasAccessLog, hasErrorLog, hasStatusHandler := false, false, false
+	for _, directive := range directives {
+		switch strings.ToLower(directive.name) {
+		case "logformat":
+			if len(directive.args) == 0 {
+				f.addError(directive.line, "syntax", "LogFormat needs a format string")
+				continue
+			}
+			nickname := ""
+			if len(directive.args) > 1 {
+				nickname = directive.args[1]
+			}
+			formats[nickname] = directive.args[0]
+		case "customlog":
+			hasAccessLog = true
+			customLogs = append(customLogs, directive)
+		case "transferlog":
+			hasAccessLog = true
+			if len(directive.args) != 1 {
+				f.addError(directive.line, "syntax", fmt.Sprintf("%s takes one argument, a file or pipe", directive.name))
+				continue
+			}
+			// TransferLog uses the last nickless LogFormat, recorded under ""
+			customLogs = append(customLogs, apacheDirective{name: directive.name, args: []string{directive.args[0], ""}, line: directive.line})
+		case "errorlog":
+			hasErrorLog = true
+			if len(directive.args) > 0 && strings.HasPrefix(directive.args[0], "syslog") {
+				f.addWarning(directive.line, "error_log", "Error logs are sent to syslog instead of a file",
+					"Write the error log to a file so the error data stream can read it")
+			}
+		case "loglevel":
+			for _, arg := range directive.args {
+				level := arg[strings.LastIndex(arg, ":")+1:]
+				if level == "debug" || strings.HasPrefix(level, "trace") {
+					f.addSuggestion(directive.line, "log_level", fmt.Sprintf("LogLevel is set to %s", arg),
+						"Use warn or error in production", "Debug and trace logging produce a high volume of error log events")
+				}
+			}
+		case "sethandler":
+			if len(directive.args) > 0 && strings.EqualFold(directive.args[0], "server-status") {
+				hasStatusHandler = true
+			}
+		case "extendedstatus":
+			if len(directive.args) > 0 && strings.EqualFold(directive.args[0], "off") {
+				f.addSuggestion(directive.line, "metrics", "ExtendedStatus is Off",
+					"Set ExtendedStatus On", "The status data stream loses per-request and traffic metrics")
+			}
+		}
+	}
+
+	if !hasErrorLog {
+		f.addSuggestion(0, "error_log", "No ErrorLog directive found",
+			"Set ErrorLog explicitly so the integration's paths match", "The error data stream collects nothing if the paths differ")
+	}
+	if !hasAccessLog {
+		f.addWarning(0, "access_log", "No CustomLog or TransferLog directive found; requests are not logged",
+			"Add 'CustomLog logs/access_log combined'")
+	}
+
+	for _, directive := range customLogs {
+		if len(directive.args) < 2 {
+			f.addError(directive.line, "syntax", fmt.Sprintf("%s needs a file or pipe and a format or nickname", directive.name))
+			continue
+		}
+
+		format, name := directive.args[1], directive.args[1]
+		if !strings.Contains(format, "%") {
+			defined, exists := formats[name]
+			switch {
+			case exists:
+				format = defined
+			case name == "" || containsString(apacheStandardFormats, name):
+				// TransferLog without a nickless LogFormat uses the common format
+				continue
+			default:
+				f.addError(directive.line, "log_format", fmt.Sprintf("%s uses log format '%s', which is not defined by LogFormat", directive.name, name))
+				continue
+			}
+		} else {
+			name = "inline format"
+		}
+
+		directives := apacheFormatDirectives(format)
+		var missing []string
+		for _, alternatives := range apacheAccessFields {
+			if len(missingDirectives(directives, alternatives)) == len(alternatives) {
+				missing = append(missing, alternatives[0])
+			}
+		}
+		if len(missing) > 0 {
+			f.addError(directive.line, "log_format", fmt.Sprintf("Log format '%s' is missing %s, which the Apache access pipeline expects",
+				name, strings.Join(missing, ", ")))
+		} else if missing := missingDirectives(directives, apacheOptionalFields); len(missing) > 0 {
+			f.addWarning(directive.line, "log_format", fmt.Sprintf("Log format '%s' does not include %s", name, strings.Join(missing, ", ")),
+				"Use the combined format to populate referrer and user agent fields")
+		}
+	}
+
+	if !hasStatusHandler {
+		f.addSuggestion(0, "metrics", "No server-status handler is configured",
+			"Enable mod_status and add '<Location /server-status> SetHandler server-status Require local </Location>'",
+			"The status metrics data stream needs the server-status endpoint")
+	}
+
+	return f.result
+}
+
+// apacheFormatDirectives returns the set of format directives in a LogFormat
+// string. Status code conditions are dropped and header names are lowercased,
+// so "%!200{User-Agent}i" is recorded as "%{user-agent}i".
+func apacheFormatDirectives(format string) map[string]bool {
+	directives := make(map[string]bool)
+	for _, match := range apacheFormatDirective.FindAllStringSubmatch(format, -1) {
+		if match[0] == "%%" {
+			continue
+		}
+		directives["%"+match[1]+strings.ToLower(match[2])+match[3]] = true
+	}
+	return directives
+}
+
+// missingDirectives returns the expected directives that a format does not use
+func missingDirectives(directives map[string]bool, expected []string) []string {
+	var missing []string
+	for _, directive := range expected {
+		if !directives[directive] {
+			missing = append(missing, directive)
+		}
+	}
+	return missing
+}
+
+// parseApacheConfig splits Apache configuration into directives, joining
+// continuation lines and recording unbalanced sections as errors
+func parseApacheConfig(content string, f *findings) []apacheDirective {
+	var directives []apacheDirective
+	var context []string
+	var openLines []int
+
+	lines := strings.Split(content, "\n")
+	for index := 0; index < len(lines); index++ {
+		lineNumber := index + 1
+		text := strings.TrimSpace(lines[index])
+		for strings.HasSuffix(text, "\\") && index+1 < len(lines) {
+			index++
+			text = strings.TrimSuffix(text, "\\") + " " + strings.TrimSpace(lines[index])
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "</") {
+			name := strings.TrimSuffix(strings.TrimPrefix(text, "</"), ">")
+			if len(context) == 0 || !strings.EqualFold(context[len(context)-1], name) {
+				f.addError(lineNumber, "syntax", fmt.Sprintf("Unexpected closing section </%s>", name))
+				continue
+			}
+			context = context[:len(context)-1]
+			openLines = openLines[:len(openLines)-1]
+			continue
+		}
+
+		if strings.HasPrefix(text, "<") {
+			if !strings.HasSuffix(text, ">") {
+				f.addError(lineNumber, "syntax", "Section is missing a closing '>'")
+				continue
+			}
+			fields := splitConfigArgs(strings.TrimSuffix(strings.TrimPrefix(text, "<"), ">"))
+			if len(fields) == 0 {
+				f.addError(lineNumber, "syntax", "Section has no name")
+				continue
+			}
+			directives = append(directives, apacheDirective{name: "<" + fields[0], args: fields[1:], line: lineNumber, context: append([]string(nil), context...)})
+			context = append(context, fields[0])
+			openLines = append(openLines, lineNumber)
+			continue
+		}
+
+		fields := splitConfigArgs(text)
+		directives = append(directives, apacheDirective{name: fields[0], args: fields[1:], line: lineNumber, context: append([]string(nil), context...)})
+	}
+
+	for index := range context {
+		f.addError(openLines[index], "syntax", fmt.Sprintf("Section <%s> is never closed", context[index]))
+	}
+	return directives
+}
+
+// splitConfigArgs splits a directive into whitespace separated arguments,
+// keeping double-quoted arguments together and unescaping \"
+func splitConfigArgs(text string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' && inQuotes && i+1 < len(text):
+			i++
+			if text[i] != '"' {
+				current.WriteByte('\\')
+			}
+			current.WriteByte(text[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteByte(c)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// lineFindings formats a validator's errors and warnings as "line type: message"
+func lineFindings(result shared.ValidationResult) ([]string, []string) {
+	format := func(line *int, findingType, message string) string {
+		if line == nil {
+			return fmt.Sprintf("- %s: %s", findingType, message)
+		}
+		return fmt.Sprintf("%d %s: %s", *line, findingType, message)
+	}
+	var errors, warnings []string
+	for _, finding := range result.Errors {
+		errors = append(errors, format(finding.Line, finding.Type, finding.Message))
+	}
+	for _, finding := range result.Warnings {
+		warnings = append(warnings, format(finding.Line, finding.Type, finding.Message))
+	}
+	return errors, warnings
+}
+
+func TestApacheValidatorAccessLogs(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:   "combined nickname",
+			config: "ErrorLog logs/error_log\nCustomLog logs/access_log combined\n",
+		},
+		{
+			name:   "TransferLog without arguments",
+			config: "ErrorLog logs/error_log\nTransferLog\n",
+			errors: []string{"2 syntax: TransferLog takes one argument, a file or pipe"},
+		},
+		{
+			name:   "TransferLog with a format",
+			config: "ErrorLog logs/error_log\nTransferLog logs/access_log combined\n",
+			errors: []string{"2 syntax: TransferLog takes one argument, a file or pipe"},
+		},
+		{
+			name:   "CustomLog without a format",
+			config: "ErrorLog logs/error_log\nCustomLog logs/access_log\n",
+			errors: []string{"2 syntax: CustomLog needs a file or pipe and a format or nickname"},
+		},
+		{
+			name:   "TransferLog with the default format",
+			config: "ErrorLog logs/error_log\nTransferLog logs/access_log\n",
+		},
+		{
+			name:   "TransferLog with a nickless LogFormat",
+			config: "ErrorLog logs/error_log\nLogFormat \"%h %t %>s %b\"\nTransferLog logs/access_log\n",
+			errors: []string{"3 log_format: Log format '' is missing %r, which the Apache access pipeline expects"},
+		},
+		{
+			name:   "undefined nickname",
+			config: "ErrorLog logs/error_log\nCustomLog logs/access_log detailed\n",
+			errors: []string{"2 log_format: CustomLog uses log format 'detailed', which is not defined by LogFormat"},
+		},
+		{
+			name:   "literal percent is not a directive",
+			config: "ErrorLog logs/error_log\nCustomLog logs/access_log \"%%h %t \\\"%r\\\" %>s %b\"\n",
+			errors: []string{"2 log_format: Log format 'inline format' is missing %h, which the Apache access pipeline expects"},
+		},
+		{
+			name:   "parameterized directive is not the plain directive",
+			config: "ErrorLog logs/error_log\nLogFormat \"%{c}h %t \\\"%r\\\" %>s %{X-Bytes}b\" proxied\nCustomLog logs/access_log proxied\n",
+			errors: []string{"3 log_format: Log format 'proxied' is missing %h, %b, which the Apache access pipeline expects"},
+		},
+		{
+			name:   "alternatives and conditions",
+			config: "ErrorLog logs/error_log\nLogFormat \"%a %t \\\"%r\\\" %s %B \\\"%{Referer}i\\\" \\\"%!200,304{User-Agent}i\\\"\" custom\nCustomLog logs/access_log custom\n",
+		},
+		{
+			name:     "no user agent",
+			config:   "ErrorLog logs/error_log\nCustomLog logs/access_log \"%h %t \\\"%r\\\" %>s %b\"\n",
+			warnings: []string{"2 log_format: Log format 'inline format' does not include %{referer}i, %{user-agent}i"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errors, warnings := lineFindings(apacheValidator{}.Validate(test.config))
+			if !reflect.DeepEqual(errors, test.errors) {
+				t.Errorf("errors = %q, want %q", errors, test.errors)
+			}
+			if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestApacheFormatDirectives(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: `%h %l %u %t "%r" %>s %b`, want: []string{"%h", "%l", "%u", "%t", "%r", "%>s", "%b"}},
+		{format: `%%h 100%% %<s`, want: []string{"%<s"}},
+		{format: `%{Referer}i %!200,304{User-Agent}i %400,501T %^ti`, want: []string{"%{referer}i", "%{user-agent}i", "%T", "%^ti"}},
+		{format: `%{c}h %{%Y-%m-%d}t`, want: []string{"%{c}h", "%{%y-%m-%d}t"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			want := make(map[string]bool)
+			for _, directive := range test.want {
+				want[directive] = true
+			}
+			if got := apacheFormatDirectives(test.format); !reflect.DeepEqual(got, want) {
+				t.Errorf("apacheFormatDirectives(%q) = %v, want %v", test.format, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+	"elastic-integration-docs-mcp/internal/version"
+)
+
+// maxPatch bounds the patch releases searched when finding the first
+// compatible patch of a stack minor version
+const maxPatch = 99
+
+type CompatibilityProvider struct {
+	configLoader *config.ConfigLoader
+	// loadErr is reported by CheckCompatibility and GetCompatibilityMatrix
+	loadErr error
+}
+
+func NewCompatibilityProvider(configDir string) *CompatibilityProvider {
+	configLoader := config.NewConfigLoaderFromEnv(configDir)
+	loadErr := configLoader.LoadAllServices()
+	if loadErr != nil {
+		configLoader = config.NewConfigLoader(configDir)
+	}
+
+	return &CompatibilityProvider{
+		configLoader: configLoader,
+		loadErr:      loadErr,
+	}
+}
+
+// CheckCompatibility reports whether a service, or every service when
+// serviceName is empty, supports an Elastic Stack version
+func (c *CompatibilityProvider) CheckCompatibility(serviceName, stackVersion string) (shared.CallToolResult, error) {
+	if c.loadErr != nil {
+		return errorResult(c.loadErr.Error()), nil
+	}
+	parsed, err := version.Parse(stackVersion)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	if serviceName != "" {
+		serviceConfig, err := c.configLoader.GetServiceConfig(serviceName)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		return textResult(formatServiceCompatibility(serviceConfig, stackVersion, parsed)), nil
+	}
+
+	var compatible, incompatible, unknown []string
+	for _, name := range c.sortedServiceNames() {
+		serviceConfig, _ := c.configLoader.GetServiceConfig(name)
+		constraint, ok := serviceConfig.ServiceInfo.Compatibility.StackConstraint()
+		switch {
+		case !ok:
+			unknown = append(unknown, name)
+		case constraint.Check(parsed):
+			compatible = append(compatible, name)
+		default:
+			incompatible = append(incompatible, fmt.Sprintf("%s (%s)", name, constraint))
+		}
+	}
+
+	report := fmt.Sprintf(`# Elastic Stack %s Compatibility
+
+## Compatible (%d)
+%s
+## Not Compatible (%d)
+%s
+## Unknown (%d)
+%s`,
+		stackVersion,
+		len(compatible), formatList(compatible),
+		len(incompatible), formatList(incompatible),
+		len(unknown), formatList(unknown))
+
+	return textResult(report), nil
+}
+
+// GetCompatibilityMatrix exports which services support which Elastic Stack
+// minor versions. When stackVersions is empty, the minors are derived from
+// the versions mentioned in all services' constraints.
+func (c *CompatibilityProvider) GetCompatibilityMatrix(stackVersions []string, format string) (shared.CallToolResult, error) {
+	if c.loadErr != nil {
+		return errorResult(c.loadErr.Error()), nil
+	}
+	names := c.sortedServiceNames()
+	constraints := make(map[string]version.Constraint, len(names))
+	for _, name := range names {
+		serviceConfig, _ := c.configLoader.GetServiceConfig(name)
+		if constraint, ok := serviceConfig.ServiceInfo.Compatibility.StackConstraint(); ok {
+			constraints[name] = constraint
+		}
+	}
+
+	var minors []version.Version
+	if len(stackVersions) > 0 {
+		for _, s := range stackVersions {
+			v, err := version.Parse(s)
+			if err != nil {
+				return errorResult(err.Error()), nil
+			}
+			minors = append(minors, version.Version{Major: v.Major, Minor: v.Minor})
+		}
+	} else {
+		minors = deriveStackMinors(constraints)
+	}
+
+	matrix := make([]shared.CompatibilityRow, 0, len(names))
+	for _, name := range names {
+		row := shared.CompatibilityRow{Service: name, Support: make(map[string]string)}
+		constraint, ok := constraints[name]
+		row.Constraint = constraint.String()
+		for _, minor := range minors {
+			row.Support[minorLabel(minor)] = minorSupport(constraint, ok, minor)
+		}
+		matrix = append(matrix, row)
+	}
+
+	switch strings.ToLower(format) {
+	case "", "markdown":
+		return textResult(formatMatrixMarkdown(matrix, minors)), nil
+	case "csv":
+		return textResult(formatMatrixCSV(matrix, minors)), nil
+	case "json":
+		return formatJSONResult(matrix, "compatibility matrix")
+	default:
+		return errorResult(fmt.Sprintf("unknown format '%s'. Supported formats: markdown, csv, json", format)), nil
+	}
+}
+
+func (c *CompatibilityProvider) sortedServiceNames() []string {
+	names := c.configLoader.GetAllServiceNames()
+	sort.Strings(names)
+	return names
+}
+
+func formatServiceCompatibility(serviceConfig *config.ServiceConfig, stackVersion string, v version.Version) string {
+	constraint, ok := serviceConfig.ServiceInfo.Compatibility.StackConstraint()
+	if !ok {
+		return fmt.Sprintf("# %s Compatibility with Elastic Stack %s\n\n**Compatible**: unknown\n\nNo Elastic Stack version constraint is defined for %s.",
+			serviceConfig.Title, stackVersion, serviceConfig.ServiceName)
+	}
+
+	if constraint.Check(v) {
+		return fmt.Sprintf("# %s Compatibility with Elastic Stack %s\n\n**Compatible**: yes\n**Constraint**: %s",
+			serviceConfig.Title, stackVersion, constraint)
+	}
+
+	var minimums []string
+	for _, mentioned := range constraint.Versions() {
+		minimums = append(minimums, mentioned.String())
+	}
+	return fmt.Sprintf("# %s Compatibility with Elastic Stack %s\n\n**Compatible**: no\n**Constraint**: %s\n\n## Versions Referenced by the Constraint\n%s",
+		serviceConfig.Title, stackVersion, constraint, formatList(minimums))
+}
+
+// deriveStackMinors returns every minor from x.0 up to the highest minor
+// mentioned for each major version referenced by the constraints
+func deriveStackMinors(constraints map[string]version.Constraint) []version.Version {
+	highest := make(map[int]int)
+	for _, constraint := range constraints {
+		for _, v := range constraint.Versions() {
+			if minor, exists := highest[v.Major]; !exists || v.Minor > minor {
+				highest[v.Major] = v.Minor
+			}
+		}
+	}
+
+	majors := make([]int, 0, len(highest))
+	for major := range highest {
+		majors = append(majors, major)
+	}
+	sort.Ints(majors)
+
+	var minors []version.Version
+	for _, major := range majors {
+		for minor := 0; minor <= highest[major]; minor++ {
+			minors = append(minors, version.Version{Major: major, Minor: minor})
+		}
+	}
+	return minors
+}
+
+// minorSupport describes support for a minor version: "yes" when every
+// patch is supported, ">=x.y.z" when support starts at a later patch, and
+// "no" or "unknown" otherwise
+func minorSupport(constraint version.Constraint, known bool, minor version.Version) string {
+	if !known {
+		return "unknown"
+	}
+	for patch := 0; patch <= maxPatch; patch++ {
+		v := version.Version{Major: minor.Major, Minor: minor.Minor, Patch: patch}
+		if constraint.Check(v) {
+			if patch == 0 {
+				return "yes"
+			}
+			return ">=" + v.String()
+		}
+	}
+	return "no"
+}
+
+func minorLabel(v version.Version) string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func formatMatrixMarkdown(matrix []shared.CompatibilityRow, minors []version.Version) string {
+	var result strings.Builder
+	result.WriteString("# Elastic Stack Compatibility Matrix\n\n| Service |")
+	for _, minor := range minors {
+		result.WriteString(" " + minorLabel(minor) + " |")
+	}
+	result.WriteString("\n|---|")
+	for range minors {
+		result.WriteString("---|")
+	}
+	result.WriteString("\n")
+
+	for _, row := range matrix {
+		result.WriteString("| " + row.Service + " |")
+		for _, minor := range minors {
+			cell := row.Support[minorLabel(minor)]
+			switch cell {
+			case "yes":
+				cell = "✓"
+			case "no":
+				cell = ""
+			}
+			result.WriteString(" " + cell + " |")
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+func formatMatrixCSV(matrix []shared.CompatibilityRow, minors []version.Version) string {
+	var result strings.Builder
+	writer := csv.NewWriter(&result)
+
+	header := []string{"service", "constraint"}
+	for _, minor := range minors {
+		header = append(header, minorLabel(minor))
+	}
+	writer.Write(header)
+
+	for _, row := range matrix {
+		record := []string{row.Service, row.Constraint}
+		for _, minor := range minors {
+			record = append(record, row.Support[minorLabel(minor)])
+		}
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	return result.String()
+}
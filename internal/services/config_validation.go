@@ -0,0 +1,511 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// placeholderPattern matches values left over from documentation templates,
+// and masked values such as the ******** of generated policies
+var placeholderPattern = regexp.MustCompile(`^<[^>]*>$|^\*{3,}$|^(?i:changeme|change-me|replaceme|replace-me|todo|x{3,})$`)
+
+// yamlLinePattern extracts the line number from a yaml.v3 error message
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// invalidNamespaceChars are the characters Fleet rejects in a data stream namespace
+const invalidNamespaceChars = `*\/?"<>|, #:-`
+
+// configValidator accumulates findings while walking a parsed configuration
+type configValidator struct {
+	integration *shared.IntegrationDetails
+	result      shared.ValidationResult
+}
+
+// ValidateConfiguration parses a Fleet package policy or standalone agent
+// policy for a service and checks it against the integration's inputs,
+// variables and data streams. Findings carry the line and column they refer to.
+// The integration is read from the package tree when it has the package.
+func (v *ValidationProvider) ValidateConfiguration(serviceName, configuration, configType string) (shared.CallToolResult, error) {
+	var integration *shared.IntegrationDetails
+	details, _, err := v.integrations.integrationDetails(serviceName)
+	var notFound *integrationNotFoundError
+	switch {
+	case err == nil:
+		integration = &details
+	case !errors.As(err, &notFound):
+		return errorResult(err.Error()), nil
+	default:
+		if _, err := v.configLoader.GetServiceConfig(serviceName); err != nil {
+			return errorResult(err.Error()), nil
+		}
+	}
+
+	validator := &configValidator{
+		integration: integration,
+		result: shared.ValidationResult{
+			Errors:      []shared.ValidationError{},
+			Warnings:    []shared.ValidationWarning{},
+			Suggestions: []shared.ValidationSuggestion{},
+		},
+	}
+	if integration == nil {
+		validator.addWarning("metadata", fmt.Sprintf("No integration metadata is available for %s; variables and data streams were not checked", serviceName), "", nil)
+	}
+
+	root, syntaxErr, err := parseConfiguration(configuration, configType)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	if syntaxErr != nil {
+		validator.result.Errors = append(validator.result.Errors, *syntaxErr)
+		return formatJSONResult(validator.result, "validation result")
+	}
+
+	switch {
+	case root.Kind != yaml.MappingNode:
+		validator.addError("structure", "Configuration must be a mapping at the top level", root)
+	case mappingValue(root, "package") != nil:
+		validator.validatePackagePolicy(root)
+	case mappingValue(root, "inputs") != nil:
+		validator.validateAgentPolicy(root)
+	default:
+		validator.addError("structure", "Configuration is neither a Fleet package policy (package, inputs) nor an agent policy (outputs, inputs)", root)
+	}
+
+	validator.result.IsValid = len(validator.result.Errors) == 0
+	return formatJSONResult(validator.result, "validation result")
+}
+
+// parseConfiguration parses YAML or JSON into a document node. Syntax errors
+// are returned as a validation error with their location.
+func parseConfiguration(configuration, configType string) (*yaml.Node, *shared.ValidationError, error) {
+	switch strings.ToLower(configType) {
+	case "":
+		if strings.HasPrefix(strings.TrimSpace(configuration), "{") {
+			configType = "json"
+		}
+	case "json", "yaml", "yml":
+	default:
+		return nil, nil, fmt.Errorf("unsupported config_type '%s'. Supported types: yaml, json", configType)
+	}
+
+	if strings.EqualFold(configType, "json") {
+		var value interface{}
+		if err := json.Unmarshal([]byte(configuration), &value); err != nil {
+			parseErr := shared.ValidationError{Type: "syntax", Message: fmt.Sprintf("Invalid JSON: %v", err), Severity: "error"}
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				// The offset counts the invalid byte itself
+				line, column := offsetPosition(configuration, max(syntaxErr.Offset-1, 0))
+				parseErr.Line, parseErr.Column = &line, &column
+			}
+			return nil, &parseErr, nil
+		}
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(configuration), &document); err != nil {
+		parseErr := shared.ValidationError{Type: "syntax", Message: fmt.Sprintf("Invalid YAML: %v", err), Severity: "error"}
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			parseErr.Line = &line
+		}
+		return nil, &parseErr, nil
+	}
+	if len(document.Content) == 0 {
+		return nil, &shared.ValidationError{Type: "syntax", Message: "Configuration is empty", Severity: "error"}, nil
+	}
+	return document.Content[0], nil, nil
+}
+
+// validatePackagePolicy checks a Fleet package policy body in the simplified
+// format produced by generate_policy
+func (c *configValidator) validatePackagePolicy(root *yaml.Node) {
+	pkg := mappingValue(root, "package")
+	if name := mappingValue(pkg, "name"); name == nil {
+		c.addError("required", "package.name is required", pkg)
+	} else if c.integration != nil && name.Value != c.integration.Name {
+		c.addError("package", fmt.Sprintf("package.name is '%s' but the configuration is being validated for %s", name.Value, c.integration.Name), name)
+	}
+
+	if namespace := mappingValue(root, "namespace"); namespace != nil {
+		c.checkNamespace(namespace)
+	}
+	if mappingValue(root, "policy_id") == nil {
+		c.addSuggestion("policy", "No policy_id is set", "Set policy_id to the agent policy the integration should be added to", "Fleet rejects package policies without an agent policy")
+	}
+
+	inputs := mappingValue(root, "inputs")
+	if inputs == nil {
+		c.addError("required", "inputs is required", root)
+		return
+	}
+	if inputs.Kind != yaml.MappingNode {
+		c.addError("structure", "inputs must be a mapping of '<policy template>-<input type>' to input settings", inputs)
+		return
+	}
+
+	for index := 0; index+1 < len(inputs.Content); index += 2 {
+		key, value := inputs.Content[index], inputs.Content[index+1]
+		if c.integration == nil {
+			continue
+		}
+
+		template, input, found := c.findFleetInput(key.Value)
+		if !found {
+			c.addError("input", fmt.Sprintf("Unknown input '%s'. Inputs are named '<policy template>-<input type>'", key.Value), key)
+			continue
+		}
+
+		c.checkVars(input, mappingPairs(mappingValue(value, "vars")), value, true)
+
+		streams := mappingValue(value, "streams")
+		for streamIndex := 0; streams != nil && streamIndex+1 < len(streams.Content); streamIndex += 2 {
+			streamKey := streams.Content[streamIndex]
+			dataStream := strings.TrimPrefix(streamKey.Value, c.integration.Name+".")
+			if !containsString(template.DataStreams, dataStream) {
+				c.addError("data_stream", fmt.Sprintf("Stream '%s' is not a data stream of policy template %s. Available data streams: %s",
+					streamKey.Value, template.Name, strings.Join(template.DataStreams, ", ")), streamKey)
+			}
+		}
+	}
+}
+
+// validateAgentPolicy checks a standalone elastic-agent.yml or agent policy
+func (c *configValidator) validateAgentPolicy(root *yaml.Node) {
+	outputs := mappingValue(root, "outputs")
+	outputNames := make(map[string]bool)
+	if outputs == nil {
+		c.addError("required", "No outputs are defined; a standalone agent needs at least a 'default' output", root)
+	} else {
+		for name := range mappingPairs(outputs) {
+			outputNames[name] = true
+		}
+	}
+
+	inputs := mappingValue(root, "inputs")
+	if inputs.Kind != yaml.SequenceNode {
+		c.addError("structure", "inputs must be a list", inputs)
+		return
+	}
+
+	for _, inputNode := range inputs.Content {
+		inputType := mappingValue(inputNode, "type")
+		if inputType == nil {
+			c.addError("required", "Input is missing 'type'", inputNode)
+			continue
+		}
+
+		output, outputNode := "default", inputNode
+		if useOutput := mappingValue(inputNode, "use_output"); useOutput != nil {
+			output, outputNode = useOutput.Value, useOutput
+		}
+		if outputs != nil && !outputNames[output] {
+			c.addError("output", fmt.Sprintf("Input uses output '%s', which is not defined in outputs", output), outputNode)
+		}
+
+		if namespace := mappingValue(mappingValue(inputNode, "data_stream"), "namespace"); namespace != nil {
+			c.checkNamespace(namespace)
+		}
+
+		input, known := c.findAgentInput(inputType.Value)
+		if c.integration != nil && !known {
+			c.addWarning("input", fmt.Sprintf("Input type '%s' is not used by the %s integration", inputType.Value, c.integration.Name), "", inputType)
+		}
+
+		inputVars := streamSettings(inputNode)
+		streams := mappingValue(inputNode, "streams")
+		if streams == nil || streams.Kind != yaml.SequenceNode || len(streams.Content) == 0 {
+			c.addError("required", fmt.Sprintf("Input '%s' has no streams", inputType.Value), inputNode)
+			continue
+		}
+
+		for _, stream := range streams.Content {
+			dataset := mappingValue(mappingValue(stream, "data_stream"), "dataset")
+			if dataset == nil {
+				c.addError("required", "Stream is missing data_stream.dataset", stream)
+			} else {
+				c.checkDataset(dataset)
+			}
+
+			if known {
+				vars := streamSettings(stream)
+				for name, node := range inputVars {
+					if _, exists := vars[name]; !exists {
+						vars[name] = node
+					}
+				}
+				c.checkVars(input, vars, stream, false)
+			}
+		}
+	}
+
+	if mappingValue(root, "agent") == nil {
+		c.addSuggestion("monitoring", "Agent monitoring is not configured", "Add agent.monitoring with enabled: true and use_output: default", "Without monitoring, agent health and errors are not visible in Elasticsearch")
+	}
+}
+
+// checkVars checks variable values against an input's variable definitions.
+// Fleet applies variable defaults itself; standalone agents do not.
+func (c *configValidator) checkVars(input shared.Input, values map[string]*yaml.Node, parent *yaml.Node, fleet bool) {
+	for _, variable := range input.Vars {
+		node, exists := values[variable.Name]
+		if !exists {
+			switch {
+			case !variable.Required:
+			case variable.Default == nil:
+				c.addError("required", fmt.Sprintf("Required variable '%s' (%s) is not set for input %s", variable.Name, variable.Title, input.Type), parent)
+			case !fleet:
+				c.addWarning("required", fmt.Sprintf("Variable '%s' is not set for input %s", variable.Name, input.Type),
+					fmt.Sprintf("Set %s explicitly; the package default %v is not applied to standalone agents", variable.Name, variable.Default), parent)
+			}
+			continue
+		}
+
+		if isVarReference(node) {
+			continue
+		}
+		if problem := checkNodeType(variable, node); problem != "" {
+			c.addError("type", fmt.Sprintf("Variable '%s' %s", variable.Name, problem), node)
+			continue
+		}
+		placeholder := false
+		for _, scalar := range scalarNodes(node) {
+			switch {
+			case scalar.Value == maskedValue:
+				c.addError("placeholder", fmt.Sprintf("Variable '%s' still contains the masked value '%s' of a generated policy; set the real value", variable.Name, scalar.Value), scalar)
+			case placeholderPattern.MatchString(scalar.Value):
+				c.addError("placeholder", fmt.Sprintf("Variable '%s' still contains the placeholder value '%s'", variable.Name, scalar.Value), scalar)
+			default:
+				continue
+			}
+			placeholder = true
+		}
+		if variable.Secret && node.Kind == yaml.ScalarNode && !placeholder {
+			suggestion := "Store the value as a Fleet secret"
+			if !fleet {
+				suggestion = fmt.Sprintf("Reference an environment variable instead, e.g. ${env.%s}", strings.ToUpper(c.integration.Name+"_"+variable.Name))
+			}
+			c.addWarning("secret", fmt.Sprintf("Secret variable '%s' is stored in plain text", variable.Name), suggestion, node)
+		}
+	}
+
+	if !fleet {
+		return
+	}
+	for _, name := range sortedKeys(values) {
+		if node := values[name]; !inputHasVar(input, name) {
+			c.addWarning("unknown_variable", fmt.Sprintf("Variable '%s' is not defined for input %s", name, input.Type), "Remove it or check the variable name for typos", node)
+		}
+	}
+}
+
+// checkDataset checks that a stream's dataset belongs to the integration
+func (c *configValidator) checkDataset(dataset *yaml.Node) {
+	if c.integration == nil {
+		return
+	}
+	prefix := c.integration.Name + "."
+	if !strings.HasPrefix(dataset.Value, prefix) {
+		c.addWarning("data_stream", fmt.Sprintf("Dataset '%s' does not belong to the %s integration; its ingest pipeline and mappings will not be applied", dataset.Value, c.integration.Name),
+			fmt.Sprintf("Use a dataset of the form %s<data stream>", prefix), dataset)
+		return
+	}
+	for _, stream := range c.integration.DataStreams {
+		if prefix+stream.Name == dataset.Value {
+			return
+		}
+	}
+	c.addError("data_stream", fmt.Sprintf("Dataset '%s' is not a data stream of the %s integration", dataset.Value, c.integration.Name), dataset)
+}
+
+// checkNamespace applies Fleet's data stream namespace restrictions
+func (c *configValidator) checkNamespace(namespace *yaml.Node) {
+	switch {
+	case namespace.Value == "":
+		c.addError("namespace", "Namespace must not be empty", namespace)
+	case strings.ToLower(namespace.Value) != namespace.Value:
+		c.addError("namespace", fmt.Sprintf("Namespace '%s' must be lowercase", namespace.Value), namespace)
+	case strings.ContainsAny(namespace.Value, invalidNamespaceChars):
+		c.addError("namespace", fmt.Sprintf("Namespace '%s' must not contain any of %s", namespace.Value, invalidNamespaceChars), namespace)
+	case len(namespace.Value) > 100:
+		c.addError("namespace", "Namespace must be at most 100 bytes", namespace)
+	}
+}
+
+// findFleetInput finds the policy template input named "<template>-<type>"
+func (c *configValidator) findFleetInput(name string) (shared.PolicyTemplate, shared.Input, bool) {
+	for _, template := range c.integration.PolicyTemplates {
+		for _, input := range template.Inputs {
+			if template.Name+"-"+input.Type == name {
+				return template, input, true
+			}
+		}
+	}
+	return shared.PolicyTemplate{}, shared.Input{}, false
+}
+
+// findAgentInput finds the first policy template input of a type
+func (c *configValidator) findAgentInput(inputType string) (shared.Input, bool) {
+	if c.integration == nil {
+		return shared.Input{}, false
+	}
+	for _, template := range c.integration.PolicyTemplates {
+		for _, input := range template.Inputs {
+			if input.Type == inputType {
+				return input, true
+			}
+		}
+	}
+	return shared.Input{}, false
+}
+
+func (c *configValidator) addError(errType, message string, node *yaml.Node) {
+	line, column := nodePosition(node)
+	c.result.Errors = append(c.result.Errors, shared.ValidationError{
+		Type:     errType,
+		Message:  message,
+		Line:     line,
+		Column:   column,
+		Severity: "error",
+	})
+}
+
+func (c *configValidator) addWarning(warningType, message, suggestion string, node *yaml.Node) {
+	line, column := nodePosition(node)
+	c.result.Warnings = append(c.result.Warnings, shared.ValidationWarning{
+		Type:       warningType,
+		Message:    message,
+		Line:       line,
+		Column:     column,
+		Suggestion: suggestion,
+	})
+}
+
+func (c *configValidator) addSuggestion(suggestionType, message, suggestion, impact string) {
+	c.result.Suggestions = append(c.result.Suggestions, shared.ValidationSuggestion{
+		Type:       suggestionType,
+		Message:    message,
+		Suggestion: suggestion,
+		Impact:     impact,
+	})
+}
+
+// checkNodeType describes how a value violates a variable's type, or returns ""
+func checkNodeType(variable shared.Variable, node *yaml.Node) string {
+	if variable.Multi {
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Sprintf("must be a list of %s values", variable.Type)
+		}
+		for _, item := range node.Content {
+			if problem := checkScalarNodeType(variable.Type, item); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	}
+	return checkScalarNodeType(variable.Type, node)
+}
+
+func checkScalarNodeType(varType string, node *yaml.Node) string {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Sprintf("must be a single %s value", varType)
+	}
+	switch varType {
+	case "integer":
+		if node.Tag != "!!int" {
+			return fmt.Sprintf("must be an integer, got '%s'", node.Value)
+		}
+	case "bool":
+		if node.Tag != "!!bool" {
+			return fmt.Sprintf("must be true or false, got '%s'", node.Value)
+		}
+	}
+	return ""
+}
+
+// isVarReference reports whether a value is a ${...} reference resolved by the agent
+func isVarReference(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && strings.HasPrefix(node.Value, "${") && strings.HasSuffix(node.Value, "}")
+}
+
+func inputHasVar(input shared.Input, name string) bool {
+	for _, variable := range input.Vars {
+		if variable.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mappingValue returns the value node for a key of a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key {
+			return node.Content[index+1]
+		}
+	}
+	return nil
+}
+
+// mappingPairs returns the values of a mapping node by key
+func mappingPairs(node *yaml.Node) map[string]*yaml.Node {
+	pairs := make(map[string]*yaml.Node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return pairs
+	}
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		pairs[node.Content[index].Value] = node.Content[index+1]
+	}
+	return pairs
+}
+
+// streamSettings returns the settings of an agent input or stream, excluding
+// the keys that structure the policy rather than configure the input
+func streamSettings(node *yaml.Node) map[string]*yaml.Node {
+	pairs := mappingPairs(node)
+	for _, key := range []string{"id", "type", "use_output", "data_stream", "streams", "enabled", "meta"} {
+		delete(pairs, key)
+	}
+	return pairs
+}
+
+func scalarNodes(node *yaml.Node) []*yaml.Node {
+	if node.Kind == yaml.ScalarNode {
+		return []*yaml.Node{node}
+	}
+	var scalars []*yaml.Node
+	for _, child := range node.Content {
+		scalars = append(scalars, scalarNodes(child)...)
+	}
+	return scalars
+}
+
+func nodePosition(node *yaml.Node) (*int, *int) {
+	if node == nil || node.Line == 0 {
+		return nil, nil
+	}
+	line, column := node.Line, node.Column
+	return &line, &column
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(text string, offset int64) (int, int) {
+	if offset > int64(len(text)) {
+		offset = int64(len(text))
+	}
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := int(offset) - strings.LastIndex(before, "\n")
+	return line, column
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/packages"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// demoManifest is a package manifest whose only input has one variable of
+// each kind the validator checks
+const demoManifest = `name: demo
+title: Demo
+version: 1.2.0
+policy_templates:
+  - name: demo
+    title: Demo metrics
+    inputs:
+      - type: demo/metrics
+        title: Collect demo metrics
+        vars:
+          - name: hosts
+            type: text
+            title: Hosts
+            multi: true
+            required: true
+          - name: period
+            type: text
+            title: Period
+            required: true
+            default: 10s
+          - name: retries
+            type: integer
+            title: Retries
+          - name: ssl
+            type: bool
+            title: Use TLS
+          - name: password
+            type: password
+            title: Password
+            secret: true
+`
+
+// writeTestFiles writes files, keyed by slash-separated path, under root
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestIntegrationProvider returns an integration provider reading the
+// given package tree, with the built-in examples and without ECS
+func newTestIntegrationProvider(t *testing.T, files map[string]string) *IntegrationProvider {
+	t.Helper()
+	root := t.TempDir()
+	writeTestFiles(t, root, files)
+	t.Setenv("ECS_FIELDS_FILE", "")
+	provider := NewIntegrationProvider()
+	provider.packages = packages.NewLoader(root)
+	return provider
+}
+
+func newTestValidationProvider(t *testing.T) *ValidationProvider {
+	t.Helper()
+	return &ValidationProvider{
+		configLoader: config.NewConfigLoader(t.TempDir()),
+		integrations: newTestIntegrationProvider(t, map[string]string{
+			"demo/manifest.yml":                    demoManifest,
+			"demo/data_stream/status/manifest.yml": "title: Status\ntype: metrics\n",
+		}),
+	}
+}
+
+// validationFindings runs ValidateConfiguration and formats its errors and
+// warnings as "line:column type" strings
+func validationFindings(t *testing.T, provider *ValidationProvider, service, configuration string) ([]string, []string) {
+	t.Helper()
+	result, err := provider.ValidateConfiguration(service, configuration, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("ValidateConfiguration failed: %s", result.Content[0].Text)
+	}
+	var validation shared.ValidationResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &validation); err != nil {
+		t.Fatal(err)
+	}
+
+	var errors, warnings []string
+	for _, finding := range validation.Errors {
+		errors = append(errors, formatFinding(finding.Line, finding.Column, finding.Type, finding.Message))
+	}
+	for _, finding := range validation.Warnings {
+		warnings = append(warnings, formatFinding(finding.Line, finding.Column, finding.Type, finding.Message))
+	}
+	return errors, warnings
+}
+
+func formatFinding(line, column *int, findingType, message string) string {
+	if line == nil || column == nil {
+		return fmt.Sprintf("-:- %s: %s", findingType, message)
+	}
+	return fmt.Sprintf("%d:%d %s: %s", *line, *column, findingType, message)
+}
+
+func TestValidatePackagePolicyVars(t *testing.T) {
+	provider := newTestValidationProvider(t)
+	policy := func(vars string) string {
+		return `package:
+  name: demo
+policy_id: agent-policy
+inputs:
+  demo-demo/metrics:
+    vars:
+` + vars + `    streams:
+      demo.status:
+        enabled: true
+`
+	}
+
+	tests := []struct {
+		name     string
+		vars     string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "valid",
+			vars: "      hosts: [http://localhost:8080]\n      retries: 3\n      ssl: true\n      password: ${env.DEMO_PASSWORD}\n",
+		},
+		{
+			name:   "missing required variable without default",
+			vars:   "      retries: 3\n",
+			errors: []string{"6:5 required: Required variable 'hosts' (Hosts) is not set for input demo/metrics"},
+		},
+		{
+			name: "wrong types",
+			vars: "      hosts: http://localhost:8080\n      retries: three\n      ssl: maybe\n",
+			errors: []string{
+				"7:14 type: Variable 'hosts' must be a list of text values",
+				"8:16 type: Variable 'retries' must be an integer, got 'three'",
+				"9:12 type: Variable 'ssl' must be true or false, got 'maybe'",
+			},
+		},
+		{
+			name:   "masked secret",
+			vars:   "      hosts: [http://localhost:8080]\n      password: '********'\n",
+			errors: []string{"8:17 placeholder: Variable 'password' still contains the masked value '********' of a generated policy; set the real value"},
+		},
+		{
+			name:   "asterisk placeholder",
+			vars:   "      hosts: [http://localhost:8080]\n      password: '***'\n",
+			errors: []string{"8:17 placeholder: Variable 'password' still contains the placeholder value '***'"},
+		},
+		{
+			name:   "placeholder in a list",
+			vars:   "      hosts:\n        - http://localhost:8080\n        - <your-host>\n",
+			errors: []string{"9:11 placeholder: Variable 'hosts' still contains the placeholder value '<your-host>'"},
+		},
+		{
+			name:     "plain text secret",
+			vars:     "      hosts: [http://localhost:8080]\n      password: hunter2\n",
+			warnings: []string{"8:17 secret: Secret variable 'password' is stored in plain text"},
+		},
+		{
+			name:     "unknown variable",
+			vars:     "      hosts: [http://localhost:8080]\n      hostz: [http://localhost:8081]\n",
+			warnings: []string{"8:14 unknown_variable: Variable 'hostz' is not defined for input demo/metrics"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errors, warnings := validationFindings(t, provider, "demo", policy(test.vars))
+			if !reflect.DeepEqual(errors, test.errors) {
+				t.Errorf("errors = %q, want %q", errors, test.errors)
+			}
+			if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestValidateAgentPolicyVars(t *testing.T) {
+	provider := newTestValidationProvider(t)
+	agentPolicy := func(settings string) string {
+		return `outputs:
+  default:
+    type: elasticsearch
+agent:
+  monitoring:
+    enabled: true
+inputs:
+  - type: demo/metrics
+    streams:
+      - data_stream:
+          dataset: demo.status
+` + settings
+	}
+
+	tests := []struct {
+		name     string
+		settings string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:     "default not applied",
+			settings: "        hosts: [http://localhost:8080]\n",
+			warnings: []string{"10:9 required: Variable 'period' is not set for input demo/metrics"},
+		},
+		{
+			name:     "missing required variable",
+			settings: "        period: 10s\n",
+			errors:   []string{"10:9 required: Required variable 'hosts' (Hosts) is not set for input demo/metrics"},
+		},
+		{
+			name:     "masked secret",
+			settings: "        hosts: [http://localhost:8080]\n        period: 10s\n        password: '********'\n",
+			errors:   []string{"14:19 placeholder: Variable 'password' still contains the masked value '********' of a generated policy; set the real value"},
+		},
+		{
+			name:     "plain text secret",
+			settings: "        hosts: [http://localhost:8080]\n        period: 10s\n        password: hunter2\n",
+			warnings: []string{"14:19 secret: Secret variable 'password' is stored in plain text"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errors, warnings := validationFindings(t, provider, "demo", agentPolicy(test.settings))
+			if !reflect.DeepEqual(errors, test.errors) {
+				t.Errorf("errors = %q, want %q", errors, test.errors)
+			}
+			if !reflect.DeepEqual(warnings, test.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestValidateConfigurationSyntaxLocation(t *testing.T) {
+	provider := newTestValidationProvider(t)
+	tests := []struct {
+		name          string
+		configuration string
+		errors        []string
+	}{
+		{
+			name:          "YAML",
+			configuration: "package:\n  name: demo\ninputs: [\n",
+			errors:        []string{"3:- syntax: Invalid YAML: yaml: line 3: did not find expected node content"},
+		},
+		{
+			name:          "JSON",
+			configuration: "{\n  \"package\": {\"name\": \"demo\"},\n  \"inputs\": }\n",
+			errors:        []string{"3:13 syntax: Invalid JSON: invalid character '}' looking for beginning of value"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := provider.ValidateConfiguration("demo", test.configuration, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var validation shared.ValidationResult
+			if err := json.Unmarshal([]byte(result.Content[0].Text), &validation); err != nil {
+				t.Fatal(err)
+			}
+			var errors []string
+			for _, finding := range validation.Errors {
+				column := "-"
+				if finding.Column != nil {
+					column = fmt.Sprint(*finding.Column)
+				}
+				errors = append(errors, fmt.Sprintf("%d:%s %s: %s", *finding.Line, column, finding.Type, finding.Message))
+			}
+			if !reflect.DeepEqual(errors, test.errors) {
+				t.Errorf("errors = %q, want %q", errors, test.errors)
+			}
+		})
+	}
+}
+
+func TestValidateConfigurationBrokenPackage(t *testing.T) {
+	provider := &ValidationProvider{
+		configLoader: config.NewConfigLoader(t.TempDir()),
+		integrations: newTestIntegrationProvider(t, map[string]string{
+			"broken/manifest.yml": "name: broken\npolicy_templates: {\n",
+		}),
+	}
+	result, err := provider.ValidateConfiguration("broken", "package:\n  name: broken\ninputs: {}\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Errorf("ValidateConfiguration succeeded for a package that fails to load: %s", result.Content[0].Text)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// ConfigValidator checks the configuration file of a source service, such as
+// nginx.conf or my.cnf, for settings that stop an integration from collecting
+// or parsing data
+type ConfigValidator interface {
+	// Name returns the format the validator is registered under
+	Name() string
+	// Description describes the files the validator understands
+	Description() string
+	// Validate parses the configuration and reports findings with line numbers
+	Validate(content string) shared.ValidationResult
+}
+
+// configValidators holds the registered validators by format name and alias
+var configValidators = make(map[string]ConfigValidator)
+
+func init() {
+	RegisterConfigValidator(nginxValidator{})
+	RegisterConfigValidator(apacheValidator{}, "httpd", "apache2")
+	RegisterConfigValidator(mysqlValidator{}, "my.cnf", "mariadb")
+	RegisterConfigValidator(rsyslogValidator{})
+	RegisterConfigValidator(syslogNGValidator{}, "syslog_ng")
+}
+
+// RegisterConfigValidator registers a validator under its name and any aliases
+func RegisterConfigValidator(validator ConfigValidator, aliases ...string) {
+	configValidators[strings.ToLower(validator.Name())] = validator
+	for _, alias := range aliases {
+		configValidators[strings.ToLower(alias)] = validator
+	}
+}
+
+// GetConfigValidator returns the validator registered for a format or alias
+func GetConfigValidator(format string) (ConfigValidator, bool) {
+	validator, exists := configValidators[strings.ToLower(strings.TrimSpace(format))]
+	return validator, exists
+}
+
+// ConfigValidatorNames returns the names of the registered validators, without aliases
+func ConfigValidatorNames() []string {
+	var names []string
+	for key, validator := range configValidators {
+		if key == strings.ToLower(validator.Name()) {
+			names = append(names, validator.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateServiceConfig validates a source service configuration file. The
+// format defaults to the service name, so nginx, apache and mysql need no format.
+func (v *ValidationProvider) ValidateServiceConfig(serviceName, format, configuration string) (shared.CallToolResult, error) {
+	if format == "" {
+		format = serviceName
+	}
+
+	validator, exists := GetConfigValidator(format)
+	if !exists {
+		return errorResult(fmt.Sprintf("No configuration validator for '%s'. Available formats: %s",
+			format, strings.Join(ConfigValidatorNames(), ", "))), nil
+	}
+
+	result := validator.Validate(configuration)
+	result.IsValid = len(result.Errors) == 0
+	return formatJSONResult(result, "validation result")
+}
+
+// findings collects line-numbered results for a ConfigValidator
+type findings struct {
+	result shared.ValidationResult
+}
+
+func newFindings() *findings {
+	return &findings{
+		result: shared.ValidationResult{
+			Errors:      []shared.ValidationError{},
+			Warnings:    []shared.ValidationWarning{},
+			Suggestions: []shared.ValidationSuggestion{},
+		},
+	}
+}
+
+func (f *findings) addError(line int, errType, message string) {
+	f.result.Errors = append(f.result.Errors, shared.ValidationError{
+		Type:     errType,
+		Message:  message,
+		Line:     linePointer(line),
+		Severity: "error",
+	})
+}
+
+func (f *findings) addWarning(line int, warningType, message, suggestion string) {
+	f.result.Warnings = append(f.result.Warnings, shared.ValidationWarning{
+		Type:       warningType,
+		Message:    message,
+		Line:       linePointer(line),
+		Suggestion: suggestion,
+	})
+}
+
+func (f *findings) addSuggestion(line int, suggestionType, message, suggestion, impact string) {
+	f.result.Suggestions = append(f.result.Suggestions, shared.ValidationSuggestion{
+		Type:       suggestionType,
+		Message:    message,
+		Suggestion: suggestion,
+		Impact:     impact,
+		Line:       linePointer(line),
+	})
+}
+
+// linePointer returns nil for line 0, which marks findings about the whole file
+func linePointer(line int) *int {
+	if line == 0 {
+		return nil
+	}
+	return &line
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// sessionTTL is how long an idle troubleshooting session is kept
+const sessionTTL = time.Hour
+
+// troubleshootingSession tracks a client's walk through a service's decision tree
+type troubleshootingSession struct {
+	serviceName string
+	node        string
+	path        []string
+	lastUsed    time.Time
+}
+
+// sessionStore holds troubleshooting sessions keyed by session ID
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*troubleshootingSession
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*troubleshootingSession),
+	}
+}
+
+// TroubleshootStep starts a guided troubleshooting session for a service, or
+// advances an existing session using the answer to its current question.
+func (d *DocumentationProvider) TroubleshootStep(serviceName, sessionID, answer string) (shared.CallToolResult, error) {
+	d.sessions.mu.Lock()
+	defer d.sessions.mu.Unlock()
+	d.sessions.pruneLocked(time.Now())
+
+	if sessionID == "" {
+		return d.startSession(serviceName)
+	}
+
+	session, exists := d.sessions.sessions[sessionID]
+	if !exists {
+		return errorResult(fmt.Sprintf("troubleshooting session '%s' not found or expired. Call troubleshoot_step without session_id to start a new session.", sessionID)), nil
+	}
+
+	serviceConfig, err := d.configLoader.GetServiceConfig(session.serviceName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	tree := serviceConfig.Troubleshooting.DecisionTree
+	if tree == nil {
+		return errorResult(fmt.Sprintf("no troubleshooting decision tree is defined for %s", session.serviceName)), nil
+	}
+
+	current := tree.Nodes[session.node]
+	if answer == "" {
+		return formatJSONResult(newTroubleshootingStep(sessionID, session, current), "troubleshooting step")
+	}
+
+	next, ok := lookupAnswer(current.Answers, answer)
+	if !ok {
+		return errorResult(fmt.Sprintf("'%s' is not a valid answer to %q. Valid answers: %s",
+			answer, current.Question, strings.Join(sortedAnswers(current.Answers), ", "))), nil
+	}
+	nextNode := tree.Nodes[next]
+
+	session.node = next
+	session.path = append(session.path, next)
+	session.lastUsed = time.Now()
+
+	step := newTroubleshootingStep(sessionID, session, nextNode)
+	if step.Complete {
+		delete(d.sessions.sessions, sessionID)
+	}
+	return formatJSONResult(step, "troubleshooting step")
+}
+
+func (d *DocumentationProvider) startSession(serviceName string) (shared.CallToolResult, error) {
+	serviceConfig, err := d.configLoader.GetServiceConfig(serviceName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	tree := serviceConfig.Troubleshooting.DecisionTree
+	if tree == nil {
+		return errorResult(fmt.Sprintf("no troubleshooting decision tree is defined for %s. Use get_troubleshooting_help instead.", serviceConfig.ServiceName)), nil
+	}
+	startNode := tree.Nodes[tree.Start]
+
+	sessionID, err := newSessionID()
+	if err != nil {
+		return shared.CallToolResult{}, err
+	}
+
+	session := &troubleshootingSession{
+		serviceName: serviceConfig.ServiceName,
+		node:        tree.Start,
+		path:        []string{tree.Start},
+		lastUsed:    time.Now(),
+	}
+
+	step := newTroubleshootingStep(sessionID, session, startNode)
+	if !step.Complete {
+		d.sessions.sessions[sessionID] = session
+	}
+	return formatJSONResult(step, "troubleshooting step")
+}
+
+// pruneLocked drops sessions idle for longer than sessionTTL. The caller must hold mu.
+func (s *sessionStore) pruneLocked(now time.Time) {
+	for id, session := range s.sessions {
+		if now.Sub(session.lastUsed) > sessionTTL {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func newTroubleshootingStep(sessionID string, session *troubleshootingSession, node config.DecisionNode) shared.TroubleshootingStep {
+	return shared.TroubleshootingStep{
+		SessionID:   sessionID,
+		ServiceName: session.serviceName,
+		Node:        session.node,
+		Question:    node.Question,
+		Commands:    node.Commands,
+		Answers:     sortedAnswers(node.Answers),
+		Solution:    node.Solution,
+		Complete:    len(node.Answers) == 0,
+		Path:        append([]string(nil), session.path...),
+	}
+}
+
+// lookupAnswer finds the node an answer leads to, ignoring case and surrounding whitespace
+func lookupAnswer(answers map[string]string, answer string) (string, bool) {
+	for option, next := range answers {
+		if strings.EqualFold(option, strings.TrimSpace(answer)) {
+			return next, true
+		}
+	}
+	return "", false
+}
+
+func sortedAnswers(answers map[string]string) []string {
+	options := make([]string, 0, len(answers))
+	for option := range answers {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	return options
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -14,10 +14,11 @@ import (
 type DocumentationProvider struct {
 	configLoader *config.ConfigLoader
 	httpClient   *http.Client
+	sessions     *sessionStore
 }
 
 func NewDocumentationProvider(configDir string) *DocumentationProvider {
-	configLoader := config.NewConfigLoader(configDir)
+	configLoader := config.NewConfigLoaderFromEnv(configDir)
 	if err := configLoader.LoadAllServices(); err != nil {
 		// In a real implementation, you might want to handle this error differently
 		// For now, we'll create an empty loader
@@ -29,6 +30,7 @@ func NewDocumentationProvider(configDir string) *DocumentationProvider {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		sessions: newSessionStore(),
 	}
 }
 
@@ -129,7 +131,7 @@ You can manually search by visiting: %s`,
 	}, nil
 }
 
-func (d *DocumentationProvider) GetTroubleshootingHelp(serviceName string) (shared.CallToolResult, error) {
+func (d *DocumentationProvider) GetTroubleshootingHelp(serviceName, errorMessage string) (shared.CallToolResult, error) {
 	serviceConfig, err := d.configLoader.GetServiceConfig(serviceName)
 	if err != nil {
 		return shared.CallToolResult{
@@ -143,27 +145,37 @@ func (d *DocumentationProvider) GetTroubleshootingHelp(serviceName string) (shar
 		}, nil
 	}
 
-	// Format troubleshooting issues as JSON-like structure as shown in requirements
-	var issuesJSON strings.Builder
-	issuesJSON.WriteString("[\n")
-
-	for i, issue := range serviceConfig.Troubleshooting.CommonIssues {
-		issuesJSON.WriteString(fmt.Sprintf("  {\n    \"Issue\":    \"%s\",\n    \"Solution\": \"%s\"\n  }",
-			issue.Issue, issue.Solution))
-		if i < len(serviceConfig.Troubleshooting.CommonIssues)-1 {
-			issuesJSON.WriteString(",")
-		}
-		issuesJSON.WriteString("\n")
+	if strings.TrimSpace(errorMessage) != "" {
+		return d.matchTroubleshootingHelp(serviceConfig, errorMessage)
 	}
 
-	issuesJSON.WriteString("]")
+	guide := toTroubleshootingGuide(serviceConfig.ServiceName, serviceConfig.Troubleshooting)
+	guide.Status = labelledStatus(d.configLoader, serviceConfig.SectionStatus(serviceConfig.Troubleshooting.Status))
+	return formatJSONResult(guide, "troubleshooting guide")
+}
 
-	return shared.CallToolResult{
-		Content: []shared.ToolContent{
-			{
-				Type: "text",
-				Text: issuesJSON.String(),
+func (d *DocumentationProvider) matchTroubleshootingHelp(serviceConfig *config.ServiceConfig, errorMessage string) (shared.CallToolResult, error) {
+	matches := matchTroubleshootingIssues(serviceConfig.Troubleshooting.CommonIssues, errorMessage)
+	if len(matches) == 0 {
+		return shared.CallToolResult{
+			Content: []shared.ToolContent{
+				{
+					Type: "text",
+					Text: fmt.Sprintf("No known %s issues match the error message %q. Call get_troubleshooting_help without error_message to list all known issues.",
+						serviceConfig.Title, errorMessage),
+				},
 			},
-		},
-	}, nil
+		}, nil
+	}
+
+	lookup := shared.TroubleshootingLookup{
+		ServiceName:        serviceConfig.ServiceName,
+		Status:             labelledStatus(d.configLoader, serviceConfig.SectionStatus(serviceConfig.Troubleshooting.Status)),
+		ErrorMessage:       errorMessage,
+		Matches:            matches,
+		DiagnosticCommands: serviceConfig.Troubleshooting.DiagnosticCommands,
+		LogLocations:       serviceConfig.Troubleshooting.LogLocations,
+		SupportResources:   serviceConfig.Troubleshooting.SupportResources,
+	}
+	return formatJSONResult(lookup, "troubleshooting matches")
 }
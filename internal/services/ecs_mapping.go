@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/ecs"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// maxFieldSuggestions bounds the similar field names offered when a lookup finds nothing
+const maxFieldSuggestions = 10
+
+// fieldDefinitionMatch is an integration's definition of a looked up field
+type fieldDefinitionMatch struct {
+	integration string
+	dataStream  string
+	field       shared.Field
+}
+
+// LookupField resolves a field name to its ECS definition and to the
+// integration data streams that define it, and reports whether the field is
+// ECS, integration-specific or conflicting in type with ECS. Without ECS
+// definitions the classification is reported as unknown
+func (i *IntegrationProvider) LookupField(fieldName, integrationName string) (shared.CallToolResult, error) {
+	ecsField, inECS, ecsErr := i.ecs.Lookup(fieldName)
+
+	matches, searched, err := i.fieldDefinitions(fieldName, integrationName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	if !inECS && len(matches) == 0 {
+		message := fmt.Sprintf("Field '%s' is not defined by ECS or by %s", fieldName, searched)
+		if ecsErr != nil {
+			message = fmt.Sprintf("Field '%s' is not defined by %s; ECS could not be checked: %v", fieldName, searched, ecsErr)
+		}
+		if suggestions := i.similarFields(fieldName, integrationName); len(suggestions) > 0 {
+			message += fmt.Sprintf(". Similar fields: %s", strings.Join(suggestions, ", "))
+		}
+		if integrationName == "" {
+			message += ". " + builtInSearchNote
+		}
+		return errorResult(message), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Field `%s`\n\n", fieldName))
+
+	var conflicts []string
+	for _, match := range matches {
+		if inECS && match.field.Type != "" && !ecs.SameFamily(match.field.Type, ecsField.Type) {
+			conflicts = append(conflicts, fmt.Sprintf("%s.%s maps it as %s", match.integration, match.dataStream, match.field.Type))
+		}
+	}
+	switch {
+	case len(conflicts) > 0:
+		result.WriteString(fmt.Sprintf("**Classification**: ECS field with conflicting types (ECS type is %s)\n", ecsField.Type))
+	case inECS:
+		result.WriteString("**Classification**: ECS field\n")
+	case ecsErr != nil:
+		result.WriteString("**Classification**: Unknown (ECS not checked)\n")
+	default:
+		result.WriteString("**Classification**: Integration-specific field\n")
+	}
+
+	result.WriteString("\n## ECS Definition\n\n")
+	switch {
+	case ecsErr != nil:
+		result.WriteString(fmt.Sprintf("Not checked: %v\n", ecsErr))
+	case !inECS:
+		result.WriteString(fmt.Sprintf("Not defined in ECS (%s).\n", i.ecs.Source()))
+	default:
+		result.WriteString(formatECSField(ecsField))
+	}
+
+	result.WriteString(fmt.Sprintf("\n## Integration Definitions\n\nSearched %s.\n\n", searched))
+	if integrationName == "" {
+		result.WriteString(builtInSearchNote + "\n\n")
+	}
+	if len(matches) == 0 {
+		result.WriteString("No integration defines this field.\n")
+	} else {
+		result.WriteString("| Integration | Data stream | Type | Description |\n|---|---|---|---|\n")
+		for _, match := range matches {
+			result.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				match.integration, match.dataStream, formatFieldType(match.field), tableCell(match.field.Description)))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		result.WriteString("\n## Conflicts\n" + formatList(conflicts))
+		result.WriteString(fmt.Sprintf("\nMap the field as %s, or rename it under the integration's own namespace.\n", ecsField.Type))
+	}
+
+	return textResult(result.String()), nil
+}
+
+// GetECSMappingReport classifies each field of an integration's data streams
+// as ECS, custom (integration-specific) or conflicting with ECS
+func (i *IntegrationProvider) GetECSMappingReport(integrationName, dataStream string) (shared.CallToolResult, error) {
+	ecsFields, err := i.ecs.Fields()
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	title, streams, source, err := i.dataStreams(integrationName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	streams, err = selectStreams(streams, integrationName, dataStream)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s ECS Mapping Report\n\n**Source**: %s\n**ECS**: %s\n", title, source, i.ecs.Source()))
+
+	for _, stream := range streams {
+		var ecsNames, custom, conflicts []string
+		for _, field := range stream.Fields {
+			ecsField, inECS := ecsFields[field.Name]
+			switch {
+			case !inECS && field.External == "ecs":
+				conflicts = append(conflicts, fmt.Sprintf("| %s | external: ecs | not defined in this ECS version |", field.Name))
+			case !inECS:
+				custom = append(custom, fmt.Sprintf("| %s | %s |", field.Name, field.Type))
+			case field.Type != "" && !ecs.SameFamily(field.Type, ecsField.Type):
+				conflicts = append(conflicts, fmt.Sprintf("| %s | %s | ECS type is %s |", field.Name, field.Type, ecsField.Type))
+			default:
+				ecsNames = append(ecsNames, fmt.Sprintf("| %s | %s |", field.Name, ecsField.Type))
+			}
+		}
+
+		result.WriteString(fmt.Sprintf("\n## %s (%s)\n\n%d ECS, %d custom, %d conflicting\n",
+			stream.Name, stream.Type, len(ecsNames), len(custom), len(conflicts)))
+		if len(conflicts) > 0 {
+			result.WriteString("\n### Conflicting\n\n| Field | Type | Problem |\n|---|---|---|\n" + strings.Join(conflicts, "\n") + "\n")
+		}
+		if len(ecsNames) > 0 {
+			result.WriteString("\n### ECS\n\n| Field | ECS type |\n|---|---|\n" + strings.Join(ecsNames, "\n") + "\n")
+		}
+		if len(custom) > 0 {
+			result.WriteString("\n### Custom\n\n| Field | Type |\n|---|---|\n" + strings.Join(custom, "\n") + "\n")
+		}
+	}
+
+	return textResult(result.String()), nil
+}
+
+// builtInSearchNote explains the scope of a field lookup without an integration
+const builtInSearchNote = "Without `integration`, only the built-in example integrations are searched, not the package tree; pass `integration` to search a package."
+
+// fieldDefinitions finds the data streams defining a field, either in one
+// integration or in the built-in example integrations, and describes what was searched
+func (i *IntegrationProvider) fieldDefinitions(fieldName, integrationName string) ([]fieldDefinitionMatch, string, error) {
+	var matches []fieldDefinitionMatch
+	if integrationName != "" {
+		_, streams, source, err := i.dataStreams(integrationName)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, stream := range streams {
+			for _, field := range stream.Fields {
+				if field.Name == fieldName {
+					matches = append(matches, fieldDefinitionMatch{integrationName, stream.Name, field})
+				}
+			}
+		}
+		return matches, fmt.Sprintf("%s (%s)", integrationName, source), nil
+	}
+
+	for _, name := range i.integrationNames() {
+		for _, stream := range i.integrations[name].DataStreams {
+			for _, field := range stream.Fields {
+				if field.Name == fieldName {
+					matches = append(matches, fieldDefinitionMatch{name, stream.Name, field})
+				}
+			}
+		}
+	}
+	return matches, fmt.Sprintf("the built-in example integrations (%s)", strings.Join(i.integrationNames(), ", ")), nil
+}
+
+// similarFields returns ECS and integration field names sharing the looked up
+// field's name or containing its last segment
+func (i *IntegrationProvider) similarFields(fieldName, integrationName string) []string {
+	segments := strings.Split(fieldName, ".")
+	last := segments[len(segments)-1]
+	similar := func(name string) bool {
+		return strings.HasPrefix(name, fieldName) || strings.HasSuffix(name, "."+last)
+	}
+
+	seen := make(map[string]bool)
+	if fields, err := i.ecs.Fields(); err == nil {
+		for name := range fields {
+			if similar(name) {
+				seen[name] = true
+			}
+		}
+	}
+	var streams []shared.IntegrationDataStream
+	if integrationName != "" {
+		_, streams, _, _ = i.dataStreams(integrationName)
+	} else {
+		for _, integration := range i.integrations {
+			streams = append(streams, integration.DataStreams...)
+		}
+	}
+	for _, stream := range streams {
+		for _, field := range stream.Fields {
+			if similar(field.Name) {
+				seen[field.Name] = true
+			}
+		}
+	}
+
+	names := sortedKeys(seen)
+	if len(names) > maxFieldSuggestions {
+		names = names[:maxFieldSuggestions]
+	}
+	return names
+}
+
+func formatECSField(field ecs.Field) string {
+	description := field.Short
+	if description == "" {
+		description = field.Description
+	}
+	text := fmt.Sprintf("- **Type**: %s\n- **Level**: %s\n- **Description**: %s\n",
+		field.Type, field.Level, strings.Join(strings.Fields(description), " "))
+	if field.Example != nil {
+		text += fmt.Sprintf("- **Example**: `%v`\n", field.Example)
+	}
+	return text
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/packages"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// GetDataStreamFields lists the fields of an integration's data streams,
+// optionally limited to one data stream, a field name prefix and a field type
+func (i *IntegrationProvider) GetDataStreamFields(integrationName, dataStream, prefix, fieldType string) (shared.CallToolResult, error) {
+	title, streams, source, err := i.dataStreams(integrationName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	streams, err = selectStreams(streams, integrationName, dataStream)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s Data Stream Fields\n\n**Source**: %s\n", title, source))
+	if prefix != "" || fieldType != "" {
+		result.WriteString(fmt.Sprintf("**Filter**: %s\n", formatFieldFilter(prefix, fieldType)))
+	}
+
+	for _, stream := range streams {
+		fields := filterFields(stream.Fields, prefix, fieldType)
+		result.WriteString(fmt.Sprintf("\n## %s (%s) - %d fields\n\n", stream.Name, stream.Type, len(fields)))
+		if len(fields) == 0 {
+			result.WriteString("No matching fields.\n")
+			continue
+		}
+		result.WriteString("| Field | Type | Description |\n|---|---|---|\n")
+		for _, field := range fields {
+			result.WriteString(fmt.Sprintf("| %s | %s | %s |\n", field.Name, formatFieldType(field), tableCell(field.Description)))
+		}
+	}
+
+	return textResult(result.String()), nil
+}
+
+// dataStreams returns an integration's title and data streams with a
+// description of where they came from
+func (i *IntegrationProvider) dataStreams(name string) (string, []shared.IntegrationDataStream, string, error) {
+	integration, source, err := i.integrationDetails(name)
+	if err != nil {
+		return "", nil, "", err
+	}
+	return integration.Title, integration.DataStreams, source, nil
+}
+
+// integrationDetails returns an integration's metadata and data streams with
+// a description of where they came from. Packages in the configured package
+// tree take precedence over the built-in integration details.
+func (i *IntegrationProvider) integrationDetails(name string) (shared.IntegrationDetails, string, error) {
+	if i.packages.Configured() {
+		pkg, err := i.packages.LoadPackage(name)
+		// Only a package missing from the tree falls back to the built-in
+		// examples; a package that fails to load is reported
+		if err != nil && !errors.Is(err, packages.ErrPackageNotFound) {
+			return shared.IntegrationDetails{}, "", err
+		}
+		if err == nil {
+			integration := shared.IntegrationDetails{
+				Name:            pkg.Name,
+				Title:           pkg.Title,
+				Description:     pkg.Description,
+				Version:         pkg.Version,
+				Categories:      pkg.Categories,
+				DataStreams:     i.packageDataStreams(pkg),
+				PolicyTemplates: packagePolicyTemplates(pkg),
+			}
+			return integration, "package tree at " + pkg.Dir, nil
+		}
+	}
+
+	integration, exists := i.integrations[strings.ToLower(name)]
+	if !exists {
+		message := fmt.Sprintf("Integration '%s' not found. Available integrations: %s", name, strings.Join(i.integrationNames(), ", "))
+		if !i.packages.Configured() {
+			message += fmt.Sprintf(". Set %s to a local integrations checkout to read any package", packages.PackagesDirEnv)
+		}
+		return shared.IntegrationDetails{}, "", &integrationNotFoundError{message}
+	}
+	return integration, fmt.Sprintf("built-in examples (set %s to read complete field definitions)", packages.PackagesDirEnv), nil
+}
+
+// integrationNotFoundError reports an integration that is neither in the
+// package tree nor built in, as opposed to a package that fails to load
+type integrationNotFoundError struct {
+	message string
+}
+
+func (e *integrationNotFoundError) Error() string {
+	return e.message
+}
+
+// selectStreams returns the named data stream, or all of them when no name is given
+func selectStreams(streams []shared.IntegrationDataStream, integrationName, dataStream string) ([]shared.IntegrationDataStream, error) {
+	if dataStream == "" {
+		return streams, nil
+	}
+	var names []string
+	for _, stream := range streams {
+		if stream.Name == dataStream {
+			return []shared.IntegrationDataStream{stream}, nil
+		}
+		names = append(names, stream.Name)
+	}
+	return nil, fmt.Errorf("data stream '%s' not found in %s. Available data streams: %s",
+		dataStream, integrationName, strings.Join(names, ", "))
+}
+
+// packageDataStreams converts a package's data streams to shared types.
+// Fields declared with external: ecs have no type in the package; when ECS
+// is configured, they take their type and description from ECS.
+func (i *IntegrationProvider) packageDataStreams(pkg *packages.Package) []shared.IntegrationDataStream {
+	streams := make([]shared.IntegrationDataStream, 0, len(pkg.DataStreams))
+	for _, dataStream := range pkg.DataStreams {
+		stream := shared.IntegrationDataStream{
+			Name:        dataStream.Name,
+			Type:        dataStream.Type,
+			Description: dataStream.Title,
+			SampleEvent: dataStream.SampleEvent,
+		}
+		for _, field := range dataStream.Fields {
+			sharedField := shared.Field{
+				Name:        field.Name,
+				Type:        field.Type,
+				Description: field.Description,
+				Example:     field.Example,
+				External:    field.External,
+			}
+			if field.Type == "" && field.External == "ecs" && i.ecs.Configured() {
+				if ecsField, inECS, err := i.ecs.Lookup(field.Name); err == nil && inECS {
+					sharedField.Type = ecsField.Type
+					if sharedField.Description == "" {
+						sharedField.Description = ecsField.Short
+					}
+				}
+			}
+			stream.Fields = append(stream.Fields, sharedField)
+		}
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+// packagePolicyTemplates converts a package's policy templates to shared
+// types. Templates that list no data streams get all of the package's.
+func packagePolicyTemplates(pkg *packages.Package) []shared.PolicyTemplate {
+	templates := make([]shared.PolicyTemplate, 0, len(pkg.PolicyTemplates))
+	for _, packageTemplate := range pkg.PolicyTemplates {
+		template := shared.PolicyTemplate{
+			Name:        packageTemplate.Name,
+			Title:       packageTemplate.Title,
+			Description: packageTemplate.Description,
+			DataStreams: packageTemplate.DataStreams,
+			Categories:  packageTemplate.Categories,
+		}
+		if len(template.DataStreams) == 0 {
+			template.DataStreams = pkg.DataStreamNames()
+		}
+		for _, packageInput := range packageTemplate.Inputs {
+			input := shared.Input{
+				Type:        packageInput.Type,
+				Title:       packageInput.Title,
+				Description: packageInput.Description,
+			}
+			for _, variable := range packageInput.Vars {
+				input.Vars = append(input.Vars, shared.Variable{
+					Name:        variable.Name,
+					Type:        variable.Type,
+					Title:       variable.Title,
+					Description: variable.Description,
+					Required:    variable.Required,
+					Default:     variable.Default,
+					Multi:       variable.Multi,
+					Secret:      variable.Secret,
+				})
+			}
+			template.Inputs = append(template.Inputs, input)
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+func filterFields(fields []shared.Field, prefix, fieldType string) []shared.Field {
+	var filtered []shared.Field
+	for _, field := range fields {
+		if prefix != "" && !strings.HasPrefix(field.Name, prefix) {
+			continue
+		}
+		if fieldType != "" && !strings.EqualFold(field.Type, fieldType) {
+			continue
+		}
+		filtered = append(filtered, field)
+	}
+	sort.SliceStable(filtered, func(a, b int) bool {
+		return filtered[a].Name < filtered[b].Name
+	})
+	return filtered
+}
+
+func formatFieldFilter(prefix, fieldType string) string {
+	var parts []string
+	if prefix != "" {
+		parts = append(parts, fmt.Sprintf("prefix `%s`", prefix))
+	}
+	if fieldType != "" {
+		parts = append(parts, fmt.Sprintf("type `%s`", fieldType))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatFieldType(field shared.Field) string {
+	switch {
+	case field.Type == "" && field.External != "":
+		return "(" + field.External + ")"
+	case field.External != "":
+		return fmt.Sprintf("%s (%s)", field.Type, field.External)
+	default:
+		return field.Type
+	}
+}
+
+// tableCell makes text safe for a single markdown table cell
+func tableCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", "\\|")
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGetDataStreamFields(t *testing.T) {
+	provider := newTestIntegrationProvider(t, map[string]string{
+		"demo/manifest.yml":                           "name: demo\ntitle: Demo\n",
+		"demo/data_stream/access/manifest.yml":        "title: Access logs\n",
+		"demo/data_stream/access/fields/fields.yml":   "- name: demo.access\n  type: group\n  fields:\n    - name: bytes\n      type: long\n    - name: path\n      type: keyword\n- name: source.ip\n  external: ecs\n",
+		"demo/data_stream/status/manifest.yml":        "title: Status\ntype: metrics\n",
+		"demo/data_stream/status/fields/fields.yml":   "- name: demo.status.active\n  type: long\n",
+		"broken/manifest.yml":                         "name: broken\n",
+		"broken/data_stream/events/fields/fields.yml": "- name: [\n",
+	})
+	rowPattern := regexp.MustCompile(`(?m)^\| ([^ |]+) \| ([^|]+) \|`)
+
+	tests := []struct {
+		name       string
+		dataStream string
+		prefix     string
+		fieldType  string
+		want       []string
+		err        string
+	}{
+		{
+			name: "all data streams",
+			want: []string{"demo.access.bytes long", "demo.access.path keyword", "source.ip (ecs)", "demo.status.active long"},
+		},
+		{
+			name:       "one data stream",
+			dataStream: "access",
+			want:       []string{"demo.access.bytes long", "demo.access.path keyword", "source.ip (ecs)"},
+		},
+		{
+			name:   "prefix",
+			prefix: "demo.access.",
+			want:   []string{"demo.access.bytes long", "demo.access.path keyword"},
+		},
+		{
+			name:      "type ignores case",
+			fieldType: "LONG",
+			want:      []string{"demo.access.bytes long", "demo.status.active long"},
+		},
+		{
+			name:       "unknown data stream",
+			dataStream: "error",
+			err:        "data stream 'error' not found in demo. Available data streams: access, status",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := provider.GetDataStreamFields("demo", test.dataStream, test.prefix, test.fieldType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].Text
+			if test.err != "" {
+				if !result.IsError || text != test.err {
+					t.Errorf("GetDataStreamFields = %q, want error %q", text, test.err)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("GetDataStreamFields failed: %s", text)
+			}
+
+			var got []string
+			for _, row := range rowPattern.FindAllStringSubmatch(text, -1) {
+				if row[1] != "Field" {
+					got = append(got, row[1]+" "+strings.TrimSpace(row[2]))
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("fields = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	result, err := provider.GetDataStreamFields("broken", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "package broken: data stream events") {
+		t.Errorf("GetDataStreamFields(broken) = %q, want the load error", result.Content[0].Text)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/ingest"
+	"elastic-integration-docs-mcp/internal/packages"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// defaultPipeline is the pipeline Fleet installs as a data stream's default pipeline
+const defaultPipeline = "default"
+
+// GetIngestPipelines lists the processors of the ingest pipelines of an
+// integration's data streams
+func (i *IntegrationProvider) GetIngestPipelines(integrationName, dataStream string) (shared.CallToolResult, error) {
+	pkg, err := i.loadPackage(integrationName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	streams, err := selectPackageStreams(pkg, dataStream)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s Ingest Pipelines\n\n**Source**: package tree at %s\n", pkg.Title, pkg.Dir))
+
+	for _, stream := range streams {
+		result.WriteString(fmt.Sprintf("\n## %s (%s)\n", stream.Name, stream.Type))
+		if len(stream.Pipelines) == 0 {
+			result.WriteString("\nNo ingest pipelines; documents are indexed as the agent sends them.\n")
+			continue
+		}
+
+		for _, pipeline := range stream.Pipelines {
+			result.WriteString(fmt.Sprintf("\n### %s\n\n", pipeline.Name))
+			if pipeline.Description != "" {
+				result.WriteString(pipeline.Description + "\n\n")
+			}
+			result.WriteString(formatProcessors(pipeline.Processors))
+			if len(pipeline.OnFailure) > 0 {
+				result.WriteString("\n**On failure**:\n\n" + formatProcessors(pipeline.OnFailure))
+			}
+		}
+	}
+
+	result.WriteString(fmt.Sprintf("\nUse simulate_pipeline to run a sample log line through a pipeline. Simulated processors: %s.\n",
+		strings.Join(ingest.SupportedProcessors(), ", ")))
+	return textResult(result.String()), nil
+}
+
+// SimulatePipeline runs a sample log line, or a JSON document, through a data
+// stream's ingest pipeline and reports the resulting document and which
+// processor failed
+func (i *IntegrationProvider) SimulatePipeline(integrationName, dataStream, pipelineName, message, document string) (shared.CallToolResult, error) {
+	pkg, err := i.loadPackage(integrationName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	stream, exists := pkg.DataStream(dataStream)
+	if !exists {
+		return errorResult(fmt.Sprintf("data stream '%s' not found in %s. Available data streams: %s",
+			dataStream, pkg.Name, strings.Join(pkg.DataStreamNames(), ", "))), nil
+	}
+
+	if pipelineName == "" {
+		pipelineName = defaultPipeline
+	}
+	var pipeline *ingest.Pipeline
+	var names []string
+	for _, candidate := range stream.Pipelines {
+		names = append(names, candidate.Name)
+		if candidate.Name == pipelineName {
+			pipeline = candidate
+		}
+	}
+	if pipeline == nil {
+		if len(names) == 0 {
+			return errorResult(fmt.Sprintf("data stream '%s' of %s has no ingest pipelines", dataStream, pkg.Name)), nil
+		}
+		return errorResult(fmt.Sprintf("pipeline '%s' not found in %s.%s. Available pipelines: %s",
+			pipelineName, pkg.Name, dataStream, strings.Join(names, ", "))), nil
+	}
+
+	input := make(ingest.Document)
+	if document != "" {
+		if err := json.Unmarshal([]byte(document), &input); err != nil {
+			return errorResult(fmt.Sprintf("document must be a JSON object: %v", err)), nil
+		}
+	}
+	if message != "" {
+		input["message"] = message
+	}
+	if len(input) == 0 {
+		return errorResult("message or document is required"), nil
+	}
+
+	simulation := ingest.NewSimulator(stream.Pipelines).Simulate(pipeline, input)
+	output, err := formatJSON(simulation.Document)
+	if err != nil {
+		return shared.CallToolResult{}, fmt.Errorf("failed to format simulated document: %v", err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Pipeline Simulation: %s.%s/%s\n\n", pkg.Name, dataStream, pipeline.Name))
+	switch {
+	case simulation.Failure == nil:
+		result.WriteString("**Result**: all simulated processors succeeded\n")
+	case simulation.Handled:
+		result.WriteString(fmt.Sprintf("**Result**: processor %s failed; the pipeline's on_failure handlers ran\n", formatFailedProcessor(simulation.Failure)))
+	default:
+		result.WriteString(fmt.Sprintf("**Result**: processor %s failed and the document would be rejected\n", formatFailedProcessor(simulation.Failure)))
+	}
+	if simulation.Failure != nil {
+		result.WriteString(fmt.Sprintf("**Error**: %s\n", simulation.Failure.Message))
+	}
+
+	result.WriteString("\n## Processors\n\n| # | Processor | Tag | Status | Details |\n|---|---|---|---|---|\n")
+	var unsupported int
+	for _, step := range simulation.Trace {
+		if step.Status == "unsupported" {
+			unsupported++
+		}
+		result.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", step.Position, step.Type, step.Tag, step.Status, tableCell(step.Message)))
+	}
+
+	result.WriteString(fmt.Sprintf("\n## Resulting Document\n\n```json\n%s\n```\n", output))
+	if unsupported > 0 {
+		result.WriteString(fmt.Sprintf("\n%d processor(s) are not simulated, so their changes are missing from the document. Use the Elasticsearch _simulate API for an exact result.\n", unsupported))
+	}
+	return textResult(result.String()), nil
+}
+
+// loadPackage reads an integration's package from the configured package tree
+func (i *IntegrationProvider) loadPackage(name string) (*packages.Package, error) {
+	if !i.packages.Configured() {
+		return nil, fmt.Errorf("ingest pipelines are read from integration packages; set %s to a local integrations checkout", packages.PackagesDirEnv)
+	}
+	return i.packages.LoadPackage(name)
+}
+
+// selectPackageStreams returns the named data stream of a package, or all of them when no name is given
+func selectPackageStreams(pkg *packages.Package, dataStream string) ([]packages.DataStream, error) {
+	if dataStream == "" {
+		return pkg.DataStreams, nil
+	}
+	stream, exists := pkg.DataStream(dataStream)
+	if !exists {
+		return nil, fmt.Errorf("data stream '%s' not found in %s. Available data streams: %s",
+			dataStream, pkg.Name, strings.Join(pkg.DataStreamNames(), ", "))
+	}
+	return []packages.DataStream{*stream}, nil
+}
+
+func formatProcessors(processors []ingest.Processor) string {
+	var result strings.Builder
+	result.WriteString("| # | Processor | Details | Tag | Condition |\n|---|---|---|---|---|\n")
+	for index, processor := range processors {
+		result.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n", index+1, processor.Type,
+			tableCell(processor.Summary()), processor.Tag(), tableCell(processor.Condition())))
+	}
+	return result.String()
+}
+
+func formatFailedProcessor(step *ingest.ProcessorResult) string {
+	text := fmt.Sprintf("#%s (%s", step.Position, step.Type)
+	if step.Tag != "" {
+		text += ", tag " + step.Tag
+	}
+	return text + ")"
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// defaultIngestionWindow is how far back documents count as recent
+const defaultIngestionWindow = "15m"
+
+// ingestionWindowPattern matches the windows accepted as Elasticsearch date math
+var ingestionWindowPattern = regexp.MustCompile(`^\d+[smhd]$`)
+
+// ElasticsearchConnection holds the endpoint and credentials used to query Elasticsearch
+type ElasticsearchConnection struct {
+	URL                string
+	APIKey             string
+	Username           string
+	Password           string
+	InsecureSkipVerify bool
+}
+
+// IngestionProvider checks that integration data arrived in Elasticsearch
+type IngestionProvider struct {
+	integrations *IntegrationProvider
+	// Client is used for Elasticsearch requests; tests can point it at an httptest server
+	Client *http.Client
+}
+
+func NewIngestionProvider() *IngestionProvider {
+	return &IngestionProvider{
+		integrations: NewIntegrationProvider(),
+		Client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ingestionSearchResponse is the part of a _search response used by the report
+type ingestionSearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+	} `json:"hits"`
+	Aggregations struct {
+		Recent        ingestionCount `json:"recent"`
+		Errors        ingestionCount `json:"errors"`
+		IngestFailure ingestionCount `json:"ingest_failures"`
+		LastTimestamp struct {
+			Value         *float64 `json:"value"`
+			ValueAsString string   `json:"value_as_string"`
+		} `json:"last_timestamp"`
+	} `json:"aggregations"`
+}
+
+type ingestionCount struct {
+	DocCount int64 `json:"doc_count"`
+}
+
+// CheckDataIngestion queries logs-<dataset>-* or metrics-<dataset>-* for each
+// of an integration's data streams and reports document counts, the last
+// timestamp, and documents carrying error.message or ingest pipeline failures
+func (p *IngestionProvider) CheckDataIngestion(integrationName string, dataStreams []string, window string, connection ElasticsearchConnection) (shared.CallToolResult, error) {
+	integration, exists := p.integrations.integrations[strings.ToLower(integrationName)]
+	if !exists {
+		return errorResult(fmt.Sprintf("Integration '%s' not found. Available integrations: %s",
+			integrationName, strings.Join(p.integrations.integrationNames(), ", "))), nil
+	}
+
+	if window == "" {
+		window = defaultIngestionWindow
+	}
+	if !ingestionWindowPattern.MatchString(window) {
+		return errorResult(fmt.Sprintf("invalid window '%s'; use a number followed by s, m, h or d, such as 15m", window)), nil
+	}
+	if parsed, err := url.Parse(connection.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errorResult(fmt.Sprintf("invalid Elasticsearch URL '%s'; use an http:// or https:// URL such as https://localhost:9200", connection.URL)), nil
+	}
+
+	streams := integration.DataStreams
+	if len(dataStreams) > 0 {
+		streams = nil
+		for _, name := range dataStreams {
+			if !containsDataStream(integration, name) {
+				return errorResult(fmt.Sprintf("data stream '%s' not found in %s", name, integration.Name)), nil
+			}
+			streams = append(streams, findDataStream(integration, name))
+		}
+	}
+
+	var rows []string
+	var problems []string
+	for _, stream := range streams {
+		dataset := datasetName(integration.Name, stream.Name)
+		index := fmt.Sprintf("%s-%s-*", stream.Type, dataset)
+
+		response, err := p.search(connection, index, window)
+		if err != nil {
+			return errorResult(fmt.Sprintf("Failed to query %s: %v", index, err)), nil
+		}
+
+		aggs := response.Aggregations
+		lastTimestamp := "never"
+		if aggs.LastTimestamp.Value != nil {
+			lastTimestamp = aggs.LastTimestamp.ValueAsString
+		}
+
+		status := "OK"
+		switch {
+		case response.Hits.Total.Value == 0:
+			status = "NO DATA"
+			problems = append(problems, fmt.Sprintf("%s: no documents in %s", dataset, index))
+		case aggs.Recent.DocCount == 0:
+			status = "STALE"
+			problems = append(problems, fmt.Sprintf("%s: no documents in the last %s (last document: %s)", dataset, window, lastTimestamp))
+		case aggs.IngestFailure.DocCount > 0 || aggs.Errors.DocCount > 0:
+			status = "ERRORS"
+			problems = append(problems, fmt.Sprintf("%s: %d recent documents have error.message set, %d failed in the ingest pipeline",
+				dataset, aggs.Errors.DocCount, aggs.IngestFailure.DocCount))
+		}
+
+		rows = append(rows, fmt.Sprintf("| %s | `%s` | %d | %d | %s | %d | %d | %s |",
+			dataset, index, response.Hits.Total.Value, aggs.Recent.DocCount, lastTimestamp,
+			aggs.Errors.DocCount, aggs.IngestFailure.DocCount, status))
+	}
+
+	report := fmt.Sprintf(`# %s Data Ingestion
+
+| Data stream | Index pattern | Documents | Last %s | Last document | Errors | Pipeline failures | Status |
+|---|---|---|---|---|---|---|---|
+%s
+`, integration.Title, window, strings.Join(rows, "\n"))
+
+	if len(problems) > 0 {
+		report += fmt.Sprintf("\n## Problems\n%s\nUse get_troubleshooting_help with the service name for common causes such as agent enrollment, input configuration and permissions.\n", formatList(problems))
+	} else {
+		report += fmt.Sprintf("\nAll data streams received documents in the last %s without errors.\n", window)
+	}
+	return textResult(report), nil
+}
+
+// search runs the ingestion query against an index pattern. Missing indices count as no documents.
+func (p *IngestionProvider) search(connection ElasticsearchConnection, index, window string) (*ingestionSearchResponse, error) {
+	query := map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"aggs": map[string]interface{}{
+			"recent": map[string]interface{}{
+				"filter": map[string]interface{}{"range": map[string]interface{}{"@timestamp": map[string]interface{}{"gte": "now-" + window}}},
+			},
+			"errors": map[string]interface{}{
+				"filter": map[string]interface{}{"bool": map[string]interface{}{
+					"filter": []interface{}{
+						map[string]interface{}{"range": map[string]interface{}{"@timestamp": map[string]interface{}{"gte": "now-" + window}}},
+						map[string]interface{}{"exists": map[string]interface{}{"field": "error.message"}},
+					},
+				}},
+			},
+			"ingest_failures": map[string]interface{}{
+				"filter": map[string]interface{}{"bool": map[string]interface{}{
+					"filter": []interface{}{
+						map[string]interface{}{"range": map[string]interface{}{"@timestamp": map[string]interface{}{"gte": "now-" + window}}},
+						map[string]interface{}{"term": map[string]interface{}{"event.kind": "pipeline_error"}},
+					},
+				}},
+			},
+			"last_timestamp": map[string]interface{}{
+				"max": map[string]interface{}{"field": "@timestamp"},
+			},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/_search?ignore_unavailable=true&allow_no_indices=true",
+		strings.TrimSuffix(connection.URL, "/"), index)
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	switch {
+	case connection.APIKey != "":
+		request.Header.Set("Authorization", "ApiKey "+connection.APIKey)
+	case connection.Username != "":
+		request.SetBasicAuth(connection.Username, connection.Password)
+	}
+
+	client := p.Client
+	if connection.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client = &http.Client{Timeout: p.Client.Timeout, Transport: transport}
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Elasticsearch returned %s: %s", response.Status, strings.TrimSpace(string(data)))
+	}
+
+	var result ingestionSearchResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Elasticsearch response: %v", err)
+	}
+	return &result, nil
+}
+
+func containsDataStream(integration shared.IntegrationDetails, name string) bool {
+	for _, stream := range integration.DataStreams {
+		if stream.Name == name {
+			return true
+		}
+	}
+	return false
+}
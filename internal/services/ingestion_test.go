@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeSearchResponse builds a _search response with the aggregations the
+// ingestion check reads
+func fakeSearchResponse(total, recent, errors, ingestFailures int64, lastTimestamp string) string {
+	last := map[string]interface{}{"value": nil}
+	if lastTimestamp != "" {
+		last = map[string]interface{}{"value": 1.7e12, "value_as_string": lastTimestamp}
+	}
+	response := map[string]interface{}{
+		"hits": map[string]interface{}{"total": map[string]interface{}{"value": total}},
+		"aggregations": map[string]interface{}{
+			"recent":          map[string]interface{}{"doc_count": recent},
+			"errors":          map[string]interface{}{"doc_count": errors},
+			"ingest_failures": map[string]interface{}{"doc_count": ingestFailures},
+			"last_timestamp":  last,
+		},
+	}
+	data, _ := json.Marshal(response)
+	return string(data)
+}
+
+// fakeElasticsearch serves one response for every _search request and
+// records the requests it received
+type fakeElasticsearch struct {
+	status   int
+	body     string
+	requests []*http.Request
+	bodies   []string
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, string(body))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.status)
+	io.WriteString(w, f.body)
+}
+
+func newTestIngestionProvider(t *testing.T, fake *fakeElasticsearch) (*IngestionProvider, string) {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	provider := NewIngestionProvider()
+	provider.Client = server.Client()
+	return provider, server.URL
+}
+
+func TestCheckDataIngestionStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		row      string
+		problem  string
+	}{
+		{
+			name:     "ok",
+			response: fakeSearchResponse(120, 12, 0, 0, "2025-10-10T13:55:36.000Z"),
+			row:      "| nginx.access | `logs-nginx.access-*` | 120 | 12 | 2025-10-10T13:55:36.000Z | 0 | 0 | OK |",
+		},
+		{
+			name:     "no data",
+			response: fakeSearchResponse(0, 0, 0, 0, ""),
+			row:      "| nginx.access | `logs-nginx.access-*` | 0 | 0 | never | 0 | 0 | NO DATA |",
+			problem:  "nginx.access: no documents in logs-nginx.access-*",
+		},
+		{
+			name:     "stale",
+			response: fakeSearchResponse(40, 0, 0, 0, "2025-10-01T00:00:00.000Z"),
+			row:      "| nginx.access | `logs-nginx.access-*` | 40 | 0 | 2025-10-01T00:00:00.000Z | 0 | 0 | STALE |",
+			problem:  "nginx.access: no documents in the last 1h (last document: 2025-10-01T00:00:00.000Z)",
+		},
+		{
+			name:     "errors",
+			response: fakeSearchResponse(50, 10, 3, 2, "2025-10-10T13:55:36.000Z"),
+			row:      "| nginx.access | `logs-nginx.access-*` | 50 | 10 | 2025-10-10T13:55:36.000Z | 3 | 2 | ERRORS |",
+			problem:  "nginx.access: 3 recent documents have error.message set, 2 failed in the ingest pipeline",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeElasticsearch{status: http.StatusOK, body: test.response}
+			provider, url := newTestIngestionProvider(t, fake)
+
+			result, err := provider.CheckDataIngestion("nginx", []string{"access"}, "1h", ElasticsearchConnection{URL: url + "/"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].Text
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", text)
+			}
+			if !strings.Contains(text, test.row) {
+				t.Errorf("report does not contain row %q:\n%s", test.row, text)
+			}
+			if test.problem == "" && !strings.Contains(text, "All data streams received documents in the last 1h") {
+				t.Errorf("report does not say all data streams are fine:\n%s", text)
+			}
+			if test.problem != "" && !strings.Contains(text, "- "+test.problem) {
+				t.Errorf("report does not list problem %q:\n%s", test.problem, text)
+			}
+
+			if len(fake.requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(fake.requests))
+			}
+			request := fake.requests[0]
+			if request.Method != http.MethodPost || request.URL.Path != "/logs-nginx.access-*/_search" {
+				t.Errorf("request = %s %s, want POST /logs-nginx.access-*/_search", request.Method, request.URL.Path)
+			}
+			if query := request.URL.Query(); query.Get("ignore_unavailable") != "true" || query.Get("allow_no_indices") != "true" {
+				t.Errorf("query = %s, want ignore_unavailable and allow_no_indices", request.URL.RawQuery)
+			}
+			if !strings.Contains(fake.bodies[0], `"gte":"now-1h"`) {
+				t.Errorf("search body does not use the window: %s", fake.bodies[0])
+			}
+		})
+	}
+}
+
+func TestCheckDataIngestionAllDataStreams(t *testing.T) {
+	fake := &fakeElasticsearch{status: http.StatusOK, body: fakeSearchResponse(1, 1, 0, 0, "2025-10-10T13:55:36.000Z")}
+	provider, url := newTestIngestionProvider(t, fake)
+
+	if _, err := provider.CheckDataIngestion("nginx", nil, "", ElasticsearchConnection{URL: url}); err != nil {
+		t.Fatal(err)
+	}
+	integration := provider.integrations.integrations["nginx"]
+	if len(fake.requests) != len(integration.DataStreams) {
+		t.Errorf("got %d requests, want one per data stream (%d)", len(fake.requests), len(integration.DataStreams))
+	}
+	if !strings.Contains(fake.bodies[0], `"gte":"now-`+defaultIngestionWindow+`"`) {
+		t.Errorf("search body does not use the default window: %s", fake.bodies[0])
+	}
+}
+
+func TestCheckDataIngestionAuthentication(t *testing.T) {
+	tests := []struct {
+		name       string
+		connection ElasticsearchConnection
+		want       string
+	}{
+		{name: "api key", connection: ElasticsearchConnection{APIKey: "a2V5", Username: "elastic", Password: "changeme"}, want: "ApiKey a2V5"},
+		{name: "basic", connection: ElasticsearchConnection{Username: "elastic", Password: "changeme"}, want: "Basic ZWxhc3RpYzpjaGFuZ2VtZQ=="},
+		{name: "none", connection: ElasticsearchConnection{}, want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeElasticsearch{status: http.StatusOK, body: fakeSearchResponse(1, 1, 0, 0, "2025-10-10T13:55:36.000Z")}
+			provider, url := newTestIngestionProvider(t, fake)
+			test.connection.URL = url
+
+			if _, err := provider.CheckDataIngestion("nginx", []string{"access"}, "15m", test.connection); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.requests[0].Header.Get("Authorization"); got != test.want {
+				t.Errorf("Authorization = %q, want %q", got, test.want)
+			}
+			if got := fake.requests[0].Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+		})
+	}
+}
+
+func TestCheckDataIngestionFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		integration string
+		dataStreams []string
+		window      string
+		url         string
+		wantErr     string
+	}{
+		{
+			name:    "non-200 response",
+			status:  http.StatusUnauthorized,
+			body:    `{"error":"missing authentication credentials"}`,
+			wantErr: `Failed to query logs-nginx.access-*: Elasticsearch returned 401 Unauthorized: {"error":"missing authentication credentials"}`,
+		},
+		{
+			name:    "invalid response",
+			status:  http.StatusOK,
+			body:    "<html>",
+			wantErr: "failed to parse Elasticsearch response",
+		},
+		{name: "unknown integration", integration: "nope", wantErr: "Integration 'nope' not found"},
+		{name: "unknown data stream", dataStreams: []string{"nope"}, wantErr: "data stream 'nope' not found in nginx"},
+		{name: "invalid window", window: "15 minutes", wantErr: "invalid window '15 minutes'"},
+		{name: "URL without a scheme", url: "localhost:9200", wantErr: "invalid Elasticsearch URL 'localhost:9200'"},
+		{name: "URL with another scheme", url: "ftp://localhost:9200", wantErr: "invalid Elasticsearch URL"},
+		{name: "URL without a host", url: "http://", wantErr: "invalid Elasticsearch URL"},
+		{name: "empty URL", url: "-", wantErr: "invalid Elasticsearch URL ''"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeElasticsearch{status: test.status, body: test.body}
+			provider, url := newTestIngestionProvider(t, fake)
+			switch test.url {
+			case "":
+			case "-":
+				url = ""
+			default:
+				url = test.url
+			}
+			integration := test.integration
+			if integration == "" {
+				integration = "nginx"
+			}
+			dataStreams := test.dataStreams
+			if dataStreams == nil {
+				dataStreams = []string{"access"}
+			}
+
+			result, err := provider.CheckDataIngestion(integration, dataStreams, test.window, ElasticsearchConnection{URL: url})
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].Text
+			if !result.IsError || !strings.Contains(text, test.wantErr) {
+				t.Errorf("result = %q (error: %v), want an error containing %q", text, result.IsError, test.wantErr)
+			}
+		})
+	}
+}
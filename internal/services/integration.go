@@ -4,16 +4,22 @@ import (
 	"fmt"
 	"strings"
 
+	"elastic-integration-docs-mcp/internal/ecs"
+	"elastic-integration-docs-mcp/internal/packages"
 	"elastic-integration-docs-mcp/internal/shared"
 )
 
 type IntegrationProvider struct {
 	integrations map[string]shared.IntegrationDetails
+	packages     *packages.Loader
+	ecs          *ecs.Schema
 }
 
 func NewIntegrationProvider() *IntegrationProvider {
 	provider := &IntegrationProvider{
 		integrations: make(map[string]shared.IntegrationDetails),
+		packages:     packages.NewLoaderFromEnv(),
+		ecs:          ecs.NewSchemaFromEnv(),
 	}
 	provider.initializeIntegrations()
 	return provider
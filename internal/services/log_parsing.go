@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/ingest"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// maxLineContext bounds the text shown around a failure position
+const maxLineContext = 60
+
+// LogParsingRequest describes a grok or dissect pattern to test against sample lines
+type LogParsingRequest struct {
+	// Processor is "grok" or "dissect"
+	Processor string
+	Pattern   string
+	Lines     []string
+	// PatternDefinitions are custom grok patterns, as in the grok processor's pattern_definitions
+	PatternDefinitions map[string]string
+	// AppendSeparator joins dissect append keys, as in the dissect processor
+	AppendSeparator string
+}
+
+// TestLogParsing runs a grok or dissect pattern against sample log lines and
+// returns the captured fields of each line, or the position where matching failed
+func (v *ValidationProvider) TestLogParsing(request LogParsingRequest) (shared.CallToolResult, error) {
+	if len(request.Lines) == 0 {
+		return errorResult("at least one sample line is required"), nil
+	}
+
+	var title string
+	var match func(line string) (map[string]interface{}, string, error)
+	switch request.Processor {
+	case "grok", "":
+		title = "Grok"
+		grok, err := ingest.CompileGrok(request.Pattern, request.PatternDefinitions)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		match = func(line string) (map[string]interface{}, string, error) {
+			if captures, matched := grok.Match(line); matched {
+				return captures, "", nil
+			}
+			explanation, err := explainGrokMismatch(request.Pattern, request.PatternDefinitions, line)
+			return nil, explanation, err
+		}
+	case "dissect":
+		title = "Dissect"
+		dissect, err := ingest.CompileDissect(request.Pattern, request.AppendSeparator)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		match = func(line string) (map[string]interface{}, string, error) {
+			values, err := dissect.Match(line)
+			var dissectErr *ingest.DissectError
+			if errors.As(err, &dissectErr) {
+				return nil, explainDissectMismatch(dissectErr), nil
+			}
+			return values, "", err
+		}
+	default:
+		return errorResult(fmt.Sprintf("unsupported processor '%s'; use grok or dissect", request.Processor)), nil
+	}
+
+	var result strings.Builder
+	var matchedLines int
+	for index, line := range request.Lines {
+		fields, explanation, err := match(line)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		if fields == nil {
+			result.WriteString(fmt.Sprintf("\n## Line %d: no match\n\n```\n%s\n```\n\n%s\n", index+1, line, explanation))
+			continue
+		}
+
+		matchedLines++
+		document := make(ingest.Document)
+		if err := document.SetAll(fields); err != nil {
+			return errorResult(fmt.Sprintf("line %d: %v", index+1, err)), nil
+		}
+		formatted, err := formatJSON(document)
+		if err != nil {
+			return shared.CallToolResult{}, fmt.Errorf("failed to format captured fields: %v", err)
+		}
+		result.WriteString(fmt.Sprintf("\n## Line %d: matched\n\n```json\n%s\n```\n", index+1, formatted))
+	}
+
+	header := fmt.Sprintf("# %s Pattern Test\n\n**Pattern**: `%s`\n**Result**: %d of %d lines matched\n",
+		title, request.Pattern, matchedLines, len(request.Lines))
+	return textResult(header + result.String()), nil
+}
+
+// explainGrokMismatch describes how far a line matched a grok expression
+func explainGrokMismatch(pattern string, definitions map[string]string, line string) (string, error) {
+	mismatch, err := ingest.DiagnoseGrok(pattern, definitions, line)
+	if err != nil || mismatch == nil {
+		return "The expression has top-level alternatives (`|`); test each alternative separately to find where matching fails.", err
+	}
+	if mismatch.Matched == "" {
+		return fmt.Sprintf("The first element of the expression, `%s`, does not match anywhere in the line.", mismatch.Next), nil
+	}
+	if mismatch.Next == "" {
+		return "Every element matched separately, but not together; check greedy elements such as DATA and GREEDYDATA.", nil
+	}
+	return fmt.Sprintf("`%s` matched `%s`, up to column %d. Matching failed at `%s`:\n\n```\n%s\n```\n\nRemaining expression: `%s`",
+		mismatch.Matched, mismatch.MatchedText, mismatch.Offset+1, mismatch.Next,
+		pointAt(line, mismatch.Offset), mismatch.Remaining), nil
+}
+
+// explainDissectMismatch describes which delimiter a dissect pattern could not find
+func explainDissectMismatch(err *ingest.DissectError) string {
+	if err.Key == "" {
+		return fmt.Sprintf("The line does not start with the pattern's leading text `%s`.", err.Delimiter)
+	}
+	return fmt.Sprintf("`%s` expects the delimiter `%s` at or after column %d, but the rest of the line does not contain it:\n\n```\n%s\n```",
+		err.Key, err.Delimiter, err.Offset+1, pointAt(err.Source, err.Offset))
+}
+
+// pointAt shows the text around a position of a line with a caret under it
+func pointAt(line string, offset int) string {
+	start, end := 0, len(line)
+	prefix, suffix := "", ""
+	if offset > maxLineContext {
+		start = offset - maxLineContext
+		prefix = "..."
+	}
+	if end-offset > maxLineContext {
+		end = offset + maxLineContext
+		suffix = "..."
+	}
+	return prefix + line[start:end] + suffix + "\n" + strings.Repeat(" ", len(prefix)+offset-start) + "^"
+}
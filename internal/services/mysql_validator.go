@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// mysqlServerSections are the my.cnf option groups read by mysqld and mariadbd
+var mysqlServerSections = []string{"mysqld", "server", "mariadb", "mysqld_safe"}
+
+// mysqlOption is an option from my.cnf. Dashes in names are normalized to underscores.
+type mysqlOption struct {
+	section string
+	name    string
+	value   string
+	line    int
+}
+
+type mysqlValidator struct{}
+
+func (mysqlValidator) Name() string {
+	return "mysql"
+}
+
+func (mysqlValidator) Description() string {
+	return "MySQL and MariaDB my.cnf option files"
+}
+
+func (mysqlValidator) Validate(content string) shared.ValidationResult {
+	f := newFindings()
+	options := make(map[string]mysqlOption)
+	for _, option := range parseMySQLConfig(content, f) {
+		if !containsString(mysqlServerSections, option.section) {
+			continue
+		}
+		if previous, exists := options[option.name]; exists && previous.value != option.value {
+			f.addWarning(option.line, "duplicate", fmt.Sprintf("%s is set again (previously '%s' on line %d); the last value wins",
+				option.name, previous.value, previous.line), "Remove the duplicate setting")
+		}
+		options[option.name] = option
+	}
+
+	slowLog, slowLogSet := options["slow_query_log"]
+	switch {
+	case !slowLogSet:
+		f.addWarning(0, "slow_log", "slow_query_log is not set, so the slow query log is disabled",
+			"Add slow_query_log = 1 and slow_query_log_file to the [mysqld] section")
+	case !mysqlEnabled(slowLog.value):
+		f.addWarning(slowLog.line, "slow_log", "The slow query log is disabled; the slowlog data stream will be empty",
+			"Set slow_query_log = 1")
+	default:
+		if _, exists := options["slow_query_log_file"]; !exists {
+			f.addSuggestion(slowLog.line, "slow_log", "slow_query_log_file is not set; MySQL writes <hostname>-slow.log to the data directory",
+				"Set slow_query_log_file to the path configured in the integration", "The slowlog data stream collects nothing if the paths differ")
+		}
+	}
+
+	if longQueryTime, exists := options["long_query_time"]; exists {
+		if seconds, err := strconv.ParseFloat(longQueryTime.value, 64); err != nil {
+			f.addError(longQueryTime.line, "type", fmt.Sprintf("long_query_time must be a number of seconds, got '%s'", longQueryTime.value))
+		} else if seconds > 10 {
+			f.addSuggestion(longQueryTime.line, "slow_log", fmt.Sprintf("long_query_time is %s seconds", longQueryTime.value),
+				"Use a value between 1 and 10 seconds", "Few queries are slow enough to be logged")
+		}
+	}
+
+	if logOutput, exists := options["log_output"]; exists && !strings.Contains(strings.ToUpper(logOutput.value), "FILE") {
+		f.addError(logOutput.line, "log_output", fmt.Sprintf("log_output is '%s', so the slow and general logs are written to tables that Elastic Agent cannot read", logOutput.value))
+	}
+
+	if logError, exists := options["log_error"]; !exists {
+		f.addSuggestion(0, "error_log", "log_error is not set; the error log goes to stderr or <hostname>.err in the data directory",
+			"Set log_error to the path configured in the integration", "The error data stream collects nothing if the paths differ")
+	} else if logError.value == "" || strings.EqualFold(logError.value, "stderr") {
+		f.addWarning(logError.line, "error_log", "The error log is written to stderr", "Set log_error to a file path")
+	}
+
+	if generalLog, exists := options["general_log"]; exists && mysqlEnabled(generalLog.value) {
+		f.addSuggestion(generalLog.line, "performance", "The general query log is enabled",
+			"Disable general_log unless it is needed for auditing", "Every statement is logged, which affects performance and disk usage")
+	}
+
+	return f.result
+}
+
+// mysqlEnabled interprets a boolean option value
+func mysqlEnabled(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "1", "on", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// parseMySQLConfig parses an option file into options, recording malformed lines as errors
+func parseMySQLConfig(content string, f *findings) []mysqlOption {
+	var options []mysqlOption
+	section := ""
+	for index, text := range strings.Split(content, "\n") {
+		lineNumber := index + 1
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") || strings.HasPrefix(text, "!include") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				f.addError(lineNumber, "syntax", "Option group header is missing a closing ']'")
+				continue
+			}
+			section = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+			continue
+		}
+
+		if section == "" {
+			f.addError(lineNumber, "syntax", "Option appears before any [group] header")
+			continue
+		}
+
+		name, value, _ := strings.Cut(text, "=")
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = value[:comment]
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+		if strings.ContainsAny(name, " \t") {
+			f.addError(lineNumber, "syntax", fmt.Sprintf("Invalid option '%s'; use name = value", text))
+			continue
+		}
+
+		options = append(options, mysqlOption{section: section, name: name, value: value, line: lineNumber})
+	}
+	return options
+}
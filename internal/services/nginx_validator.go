@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// nginxAccessFields are the variables the nginx integration's access log
+// pipeline needs; its grok patterns follow the combined log format
+var nginxAccessFields = []string{"remote_addr", "time_local", "request", "status", "body_bytes_sent"}
+
+// nginxOptionalFields are parsed when present and populate user agent and referrer fields
+var nginxOptionalFields = []string{"http_referer", "http_user_agent"}
+
+// nginxDirective is a directive from nginx.conf with the blocks enclosing it
+type nginxDirective struct {
+	name    string
+	args    []string
+	line    int
+	context []string
+}
+
+type nginxValidator struct{}
+
+func (nginxValidator) Name() string {
+	return "nginx"
+}
+
+func (nginxValidator) Description() string {
+	return "nginx.conf and included server configuration files"
+}
+
+func (nginxValidator) Validate(content string) shared.ValidationResult {
+	f := newFindings()
+	directives := parseNginxConfig(content, f)
+
+	formats := make(map[string]nginxDirective)
+	var accessLogs []nginxDirective
+	hasStubStatus := false
+	for _, directive := range directives {
+		switch directive.name {
+		case "log_format":
+			if len(directive.args) < 2 {
+				f.addError(directive.line, "syntax", "log_format needs a name and a format string")
+				continue
+			}
+			formats[directive.args[0]] = directive
+		case "access_log":
+			accessLogs = append(accessLogs, directive)
+		case "error_log":
+			if len(directive.args) > 1 && directive.args[1] == "debug" {
+				f.addSuggestion(directive.line, "log_level", "error_log is set to debug",
+					"Use warn or error in production", "Debug logging produces a high volume of error log events")
+			}
+		case "stub_status":
+			hasStubStatus = true
+		}
+	}
+
+	if len(accessLogs) == 0 {
+		f.addSuggestion(0, "access_log", "No access_log directive found; nginx writes the combined format to its compiled-in default path",
+			"Set access_log explicitly so the integration's paths match", "The access data stream collects nothing if the paths differ")
+	}
+
+	for _, directive := range accessLogs {
+		if len(directive.args) == 0 {
+			f.addError(directive.line, "syntax", "access_log needs a path or off")
+			continue
+		}
+		if directive.args[0] == "off" {
+			f.addWarning(directive.line, "access_log", fmt.Sprintf("Access logging is disabled in %s", nginxContext(directive)),
+				"Remove 'access_log off' where requests should be collected")
+			continue
+		}
+		if strings.HasPrefix(directive.args[0], "syslog:") {
+			f.addWarning(directive.line, "access_log", "Access logs are sent to syslog instead of a file",
+				"Write access logs to a file, or collect them with a syslog input and the nginx pipeline")
+		}
+
+		formatName := "combined"
+		if len(directive.args) > 1 && !strings.Contains(directive.args[1], "=") {
+			formatName = directive.args[1]
+		}
+		if formatName == "combined" {
+			continue
+		}
+
+		format, exists := formats[formatName]
+		if !exists {
+			f.addError(directive.line, "log_format", fmt.Sprintf("access_log uses log format '%s', which is not defined", formatName))
+			continue
+		}
+
+		formatString := strings.Join(format.args[1:], " ")
+		if missing := nginxMissingVariables(formatString, nginxAccessFields); len(missing) > 0 {
+			f.addError(format.line, "log_format", fmt.Sprintf("log_format '%s' is missing %s, which the nginx access pipeline expects",
+				formatName, strings.Join(missing, ", ")))
+		} else if missing := nginxMissingVariables(formatString, nginxOptionalFields); len(missing) > 0 {
+			f.addWarning(format.line, "log_format", fmt.Sprintf("log_format '%s' does not include %s", formatName, strings.Join(missing, ", ")),
+				"Add them in combined log format order to populate referrer and user agent fields")
+		}
+	}
+
+	if !hasStubStatus {
+		f.addSuggestion(0, "metrics", "No stub_status location is configured",
+			"Add 'location /nginx_status { stub_status; allow 127.0.0.1; deny all; }' to a server block",
+			"The stubstatus metrics data stream needs the stub_status endpoint")
+	}
+
+	return f.result
+}
+
+// nginxVariable matches a variable reference, $name or ${name}
+var nginxVariable = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// nginxMissingVariables returns the variables not referenced as $name or ${name} in a log format
+func nginxMissingVariables(format string, variables []string) []string {
+	referenced := make(map[string]bool)
+	for _, match := range nginxVariable.FindAllStringSubmatch(format, -1) {
+		referenced[match[1]+match[2]] = true
+	}
+
+	var missing []string
+	for _, variable := range variables {
+		if !referenced[variable] {
+			missing = append(missing, "$"+variable)
+		}
+	}
+	return missing
+}
+
+func nginxContext(directive nginxDirective) string {
+	if len(directive.context) == 0 {
+		return "the main context"
+	}
+	return "the " + strings.Join(directive.context, " > ") + " block"
+}
+
+// parseNginxConfig splits nginx configuration into directives, recording
+// unbalanced braces and unterminated directives as errors
+func parseNginxConfig(content string, f *findings) []nginxDirective {
+	var directives []nginxDirective
+	var tokens []string
+	var context []string
+	var openLines []int
+	line, tokenLine := 1, 0
+
+	flush := func(block bool) {
+		if len(tokens) == 0 {
+			if block {
+				f.addError(line, "syntax", "Block has no directive name")
+			}
+			return
+		}
+		directive := nginxDirective{name: tokens[0], args: tokens[1:], line: tokenLine, context: append([]string(nil), context...)}
+		directives = append(directives, directive)
+		if block {
+			context = append(context, tokens[0])
+			openLines = append(openLines, tokenLine)
+		}
+		tokens = nil
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '#':
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == ';':
+			if len(tokens) == 0 {
+				f.addError(line, "syntax", "Unexpected ';'")
+			}
+			flush(false)
+		case c == '{':
+			flush(true)
+		case c == '}':
+			if len(tokens) > 0 {
+				f.addError(tokenLine, "syntax", fmt.Sprintf("Directive '%s' is missing a terminating ';'", tokens[0]))
+				tokens = nil
+			}
+			if len(context) == 0 {
+				f.addError(line, "syntax", "Unexpected '}'")
+				continue
+			}
+			context = context[:len(context)-1]
+			openLines = openLines[:len(openLines)-1]
+		case c == '"' || c == '\'':
+			if len(tokens) == 0 {
+				tokenLine = line
+			}
+			start := line
+			var value strings.Builder
+			for i++; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' && i+1 < len(content) {
+					i++
+				}
+				if content[i] == '\n' {
+					line++
+				}
+				value.WriteByte(content[i])
+			}
+			if i >= len(content) {
+				f.addError(start, "syntax", "Unterminated quoted string")
+			}
+			tokens = append(tokens, value.String())
+		default:
+			if len(tokens) == 0 {
+				tokenLine = line
+			}
+			start := i
+			for i+1 < len(content) && !strings.ContainsRune(" \t\r\n;{}#\"'", rune(content[i+1])) {
+				i++
+			}
+			tokens = append(tokens, content[start:i+1])
+		}
+	}
+
+	if len(tokens) > 0 {
+		f.addError(tokenLine, "syntax", fmt.Sprintf("Directive '%s' is missing a terminating ';'", tokens[0]))
+	}
+	for index := range context {
+		f.addError(openLines[index], "syntax", fmt.Sprintf("Block '%s' is never closed", context[index]))
+	}
+	return directives
+}
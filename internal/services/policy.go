@@ -0,0 +1,439 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// maskedValue replaces secret variable values in generated output
+const maskedValue = "********"
+
+// secretFunc returns the value written in place of a secret variable
+type secretFunc func(name string) interface{}
+
+func maskSecret(name string) interface{} {
+	return maskedValue
+}
+
+// PolicyRequest describes the package policy to generate for an integration
+type PolicyRequest struct {
+	Integration     string
+	PolicyTemplates []string
+	DataStreams     []string
+	Vars            map[string]interface{}
+	Namespace       string
+	PolicyID        string
+}
+
+// packagePolicy is the Fleet package policy body in the simplified format
+// accepted by POST /api/fleet/package_policies
+type packagePolicy struct {
+	PolicyID  string                        `json:"policy_id"`
+	Package   packagePolicyPackage          `json:"package"`
+	Name      string                        `json:"name"`
+	Namespace string                        `json:"namespace"`
+	Inputs    map[string]packagePolicyInput `json:"inputs"`
+}
+
+type packagePolicyPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type packagePolicyInput struct {
+	Enabled bool                           `json:"enabled"`
+	Vars    map[string]interface{}         `json:"vars,omitempty"`
+	Streams map[string]packagePolicyStream `json:"streams"`
+}
+
+type packagePolicyStream struct {
+	Enabled bool `json:"enabled"`
+}
+
+// standaloneInput is an input in a standalone elastic-agent.yml
+type standaloneInput struct {
+	ID         string              `yaml:"id"`
+	Type       string              `yaml:"type"`
+	UseOutput  string              `yaml:"use_output"`
+	DataStream standaloneNamespace `yaml:"data_stream"`
+	Streams    []standaloneStream  `yaml:"streams"`
+}
+
+// standaloneStream is a stream of a standalone input; variables are inlined
+// next to the fields in standaloneStreamFields
+type standaloneStream struct {
+	ID         string                 `yaml:"id"`
+	DataStream standaloneDataStream   `yaml:"data_stream"`
+	Vars       map[string]interface{} `yaml:",inline"`
+}
+
+// standaloneStreamFields are the settings of a standalone stream that are not
+// variables. A variable with one of these names cannot be inlined.
+var standaloneStreamFields = map[string]bool{"id": true, "data_stream": true}
+
+type standaloneDataStream struct {
+	Dataset string `yaml:"dataset"`
+	Type    string `yaml:"type"`
+}
+
+type standaloneNamespace struct {
+	Namespace string `yaml:"namespace"`
+}
+
+// resolvedInput is a policy template input with its selected data streams,
+// variable values and the names of its secret variables
+type resolvedInput struct {
+	template    shared.PolicyTemplate
+	input       shared.Input
+	dataStreams []shared.IntegrationDataStream
+	vars        map[string]interface{}
+	secrets     map[string]bool
+}
+
+// policyPlan is the validated selection of inputs for a package policy
+type policyPlan struct {
+	integration shared.IntegrationDetails
+	namespace   string
+	policyID    string
+	inputs      []resolvedInput
+	errors      []string
+	warnings    []string
+}
+
+func (i *IntegrationProvider) GeneratePolicy(request PolicyRequest) (shared.CallToolResult, error) {
+	plan, err := i.planPolicy(request)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	if len(plan.errors) > 0 {
+		return errorResult(fmt.Sprintf("# %s Policy Validation Failed\n\n## Errors\n%s%s",
+			plan.integration.Title, formatList(plan.errors), formatWarnings(plan.warnings))), nil
+	}
+
+	policyJSON, err := formatJSON(plan.packagePolicy(maskSecret))
+	if err != nil {
+		return shared.CallToolResult{}, fmt.Errorf("failed to format package policy: %v", err)
+	}
+
+	inputsYAML, err := formatYAML(map[string]interface{}{"inputs": plan.standaloneInputs(maskSecret)})
+	if err != nil {
+		return shared.CallToolResult{}, fmt.Errorf("failed to format standalone inputs: %v", err)
+	}
+
+	return textResult(fmt.Sprintf("# %s Package Policy\n%s\n## Fleet Package Policy\nPOST this body to `/api/fleet/package_policies`. Secret values are masked.\n\n```json\n%s\n```\n\n## Standalone elastic-agent.yml Inputs\n%s\n\n```yaml\n%s%s```\n",
+		plan.integration.Title, formatWarnings(plan.warnings), policyJSON, standaloneStreamNote, standaloneStreamComment, inputsYAML)), nil
+}
+
+// planPolicy selects the inputs and data streams for a request and resolves
+// their variables. Selection problems are returned as an error; variable
+// problems are collected in the plan so they can be reported together.
+func (i *IntegrationProvider) planPolicy(request PolicyRequest) (*policyPlan, error) {
+	integration, exists := i.integrations[strings.ToLower(request.Integration)]
+	if !exists {
+		return nil, fmt.Errorf("Integration '%s' not found. Available integrations: %s",
+			request.Integration, strings.Join(i.integrationNames(), ", "))
+	}
+
+	plan := &policyPlan{
+		integration: integration,
+		namespace:   request.Namespace,
+		policyID:    request.PolicyID,
+	}
+	if plan.namespace == "" {
+		plan.namespace = "default"
+	}
+	if plan.policyID == "" {
+		plan.policyID = "<agent-policy-id>"
+	}
+
+	templates, err := selectPolicyTemplates(integration, request.PolicyTemplates)
+	if err != nil {
+		return nil, err
+	}
+	wanted, err := selectDataStreams(templates, request.DataStreams)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, template := range templates {
+		for _, input := range template.Inputs {
+			var streams []shared.IntegrationDataStream
+			for _, name := range template.DataStreams {
+				stream := findDataStream(integration, name)
+				if wanted[name] && inputCollects(input, stream) {
+					streams = append(streams, stream)
+				}
+			}
+			if len(streams) == 0 {
+				continue
+			}
+
+			resolved := resolvedInput{
+				template:    template,
+				input:       input,
+				dataStreams: streams,
+				vars:        make(map[string]interface{}),
+				secrets:     make(map[string]bool),
+			}
+			for _, variable := range input.Vars {
+				value, key := lookupVar(request.Vars, input.Type, variable.Name)
+				if key != "" {
+					used[key] = true
+				}
+				if value == nil {
+					value = variable.Default
+				}
+				if value == nil {
+					if variable.Required {
+						plan.errors = append(plan.errors, fmt.Sprintf("%s: required variable '%s' (%s) is not set", input.Type, variable.Name, variable.Title))
+					}
+					continue
+				}
+				if err := checkVarType(variable, value); err != nil {
+					plan.errors = append(plan.errors, fmt.Sprintf("%s: variable '%s' %v", input.Type, variable.Name, err))
+					continue
+				}
+				if standaloneStreamFields[variable.Name] {
+					plan.errors = append(plan.errors, fmt.Sprintf("%s: variable '%s' has the name of a standalone stream setting and cannot be written to the stream", input.Type, variable.Name))
+					continue
+				}
+				if variable.Secret {
+					resolved.secrets[variable.Name] = true
+				}
+				resolved.vars[variable.Name] = value
+			}
+			plan.inputs = append(plan.inputs, resolved)
+		}
+	}
+
+	for _, key := range sortedKeys(request.Vars) {
+		if !used[key] {
+			plan.warnings = append(plan.warnings, fmt.Sprintf("variable '%s' is not used by any selected input", key))
+		}
+	}
+
+	return plan, nil
+}
+
+// packagePolicy builds the Fleet package policy body, replacing secrets using secret
+func (p *policyPlan) packagePolicy(secret secretFunc) packagePolicy {
+	policy := packagePolicy{
+		PolicyID:  p.policyID,
+		Package:   packagePolicyPackage{Name: p.integration.Name, Version: p.integration.Version},
+		Name:      p.integration.Name + "-1",
+		Namespace: p.namespace,
+		Inputs:    make(map[string]packagePolicyInput),
+	}
+
+	for _, resolved := range p.inputs {
+		input := packagePolicyInput{
+			Enabled: true,
+			Vars:    resolved.varValues(secret),
+			Streams: make(map[string]packagePolicyStream),
+		}
+		for _, stream := range resolved.dataStreams {
+			input.Streams[datasetName(p.integration.Name, stream.Name)] = packagePolicyStream{Enabled: true}
+		}
+		policy.Inputs[resolved.template.Name+"-"+resolved.input.Type] = input
+	}
+	return policy
+}
+
+// standaloneInputs builds the inputs block of a standalone elastic-agent.yml,
+// replacing secrets using secret. Input variables are copied onto each stream.
+func (p *policyPlan) standaloneInputs(secret secretFunc) []standaloneInput {
+	var inputs []standaloneInput
+	for _, resolved := range p.inputs {
+		input := standaloneInput{
+			ID:         fmt.Sprintf("%s-%s", resolved.template.Name, resolved.input.Type),
+			Type:       resolved.input.Type,
+			UseOutput:  "default",
+			DataStream: standaloneNamespace{Namespace: p.namespace},
+		}
+		for _, stream := range resolved.dataStreams {
+			dataset := datasetName(p.integration.Name, stream.Name)
+			input.Streams = append(input.Streams, standaloneStream{
+				ID:         fmt.Sprintf("%s-%s", resolved.input.Type, dataset),
+				DataStream: standaloneDataStream{Dataset: dataset, Type: stream.Type},
+				Vars:       resolved.varValues(secret),
+			})
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// varValues returns the input's variable values, replacing its secrets using secret
+func (r resolvedInput) varValues(secret secretFunc) map[string]interface{} {
+	if len(r.vars) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(r.vars))
+	for name, value := range r.vars {
+		if r.secrets[name] {
+			value = secret(name)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+func (i *IntegrationProvider) integrationNames() []string {
+	names := make([]string, 0, len(i.integrations))
+	for name := range i.integrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectPolicyTemplates returns the named policy templates, or all of them when none are named
+func selectPolicyTemplates(integration shared.IntegrationDetails, names []string) ([]shared.PolicyTemplate, error) {
+	if len(names) == 0 {
+		return integration.PolicyTemplates, nil
+	}
+
+	var templates []shared.PolicyTemplate
+	for _, name := range names {
+		found := false
+		for _, template := range integration.PolicyTemplates {
+			if strings.EqualFold(template.Name, name) {
+				templates = append(templates, template)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, 0, len(integration.PolicyTemplates))
+			for _, template := range integration.PolicyTemplates {
+				available = append(available, template.Name)
+			}
+			return nil, fmt.Errorf("policy template '%s' not found in %s. Available policy templates: %s",
+				name, integration.Name, strings.Join(available, ", "))
+		}
+	}
+	return templates, nil
+}
+
+// selectDataStreams returns the set of requested data streams, or every data
+// stream of the templates when none are requested
+func selectDataStreams(templates []shared.PolicyTemplate, names []string) (map[string]bool, error) {
+	available := make(map[string]bool)
+	for _, template := range templates {
+		for _, name := range template.DataStreams {
+			available[name] = true
+		}
+	}
+
+	if len(names) == 0 {
+		return available, nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		if !available[name] {
+			return nil, fmt.Errorf("data stream '%s' is not part of the selected policy templates. Available data streams: %s",
+				name, strings.Join(sortedKeys(available), ", "))
+		}
+		wanted[name] = true
+	}
+	return wanted, nil
+}
+
+func findDataStream(integration shared.IntegrationDetails, name string) shared.IntegrationDataStream {
+	for _, stream := range integration.DataStreams {
+		if stream.Name == name {
+			return stream
+		}
+	}
+	return shared.IntegrationDataStream{Name: name, Type: "logs"}
+}
+
+// inputCollects reports whether an input collects a data stream. Metrics
+// inputs are named "<package>/metrics"; every other input collects logs.
+func inputCollects(input shared.Input, stream shared.IntegrationDataStream) bool {
+	if strings.HasSuffix(input.Type, "/metrics") {
+		return stream.Type == "metrics"
+	}
+	return stream.Type != "metrics"
+}
+
+// lookupVar finds a user-supplied value for an input variable. Values may be
+// keyed "<input type>.<name>" to target one input, or by the plain name.
+func lookupVar(vars map[string]interface{}, inputType, name string) (interface{}, string) {
+	qualified := inputType + "." + name
+	if value, exists := vars[qualified]; exists {
+		return value, qualified
+	}
+	if value, exists := vars[name]; exists {
+		return value, name
+	}
+	return nil, ""
+}
+
+// checkVarType verifies a value against a variable's declared type
+func checkVarType(variable shared.Variable, value interface{}) error {
+	if variable.Multi {
+		items, ok := value.([]interface{})
+		if !ok {
+			if _, isStrings := value.([]string); isStrings {
+				return nil
+			}
+			return fmt.Errorf("must be a list of %s values", variable.Type)
+		}
+		for _, item := range items {
+			if err := checkScalarType(variable.Type, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return checkScalarType(variable.Type, value)
+}
+
+func checkScalarType(varType string, value interface{}) error {
+	switch varType {
+	case "integer":
+		number, ok := value.(float64)
+		if intValue, isInt := value.(int); isInt {
+			number, ok = float64(intValue), true
+		}
+		if !ok || number != float64(int64(number)) {
+			return fmt.Errorf("must be an integer, got %v", value)
+		}
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be true or false, got %v", value)
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("must be a string, got %v", value)
+		}
+	}
+	return nil
+}
+
+func datasetName(integration, dataStream string) string {
+	return integration + "." + dataStream
+}
+
+func formatWarnings(warnings []string) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	return "\n## Warnings\n" + formatList(warnings)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
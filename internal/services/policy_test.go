@@ -0,0 +1,280 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// demoIntegration has a logs input and a metrics input that both define a
+// "password" variable, which is only secret for the metrics input
+var demoIntegration = shared.IntegrationDetails{
+	Name:    "demo",
+	Title:   "Demo",
+	Version: "1.2.0",
+	DataStreams: []shared.IntegrationDataStream{
+		{Name: "access", Type: "logs"},
+		{Name: "status", Type: "metrics"},
+	},
+	PolicyTemplates: []shared.PolicyTemplate{{
+		Name:        "demo",
+		DataStreams: []string{"access", "status"},
+		Inputs: []shared.Input{
+			{Type: "logfile", Vars: []shared.Variable{
+				{Name: "paths", Type: "text", Multi: true, Required: true, Default: []interface{}{"/var/log/demo/access.log"}},
+				{Name: "password", Type: "text"},
+			}},
+			{Type: "demo/metrics", Vars: []shared.Variable{
+				{Name: "hosts", Type: "text", Multi: true, Required: true},
+				{Name: "password", Type: "password", Secret: true},
+			}},
+		},
+	}},
+}
+
+func newTestPolicyProvider(integrations ...shared.IntegrationDetails) *IntegrationProvider {
+	provider := &IntegrationProvider{integrations: make(map[string]shared.IntegrationDetails)}
+	for _, integration := range integrations {
+		provider.integrations[integration.Name] = integration
+	}
+	return provider
+}
+
+func TestPolicySecretsPerInput(t *testing.T) {
+	plan, err := newTestPolicyProvider(demoIntegration).planPolicy(PolicyRequest{
+		Integration: "demo",
+		Vars: map[string]interface{}{
+			"hosts":                 []interface{}{"http://localhost:8080"},
+			"logfile.password":      "not-a-secret",
+			"demo/metrics.password": "hunter2",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.errors) > 0 {
+		t.Fatalf("planPolicy errors: %q", plan.errors)
+	}
+
+	policy := plan.packagePolicy(maskSecret)
+	passwords := map[string]interface{}{
+		"demo-logfile":      policy.Inputs["demo-logfile"].Vars["password"],
+		"demo-demo/metrics": policy.Inputs["demo-demo/metrics"].Vars["password"],
+	}
+	want := map[string]interface{}{"demo-logfile": "not-a-secret", "demo-demo/metrics": maskedValue}
+	if !reflect.DeepEqual(passwords, want) {
+		t.Errorf("package policy passwords = %v, want %v", passwords, want)
+	}
+
+	for _, input := range plan.standaloneInputs(maskSecret) {
+		for _, stream := range input.Streams {
+			if got := stream.Vars["password"]; got != want[input.ID] {
+				t.Errorf("standalone stream %s password = %v, want %v", stream.ID, got, want[input.ID])
+			}
+		}
+	}
+}
+
+func TestPolicyStreamFieldVariable(t *testing.T) {
+	integration := shared.IntegrationDetails{
+		Name:        "clash",
+		Title:       "Clash",
+		DataStreams: []shared.IntegrationDataStream{{Name: "events", Type: "logs"}},
+		PolicyTemplates: []shared.PolicyTemplate{{
+			Name:        "clash",
+			DataStreams: []string{"events"},
+			Inputs: []shared.Input{{Type: "httpjson", Vars: []shared.Variable{
+				{Name: "id", Type: "text", Default: "client-1"},
+			}}},
+		}},
+	}
+
+	plan, err := newTestPolicyProvider(integration).planPolicy(PolicyRequest{Integration: "clash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"httpjson: variable 'id' has the name of a standalone stream setting and cannot be written to the stream"}
+	if !reflect.DeepEqual(plan.errors, want) {
+		t.Errorf("planPolicy errors = %q, want %q", plan.errors, want)
+	}
+}
+
+func TestPlanPolicy(t *testing.T) {
+	provider := newTestPolicyProvider(demoIntegration)
+	hosts := []interface{}{"http://localhost:8080"}
+
+	tests := []struct {
+		name     string
+		request  PolicyRequest
+		inputs   []string
+		vars     map[string]map[string]interface{}
+		errors   []string
+		warnings []string
+		err      string
+	}{
+		{
+			name:    "defaults and plain names",
+			request: PolicyRequest{Integration: "Demo", Vars: map[string]interface{}{"hosts": hosts}},
+			inputs:  []string{"logfile: demo.access", "demo/metrics: demo.status"},
+			vars: map[string]map[string]interface{}{
+				"logfile":      {"paths": []interface{}{"/var/log/demo/access.log"}},
+				"demo/metrics": {"hosts": hosts},
+			},
+		},
+		{
+			name: "qualified name wins over plain name",
+			request: PolicyRequest{Integration: "demo", Vars: map[string]interface{}{
+				"hosts":              hosts,
+				"paths":              []interface{}{"/logs/plain.log"},
+				"logfile.paths":      []interface{}{"/logs/qualified.log"},
+				"demo/metrics.hosts": []interface{}{"http://metrics:8080"},
+			}},
+			inputs: []string{"logfile: demo.access", "demo/metrics: demo.status"},
+			vars: map[string]map[string]interface{}{
+				"logfile":      {"paths": []interface{}{"/logs/qualified.log"}},
+				"demo/metrics": {"hosts": []interface{}{"http://metrics:8080"}},
+			},
+			warnings: []string{"variable 'hosts' is not used by any selected input", "variable 'paths' is not used by any selected input"},
+		},
+		{
+			name:    "data stream selects inputs",
+			request: PolicyRequest{Integration: "demo", DataStreams: []string{"access"}, Vars: map[string]interface{}{"hostz": hosts}},
+			inputs:  []string{"logfile: demo.access"},
+			vars: map[string]map[string]interface{}{
+				"logfile": {"paths": []interface{}{"/var/log/demo/access.log"}},
+			},
+			warnings: []string{"variable 'hostz' is not used by any selected input"},
+		},
+		{
+			name:    "missing required variable",
+			request: PolicyRequest{Integration: "demo"},
+			inputs:  []string{"logfile: demo.access", "demo/metrics: demo.status"},
+			vars: map[string]map[string]interface{}{
+				"logfile":      {"paths": []interface{}{"/var/log/demo/access.log"}},
+				"demo/metrics": {},
+			},
+			errors: []string{"demo/metrics: required variable 'hosts' () is not set"},
+		},
+		{
+			name: "wrong types",
+			request: PolicyRequest{Integration: "demo", Vars: map[string]interface{}{
+				"hosts":                 "http://localhost:8080",
+				"logfile.paths":         []interface{}{"/var/log/a.log", 42},
+				"demo/metrics.password": true,
+			}},
+			inputs: []string{"logfile: demo.access", "demo/metrics: demo.status"},
+			vars: map[string]map[string]interface{}{
+				"logfile":      {},
+				"demo/metrics": {},
+			},
+			errors: []string{
+				"logfile: variable 'paths' must be a string, got 42",
+				"demo/metrics: variable 'hosts' must be a list of text values",
+				"demo/metrics: variable 'password' must be a string, got true",
+			},
+		},
+		{
+			name:    "unknown integration",
+			request: PolicyRequest{Integration: "nginx"},
+			err:     "Integration 'nginx' not found. Available integrations: demo",
+		},
+		{
+			name:    "unknown policy template",
+			request: PolicyRequest{Integration: "demo", PolicyTemplates: []string{"demo-logs"}},
+			err:     "policy template 'demo-logs' not found in demo. Available policy templates: demo",
+		},
+		{
+			name:    "data stream outside the templates",
+			request: PolicyRequest{Integration: "demo", DataStreams: []string{"errors"}},
+			err:     "data stream 'errors' is not part of the selected policy templates. Available data streams: access, status",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan, err := provider.planPolicy(test.request)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("planPolicy error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var inputs []string
+			vars := make(map[string]map[string]interface{})
+			for _, resolved := range plan.inputs {
+				streams := make([]string, 0, len(resolved.dataStreams))
+				for _, stream := range resolved.dataStreams {
+					streams = append(streams, datasetName(plan.integration.Name, stream.Name))
+				}
+				inputs = append(inputs, resolved.input.Type+": "+strings.Join(streams, ", "))
+				vars[resolved.input.Type] = resolved.vars
+			}
+			if !reflect.DeepEqual(inputs, test.inputs) {
+				t.Errorf("inputs = %q, want %q", inputs, test.inputs)
+			}
+			if !reflect.DeepEqual(vars, test.vars) {
+				t.Errorf("vars = %v, want %v", vars, test.vars)
+			}
+			if !reflect.DeepEqual(plan.errors, test.errors) {
+				t.Errorf("errors = %q, want %q", plan.errors, test.errors)
+			}
+			if !reflect.DeepEqual(plan.warnings, test.warnings) {
+				t.Errorf("warnings = %q, want %q", plan.warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestGeneratePolicyOutput(t *testing.T) {
+	provider := newTestPolicyProvider(demoIntegration)
+	request := PolicyRequest{Integration: "demo", Namespace: "prod", Vars: map[string]interface{}{
+		"hosts":                 []interface{}{"http://localhost:8080"},
+		"demo/metrics.password": "hunter2",
+	}}
+
+	tests := []struct {
+		name     string
+		generate func() (shared.CallToolResult, error)
+		contains []string
+	}{
+		{
+			name:     "package policy",
+			generate: func() (shared.CallToolResult, error) { return provider.GeneratePolicy(request) },
+			contains: []string{`"namespace": "prod"`, `"demo.status": {`, `"password": "********"`, "id: demo/metrics-demo.status", "password: '********'"},
+		},
+		{
+			name: "agent configuration",
+			generate: func() (shared.CallToolResult, error) {
+				return provider.RenderAgentConfig(AgentConfigRequest{PolicyRequest: request})
+			},
+			contains: []string{"- ES_API_KEY\n", "- DEMO_PASSWORD\n", "password: ${env.DEMO_PASSWORD}", "https://localhost:9200", "namespace: prod"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].Text
+			if result.IsError {
+				t.Fatalf("generation failed: %s", text)
+			}
+			if strings.Contains(text, "hunter2") {
+				t.Errorf("output contains the secret value:\n%s", text)
+			}
+			for _, want := range test.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("output does not contain %q:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// ReadmeTemplateFile is the path, relative to the config directory, of a
+// template that replaces the built-in README template
+const ReadmeTemplateFile = "templates/readme.md.tmpl"
+
+//go:embed templates/readme.md.tmpl
+var defaultReadmeTemplate string
+
+// ReadmeProvider renders integration READMEs from service configurations and
+// integration metadata
+type ReadmeProvider struct {
+	configDir    string
+	configLoader *config.ConfigLoader
+	integrations *IntegrationProvider
+	// loadErr is reported by Readme, ImportReadme and ExportSite, as
+	// StaleProvider does
+	loadErr error
+}
+
+func NewReadmeProvider(configDir string) *ReadmeProvider {
+	configLoader := config.NewConfigLoaderFromEnv(configDir)
+	loadErr := configLoader.LoadAllServices()
+	if loadErr != nil {
+		configLoader = config.NewConfigLoader(configDir)
+	}
+
+	return &ReadmeProvider{
+		configDir:    configDir,
+		configLoader: configLoader,
+		integrations: NewIntegrationProvider(),
+		loadErr:      loadErr,
+	}
+}
+
+// LoadErrors returns the errors of the service files that failed to load
+func (r *ReadmeProvider) LoadErrors() []error {
+	return r.configLoader.LoadErrors()
+}
+
+// ReadmeData is the data passed to README templates
+type ReadmeData struct {
+	Service *config.ServiceConfig
+	// Integration is nil when no integration metadata is found for the service
+	Integration *shared.IntegrationDetails
+	// DataStreamSource describes where the data streams were read from
+	DataStreamSource string
+	DataStreams      []ReadmeDataStream
+	KibanaSetup      []ReadmeKibanaInput
+	// StatusNotice warns that the service is a draft or deprecated when the
+	// server labels content that is not reviewed
+	StatusNotice string
+}
+
+// ReadmeDataStream is a data stream with its fields and an example event
+type ReadmeDataStream struct {
+	Name        string
+	Type        string
+	Description string
+	Fields      []shared.Field
+	// SampleEvent is indented JSON, and SampleEventNote says where it came from
+	SampleEvent     string
+	SampleEventNote string
+}
+
+// ReadmeKibanaInput holds the Kibana setup steps of one input type
+type ReadmeKibanaInput struct {
+	InputType string
+	Steps     []config.KibanaSetupStep
+}
+
+// readmeFuncs are the functions available to README templates
+var readmeFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"add":       func(a, b int) int { return a + b },
+	"cell":      tableCell,
+	"fieldType": formatFieldType,
+	"fileType":  getFileExtension,
+	"trim":      strings.TrimSpace,
+}
+
+// RenderReadme renders the README of a service. The integration defaults to
+// the service name, and an empty template uses the configured or built-in one.
+func (r *ReadmeProvider) RenderReadme(serviceName, integrationName, templateText string) (shared.CallToolResult, error) {
+	readme, err := r.Readme(serviceName, integrationName, templateText)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	return textResult(readme), nil
+}
+
+// Readme renders the README of a service as Markdown
+func (r *ReadmeProvider) Readme(serviceName, integrationName, templateText string) (string, error) {
+	if r.loadErr != nil {
+		return "", r.loadErr
+	}
+	serviceConfig, err := r.configLoader.GetServiceConfig(serviceName)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := r.parseTemplate(templateText)
+	if err != nil {
+		return "", err
+	}
+	readme, _, err := r.render(tmpl, serviceConfig, integrationName)
+	return readme, err
+}
+
+// parseTemplate parses a README template, or the configured or built-in one
+// when the text is empty
+func (r *ReadmeProvider) parseTemplate(templateText string) (*template.Template, error) {
+	if templateText == "" {
+		var err error
+		templateText, err = r.readmeTemplate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	tmpl, err := template.New("readme").Funcs(readmeFuncs).Parse(templateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid README template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// render executes a README template for a service and returns the README
+// with the data it was rendered from. The integration defaults to the service name.
+func (r *ReadmeProvider) render(tmpl *template.Template, serviceConfig *config.ServiceConfig, integrationName string) (string, ReadmeData, error) {
+	if integrationName == "" {
+		integrationName = serviceConfig.ServiceName
+	}
+	data, err := r.readmeData(serviceConfig, integrationName)
+	if err != nil {
+		return "", data, err
+	}
+	var readme strings.Builder
+	if err := tmpl.Execute(&readme, data); err != nil {
+		return "", data, fmt.Errorf("failed to render README: %v", err)
+	}
+	return readme.String(), data, nil
+}
+
+// readmeTemplate returns the config directory's README template, or the
+// built-in one when there is none
+func (r *ReadmeProvider) readmeTemplate() (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(r.configDir, ReadmeTemplateFile))
+	if os.IsNotExist(err) {
+		return defaultReadmeTemplate, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read README template: %v", err)
+	}
+	return string(content), nil
+}
+
+func (r *ReadmeProvider) readmeData(serviceConfig *config.ServiceConfig, integrationName string) (ReadmeData, error) {
+	serviceConfig = withoutPlaceholders(serviceConfig)
+	data := ReadmeData{Service: serviceConfig, StatusNotice: statusNotice(r.configLoader, serviceConfig.ServiceStatus())}
+	for _, inputType := range serviceConfig.KibanaSetupInstructions.InputTypes() {
+		data.KibanaSetup = append(data.KibanaSetup, ReadmeKibanaInput{
+			InputType: inputType,
+			Steps:     serviceConfig.KibanaSetupInstructions[inputType].Steps,
+		})
+	}
+
+	// A service without integration metadata still gets a README, without
+	// the reference section. A package that fails to load is an error.
+	integration, source, err := r.integrations.integrationDetails(integrationName)
+	var notFound *integrationNotFoundError
+	if errors.As(err, &notFound) {
+		return data, nil
+	}
+	if err != nil {
+		return data, err
+	}
+	data.Integration = &integration
+	data.DataStreamSource = source
+
+	for _, stream := range integration.DataStreams {
+		event, note, err := r.integrations.sampleEvent(integrationName, stream)
+		if err != nil {
+			return data, fmt.Errorf("failed to format sample event for %s: %v", stream.Name, err)
+		}
+		data.DataStreams = append(data.DataStreams, ReadmeDataStream{
+			Name:            stream.Name,
+			Type:            stream.Type,
+			Description:     stream.Description,
+			Fields:          stream.Fields,
+			SampleEvent:     event,
+			SampleEventNote: note,
+		})
+	}
+	return data, nil
+}
+
+// withoutPlaceholders returns a copy of a service without the placeholders
+// scaffolded files start with, such as "# TODO: Add service prerequisites",
+// so that READMEs leave out what has not been written yet. Placeholder
+// entries and steps are dropped, later steps are renumbered to close the
+// gap, and placeholder text is cleared. Issues left with only a title are
+// dropped too.
+func withoutPlaceholders(serviceConfig *config.ServiceConfig) *config.ServiceConfig {
+	cleaned := *serviceConfig
+	cleaned.DocumentationSites = finishedEntries(cleaned.DocumentationSites)
+
+	info := &cleaned.ServiceInfo
+	info.CommonUseCases = finishedEntries(info.CommonUseCases)
+	info.DataTypesCollected = finishedEntries(info.DataTypesCollected)
+	info.Compatibility.ElasticStackVersions = finishedEntries(info.Compatibility.ElasticStackVersions)
+	info.Compatibility.ServiceVersions = finishedEntries(info.Compatibility.ServiceVersions)
+	info.ScalingAndPerformance.Description = finishedText(info.ScalingAndPerformance.Description)
+	info.ScalingAndPerformance.PerformanceExpectations = finishedEntries(info.ScalingAndPerformance.PerformanceExpectations)
+	info.ScalingAndPerformance.ScalingGuidance = finishedEntries(info.ScalingAndPerformance.ScalingGuidance)
+
+	setup := &cleaned.SetupInstructions
+	setup.Prerequisites = finishedEntries(setup.Prerequisites)
+	var installationSteps []config.InstallationStep
+	dropped := 0
+	for _, step := range setup.InstallationSteps {
+		step.Description = finishedText(step.Description)
+		step.Commands = finishedEntries(step.Commands)
+		step.Verification = finishedText(step.Verification)
+		if unfinishedContent.MatchString(step.Title) || (step.Description == "" && len(step.Commands) == 0 && len(step.ConfigSnippets) == 0 && step.Verification == "") {
+			dropped++
+			continue
+		}
+		step.Step -= dropped
+		installationSteps = append(installationSteps, step)
+	}
+	setup.InstallationSteps = installationSteps
+
+	kibana := make(config.KibanaSetupInstructions, len(cleaned.KibanaSetupInstructions))
+	for inputType, steps := range cleaned.KibanaSetupInstructions {
+		var kept []config.KibanaSetupStep
+		dropped := 0
+		for _, step := range steps.Steps {
+			if finishedText(step.Instruction) == "" {
+				dropped++
+				continue
+			}
+			step.Step -= dropped
+			kept = append(kept, step)
+		}
+		steps.Steps = kept
+		kibana[inputType] = steps
+	}
+	cleaned.KibanaSetupInstructions = kibana
+
+	troubleshooting := &cleaned.Troubleshooting
+	var issues []config.TroubleshootingIssue
+	for _, issue := range troubleshooting.CommonIssues {
+		issue.Solution = finishedText(issue.Solution)
+		issue.Solutions = finishedEntries(issue.Solutions)
+		issue.Symptoms = finishedEntries(issue.Symptoms)
+		issue.Causes = finishedEntries(issue.Causes)
+		issue.Prevention = finishedEntries(issue.Prevention)
+		if finishedText(issue.Issue) == "" || (len(issue.AllSolutions()) == 0 && len(issue.Symptoms) == 0 && len(issue.Causes) == 0) {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	troubleshooting.CommonIssues = issues
+	troubleshooting.DiagnosticCommands = finishedEntries(troubleshooting.DiagnosticCommands)
+	troubleshooting.LogLocations = finishedEntries(troubleshooting.LogLocations)
+	troubleshooting.SupportResources = finishedEntries(troubleshooting.SupportResources)
+
+	var validationSteps []config.ValidationStep
+	dropped = 0
+	for _, step := range cleaned.ValidationSteps.Steps {
+		step.Description = finishedText(step.Description)
+		step.Commands = finishedEntries(step.Commands)
+		step.ExpectedOutput = finishedText(step.ExpectedOutput)
+		if unfinishedContent.MatchString(step.Title) || (step.Description == "" && len(step.Commands) == 0) {
+			dropped++
+			continue
+		}
+		step.Step -= dropped
+		validationSteps = append(validationSteps, step)
+	}
+	cleaned.ValidationSteps.Steps = validationSteps
+	return &cleaned
+}
+
+// finishedEntries returns the entries of a list that are not placeholders
+func finishedEntries(entries []string) []string {
+	var finished []string
+	for _, entry := range entries {
+		if finishedText(entry) != "" {
+			finished = append(finished, entry)
+		}
+	}
+	return finished
+}
+
+// finishedText returns text, or "" when it is a placeholder
+func finishedText(text string) string {
+	if unfinishedContent.MatchString(text) {
+		return ""
+	}
+	return text
+}
@@ -0,0 +1,1154 @@
+package services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/markdown"
+	"elastic-integration-docs-mcp/internal/version"
+)
+
+// Kinds of README sections recognised by the importer
+const (
+	importOverview        = "overview"
+	importUseCases        = "use cases"
+	importDataTypes       = "data types"
+	importCompatibility   = "compatibility"
+	importScaling         = "scaling"
+	importPrerequisites   = "prerequisites"
+	importSetup           = "setup"
+	importKibana          = "kibana"
+	importValidation      = "validation"
+	importTroubleshooting = "troubleshooting"
+	importLogLocations    = "log locations"
+	importDocumentation   = "documentation"
+	importReference       = "reference"
+)
+
+// importSectionKeywords maps heading keywords to section kinds. The first
+// kind with a keyword contained in a heading wins, so more specific kinds
+// come first.
+var importSectionKeywords = []struct {
+	kind     string
+	keywords []string
+}{
+	{importLogLocations, []string{"log location", "log file", "log path"}},
+	{importReference, []string{"reference", "exported fields", "fields", "sample event"}},
+	{importCompatibility, []string{"compatib", "supported version", "version support"}},
+	{importTroubleshooting, []string{"troubleshoot", "known issue", "common issue", "common problem", "faq"}},
+	{importValidation, []string{"validat", "verif", "test the", "check the"}},
+	{importKibana, []string{"kibana", "fleet", "onboard", "add the integration", "enable the integration"}},
+	{importPrerequisites, []string{"prerequisite", "requirement", "what do i need", "before you begin", "before you start"}},
+	{importSetup, []string{"setup", "set up", "install", "deploy", "configur", "getting started"}},
+	{importScaling, []string{"scaling", "performance"}},
+	{importUseCases, []string{"use case", "what can i do", "why use"}},
+	{importDataTypes, []string{"what data", "data type", "data stream", "collect"}},
+	{importDocumentation, []string{"documentation", "resources", "further reading", "learn more", "links", "support"}},
+	{importOverview, []string{"overview", "introduction", "about", "how it works", "description"}},
+}
+
+// nestedImportKinds are the section kinds recognised in subsections of a
+// section of another kind
+var nestedImportKinds = map[string]bool{
+	importCompatibility:   true,
+	importPrerequisites:   true,
+	importSetup:           true,
+	importKibana:          true,
+	importValidation:      true,
+	importTroubleshooting: true,
+	importLogLocations:    true,
+	importReference:       true,
+}
+
+var (
+	// versionConstraint matches version ranges written in constraint syntax
+	versionConstraint = regexp.MustCompile(`(?:\^|~|>=|<=|>|<)\s*v?\d+(?:\.\d+){0,2}(?:\s*(?:\|\||,)?\s*(?:\^|~|>=|<=|>|<)\s*v?\d+(?:\.\d+){0,2})*`)
+	// minimumVersion matches versions followed by "or later" and similar
+	minimumVersion   = regexp.MustCompile(`(?i)v?(\d+(?:\.\d+){1,2})(?:\+|\s+(?:or|and)\s+(?:later|higher|newer|above))`)
+	stackMention     = regexp.MustCompile(`(?i)elastic stack|kibana|elasticsearch|elastic agent|stack version`)
+	snippetFilename  = regexp.MustCompile("`([\\w./~-]+\\.(?:ya?ml|conf|cnf|cfg|ini|json|xml|toml|properties))`")
+	snippetLabel     = regexp.MustCompile("^`([^`]+)`:?$")
+	stepNumber       = regexp.MustCompile(`(?i)^step\s+\d+\s*[:.-]\s*`)
+	placeholder      = regexp.MustCompile(`^(?:# )?TODO\b`)
+	sentenceEnd      = regexp.MustCompile(`[.!?](?:\s+|$)`)
+	expectedLabel    = regexp.MustCompile(`(?i)^(?:expected(?: output)?|to verify(?: this step)?):\s*`)
+	issueLabel       = regexp.MustCompile(`(?i)^(symptoms?|(?:possible |common )?causes?|solutions?|resolution|fix|prevention)\s*:$`)
+	linkTarget       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldLead         = regexp.MustCompile(`^\*\*([^*]+?)\*\*\s*[:.-]?\s*(.*)$`)
+	expectedOutput   = regexp.MustCompile(`(?i)^(expected|you should see|the output|it should|this should|should (?:return|show|print)|to verify)`)
+	templateFunction = regexp.MustCompile(`^\{\{.*\}\}$`)
+)
+
+// shellLanguages are the code block languages imported as commands; other
+// code blocks become config snippets
+var shellLanguages = map[string]bool{
+	"": true, "bash": true, "sh": true, "shell": true, "console": true, "shell-session": true,
+	"zsh": true, "powershell": true, "ps1": true, "cmd": true, "bat": true,
+}
+
+// snippetExtensions are the file extensions of unnamed config snippets by language
+var snippetExtensions = map[string]string{
+	"yaml": "yml", "yml": "yml", "json": "json", "xml": "xml", "toml": "toml",
+	"ini": "ini", "nginx": "conf", "apache": "conf", "apacheconf": "conf", "conf": "conf",
+}
+
+// ImportReport lists how the sections of an imported README were used
+type ImportReport struct {
+	Source  string
+	Service string
+	// MergedWith is the existing service file the draft was merged into;
+	// sections missing from the README keep its content
+	MergedWith string
+	// Mapped lists the configuration sections filled from the README
+	Mapped []string
+	// Skipped lists content left out on purpose, such as the field reference
+	// the readme command generates from the package
+	Skipped []string
+	// Unmapped lists content that has no place in a service configuration
+	// and needs a human to move or drop it
+	Unmapped []string
+}
+
+// ImportReadme reads a README and drafts a service configuration from it.
+// The service name defaults to the package directory of a
+// packages/<name>/docs/README.md path. When the service is already
+// configured, the draft starts from that configuration and only the sections
+// found in the README replace it.
+func (r *ReadmeProvider) ImportReadme(path, serviceName string) (*config.ServiceConfig, *ImportReport, error) {
+	if r.loadErr != nil {
+		return nil, nil, r.loadErr
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if serviceName == "" {
+		serviceName = packageNameFromPath(path)
+	}
+	if serviceName == "" {
+		return nil, nil, fmt.Errorf("cannot derive a service name from %s; name the service explicitly", path)
+	}
+
+	imported, report := ImportReadmeMarkdown(string(content), serviceName)
+	report.Source = path
+
+	// The existing file is read as written, keeping references to the
+	// common troubleshooting library unresolved
+	servicePath, err := serviceFilePath(r.configDir, serviceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadFile(servicePath)
+	if os.IsNotExist(err) {
+		return imported, report, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", servicePath, err)
+	}
+	var existing config.ServiceConfig
+	if err := yaml.Unmarshal(data, &existing); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", servicePath, err)
+	}
+	report.MergedWith = servicePath
+	return mergeImportedConfig(&existing, imported), report, nil
+}
+
+// packageNameFromPath returns the package directory of a README inside an
+// integrations package, or "" when the path does not look like one
+func packageNameFromPath(path string) string {
+	dir := filepath.Dir(path)
+	if name := filepath.Base(dir); name == "docs" || name == "_dev" || name == "build" {
+		for dir != "." && dir != string(filepath.Separator) {
+			dir = filepath.Dir(dir)
+			if base := filepath.Base(dir); base != "build" && base != "_dev" {
+				return base
+			}
+		}
+	}
+	return ""
+}
+
+// ImportReadmeMarkdown drafts a service configuration from README Markdown.
+// Headings select the configuration section their content goes to; see
+// importSectionKeywords.
+func ImportReadmeMarkdown(source, serviceName string) (*config.ServiceConfig, *ImportReport) {
+	m := &readmeImport{
+		config: &config.ServiceConfig{ServiceName: serviceName, Status: config.StatusDraft},
+		report: &ImportReport{Service: serviceName},
+		counts: make(map[string]int),
+	}
+	root := buildImportSections(markdown.Parse(source))
+	m.dropDirectives(root)
+	m.section(root)
+	m.finish()
+	return m.config, m.report
+}
+
+// importSection is a heading with the blocks and subsections under it
+type importSection struct {
+	level    int
+	title    string
+	path     string
+	blocks   []markdown.Block
+	children []*importSection
+}
+
+func buildImportSections(blocks []markdown.Block) *importSection {
+	root := &importSection{}
+	stack := []*importSection{root}
+	for _, block := range blocks {
+		if block.Kind != markdown.BlockHeading {
+			top := stack[len(stack)-1]
+			top.blocks = append(top.blocks, block)
+			continue
+		}
+		for len(stack) > 1 && stack[len(stack)-1].level >= block.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		section := &importSection{level: block.Level, title: block.Text, path: block.Text}
+		if parent.path != "" {
+			section.path = parent.path + " > " + block.Text
+		}
+		parent.children = append(parent.children, section)
+		stack = append(stack, section)
+	}
+	return root
+}
+
+// classifyImportSection returns the kind of a heading, or "" when no
+// keyword matches
+func classifyImportSection(title string) string {
+	title = strings.ToLower(title)
+	for _, entry := range importSectionKeywords {
+		for _, keyword := range entry.keywords {
+			if strings.Contains(title, keyword) {
+				return entry.kind
+			}
+		}
+	}
+	return ""
+}
+
+// readmeImport holds the state of one README import
+type readmeImport struct {
+	config *config.ServiceConfig
+	report *ImportReport
+	// counts are the number of items imported per configuration section
+	counts map[string]int
+	// setupSteps and validationSteps collect steps across README sections
+	setupSteps      []importedStep
+	validationSteps []importedStep
+}
+
+// importedStep is a setup or validation step before conversion
+type importedStep struct {
+	title string
+	// filename names the next config snippet
+	filename    string
+	description []string
+	commands    []string
+	snippets    []config.ConfigSnippet
+	expected    []string
+}
+
+func (s *importedStep) empty() bool {
+	return len(s.description) == 0 && len(s.commands) == 0 && len(s.snippets) == 0 && len(s.expected) == 0
+}
+
+// section imports a section and its subsections. Subsections without a kind
+// of their own belong to the kind of their parent.
+func (m *readmeImport) section(section *importSection) {
+	kind := classifyImportSection(section.title)
+	switch title := strings.ToLower(section.title); {
+	case section.level == 1 && m.config.Title == "":
+		// The first top-level heading names the integration
+		m.config.Title = strings.TrimSuffix(strings.TrimSuffix(section.title, " Integration"), " integration")
+		m.mapped("title", 1)
+		kind = importOverview
+	case title == "logs" || title == "metrics":
+		// "Logs" and "Metrics" sections hold the generated reference
+		kind = importReference
+	}
+
+	own, others := m.splitChildren(section, kind)
+	switch kind {
+	case importOverview:
+		m.overview(section)
+	case importUseCases:
+		m.texts(section, own, &m.config.ServiceInfo.CommonUseCases, "service_info.common_use_cases")
+	case importDataTypes:
+		m.texts(section, own, &m.config.ServiceInfo.DataTypesCollected, "service_info.data_types_collected")
+	case importPrerequisites:
+		m.texts(section, own, &m.config.SetupInstructions.Prerequisites, "setup_instructions.prerequisites")
+	case importCompatibility:
+		m.compatibility(section, own)
+	case importScaling:
+		m.scaling(section, own)
+	case importSetup:
+		m.setupSteps = append(m.setupSteps, m.steps(section, own, kind)...)
+	case importValidation:
+		m.validationSteps = append(m.validationSteps, m.steps(section, own, kind)...)
+	case importKibana:
+		m.kibana(section, own)
+	case importTroubleshooting:
+		m.troubleshooting(section, own)
+	case importLogLocations:
+		m.logLocations(section, own)
+	case importDocumentation:
+		m.documentation(section, own)
+	case importReference:
+		m.report.Skipped = append(m.report.Skipped, section.path+": generated from the package by the readme command")
+	default:
+		for _, block := range section.blocks {
+			m.unmapped(section, block)
+		}
+	}
+
+	for _, child := range others {
+		m.section(child)
+	}
+}
+
+// splitChildren separates the subsections handled with their parent from
+// those imported on their own. Overview and unrecognised sections take no
+// subsections. Other sections take those without a kind, or with a kind only
+// recognised by generic words such as "collect"; a troubleshooting section
+// also takes subsections about setup or validation, which are its issues.
+func (m *readmeImport) splitChildren(section *importSection, kind string) (own, others []*importSection) {
+	for _, child := range section.children {
+		childKind := classifyImportSection(child.title)
+		separate := childKind != kind && nestedImportKinds[childKind]
+		switch {
+		case kind == "" || kind == importOverview:
+			others = append(others, child)
+		case kind == importReference:
+			own = append(own, child)
+		case separate && (kind != importTroubleshooting || childKind == importLogLocations || childKind == importReference):
+			others = append(others, child)
+		default:
+			own = append(own, child)
+		}
+	}
+	return own, others
+}
+
+// dropDirectives removes template function calls such as {{fields "access"}}
+// and HTML comments, which only make sense to the elastic-package renderer,
+// and TODO placeholders from a section tree
+func (m *readmeImport) dropDirectives(section *importSection) {
+	var blocks []markdown.Block
+	for _, block := range section.blocks {
+		text := strings.TrimSpace(block.Text)
+		if block.Kind == markdown.BlockParagraph && (templateFunction.MatchString(text) || strings.HasPrefix(text, "<!--")) {
+			m.report.Skipped = append(m.report.Skipped, fmt.Sprintf("%s: %q", sectionLabel(section), excerpt(text)))
+			continue
+		}
+		if block.Kind == markdown.BlockParagraph && placeholder.MatchString(text) {
+			continue
+		}
+		if block.Kind == markdown.BlockList {
+			var items []markdown.ListItem
+			for _, item := range block.Items {
+				if !placeholderItem(item) {
+					items = append(items, item)
+				}
+			}
+			if len(items) == 0 {
+				continue
+			}
+			block.Items = items
+		}
+		blocks = append(blocks, block)
+	}
+	section.blocks = blocks
+	for _, child := range section.children {
+		m.dropDirectives(child)
+	}
+}
+
+func (m *readmeImport) overview(section *importSection) {
+	for i, block := range section.blocks {
+		switch {
+		case block.Kind == markdown.BlockParagraph && m.config.Description == "":
+			m.config.Description = oneLine(block.Text)
+			m.mapped("description", 1)
+		case block.Kind == markdown.BlockList && i > 0 && section.blocks[i-1].Kind == markdown.BlockParagraph &&
+			strings.Contains(strings.ToLower(section.blocks[i-1].Text), "use"):
+			// "Use the integration to:" followed by a list
+			for _, item := range block.Items {
+				m.config.ServiceInfo.CommonUseCases = append(m.config.ServiceInfo.CommonUseCases, oneLine(item.Text))
+			}
+			m.mapped("service_info.common_use_cases", len(block.Items))
+		default:
+			m.unmapped(section, block)
+		}
+	}
+}
+
+// texts imports paragraphs and list items as entries of a list
+func (m *readmeImport) texts(section *importSection, own []*importSection, target *[]string, name string) {
+	for _, s := range append([]*importSection{section}, own...) {
+		for _, block := range s.blocks {
+			entries := blockTexts(block)
+			if entries == nil {
+				m.unmapped(s, block)
+				continue
+			}
+			*target = append(*target, entries...)
+			m.mapped(name, len(entries))
+		}
+	}
+}
+
+// blockTexts returns a paragraph or the items of a list as single lines, or
+// nil for other blocks
+func blockTexts(block markdown.Block) []string {
+	switch block.Kind {
+	case markdown.BlockParagraph:
+		return []string{oneLine(block.Text)}
+	case markdown.BlockList:
+		var entries []string
+		for _, item := range block.Items {
+			entries = append(entries, oneLine(itemText(item)))
+		}
+		return entries
+	}
+	return nil
+}
+
+// compatibility imports version ranges. Ranges mentioning the Elastic Stack
+// go to elastic_stack_versions and the others to service_versions; text
+// without a recognisable range is reported.
+func (m *readmeImport) compatibility(section *importSection, own []*importSection) {
+	compatibility := &m.config.ServiceInfo.Compatibility
+	for _, s := range append([]*importSection{section}, own...) {
+		for _, block := range s.blocks {
+			texts := blockTexts(block)
+			if block.Kind == markdown.BlockTable {
+				for _, row := range block.Rows[1:] {
+					texts = append(texts, strings.Join(row, " "))
+				}
+			}
+			if texts == nil {
+				m.unmapped(s, block)
+				continue
+			}
+			for _, text := range sentences(texts) {
+				ranges := versionRanges(text)
+				if len(ranges) == 0 {
+					m.report.Unmapped = append(m.report.Unmapped, fmt.Sprintf("%s: no version range in %q", sectionLabel(s), excerpt(text)))
+					continue
+				}
+				if stackMention.MatchString(text) {
+					compatibility.ElasticStackVersions = append(compatibility.ElasticStackVersions, ranges...)
+					m.mapped("service_info.compatibility.elastic_stack_versions", len(ranges))
+				} else {
+					compatibility.ServiceVersions = append(compatibility.ServiceVersions, ranges...)
+					m.mapped("service_info.compatibility.service_versions", len(ranges))
+				}
+			}
+		}
+	}
+}
+
+// versionRanges returns the valid version constraints written in a text
+func versionRanges(text string) []string {
+	var ranges []string
+	for _, match := range versionConstraint.FindAllString(text, -1) {
+		ranges = append(ranges, strings.Join(strings.Fields(match), " "))
+	}
+	if len(ranges) == 0 {
+		for _, match := range minimumVersion.FindAllStringSubmatch(text, -1) {
+			ranges = append(ranges, ">="+match[1])
+		}
+	}
+
+	var valid []string
+	for _, candidate := range ranges {
+		candidate = strings.ReplaceAll(candidate, "v", "")
+		if _, err := version.ParseConstraint(candidate); err == nil {
+			valid = append(valid, candidate)
+		}
+	}
+	return valid
+}
+
+func (m *readmeImport) scaling(section *importSection, own []*importSection) {
+	scaling := &m.config.ServiceInfo.ScalingAndPerformance
+	for _, s := range append([]*importSection{section}, own...) {
+		for _, block := range s.blocks {
+			entries := blockTexts(block)
+			switch {
+			case entries == nil:
+				m.unmapped(s, block)
+			case block.Kind == markdown.BlockParagraph && scaling.Description == "":
+				scaling.Description = entries[0]
+				m.mapped("service_info.scaling_and_performance.description", 1)
+			default:
+				scaling.ScalingGuidance = append(scaling.ScalingGuidance, entries...)
+				m.mapped("service_info.scaling_and_performance.scaling_guidance", len(entries))
+			}
+		}
+	}
+}
+
+// steps imports setup or validation steps. Each subsection is one step, or
+// the steps of its own subsections; without subsections, the items of a
+// numbered list are the steps. Other content joins the step before it.
+func (m *readmeImport) steps(section *importSection, own []*importSection, kind string) []importedStep {
+	var steps []importedStep
+	if len(own) > 0 || !hasOrderedList(section.blocks) {
+		lead := importedStep{title: stepHeading(section.title)}
+		for _, block := range section.blocks {
+			m.addToStep(section, &lead, block, len(own) > 0)
+		}
+		if !lead.empty() {
+			steps = append(steps, lead)
+		}
+		for _, child := range own {
+			if len(child.children) > 0 || hasOrderedList(child.blocks) {
+				childOwn, others := m.splitChildren(child, kind)
+				steps = append(steps, m.steps(child, childOwn, kind)...)
+				for _, other := range others {
+					m.section(other)
+				}
+				continue
+			}
+			step := importedStep{title: stepHeading(child.title)}
+			for _, block := range child.blocks {
+				m.addToStep(child, &step, block, false)
+			}
+			if step.empty() {
+				m.report.Unmapped = append(m.report.Unmapped, child.path+": heading without content")
+				continue
+			}
+			steps = append(steps, step)
+		}
+		return steps
+	}
+
+	var current *importedStep
+	lead := importedStep{title: stepHeading(section.title)}
+	for _, block := range section.blocks {
+		if block.Kind == markdown.BlockList && block.Ordered {
+			for _, item := range block.Items {
+				step := importedStep{}
+				text := oneLine(item.Text)
+				if match := boldLead.FindStringSubmatch(text); match != nil {
+					// "**Check the service**: Verify that..."
+					step.title = strings.TrimRight(match[1], ":.")
+					if match[2] != "" {
+						step.description = append(step.description, match[2])
+					}
+				} else if step.title = stepTitle(text); strings.TrimRight(text, ".:") != step.title {
+					step.description = append(step.description, text)
+				}
+				if match := snippetFilename.FindStringSubmatch(text); match != nil {
+					step.filename = match[1]
+				}
+				for _, nested := range item.Blocks {
+					m.addToStep(section, &step, nested, false)
+				}
+				steps = append(steps, step)
+			}
+			current = &steps[len(steps)-1]
+			continue
+		}
+		if current == nil {
+			// Introductions without commands are usually "Follow these steps:"
+			if block.Kind == markdown.BlockCode {
+				m.addToStep(section, &lead, block, false)
+			} else {
+				m.unmapped(section, block)
+			}
+			continue
+		}
+		m.addToStep(section, current, block, false)
+	}
+	if !lead.empty() {
+		steps = append([]importedStep{lead}, steps...)
+	}
+	return steps
+}
+
+// addToStep adds a block to a step. With introOnly, paragraphs are reported
+// instead, since the text before a section's subsections is an introduction.
+func (m *readmeImport) addToStep(section *importSection, step *importedStep, block markdown.Block, introOnly bool) {
+	switch block.Kind {
+	case markdown.BlockParagraph:
+		text := oneLine(block.Text)
+		switch match := snippetLabel.FindStringSubmatch(text); {
+		case match != nil:
+			// A "`file.conf`:" line names the snippet that follows
+			step.filename = match[1]
+		case expectedOutput.MatchString(text):
+			step.expected = append(step.expected, expectedLabel.ReplaceAllString(text, ""))
+		case introOnly:
+			m.unmapped(section, block)
+		default:
+			step.description = append(step.description, text)
+		}
+	case markdown.BlockList:
+		for i, item := range block.Items {
+			marker := "-"
+			if block.Ordered {
+				marker = fmt.Sprintf("%d.", i+1)
+			}
+			step.description = append(step.description, marker+" "+oneLine(item.Text))
+			for _, nested := range item.Blocks {
+				m.addToStep(section, step, nested, false)
+			}
+		}
+	case markdown.BlockCode:
+		language := strings.ToLower(block.Language)
+		if !shellLanguages[language] {
+			step.snippets = append(step.snippets, config.ConfigSnippet{
+				Filename: snippetName(step, language),
+				Content:  strings.TrimRight(block.Text, "\n") + "\n",
+			})
+			step.filename = ""
+			return
+		}
+		commands, output := splitShellBlock(block.Text)
+		step.commands = append(step.commands, commands...)
+		if output != "" && len(step.expected) > 0 {
+			// Output shown after "You should see" is the expected output
+			step.expected = append(step.expected, output)
+		}
+	default:
+		m.unmapped(section, block)
+	}
+}
+
+// snippetName returns the file named before a snippet, or a name from the
+// snippet's language
+func snippetName(step *importedStep, language string) string {
+	if step.filename != "" {
+		return step.filename
+	}
+	if len(step.description) > 0 {
+		if match := snippetFilename.FindStringSubmatch(step.description[len(step.description)-1]); match != nil {
+			return match[1]
+		}
+	}
+	extension := snippetExtensions[language]
+	if extension == "" {
+		extension = "txt"
+	}
+	return fmt.Sprintf("snippet-%d.%s", len(step.snippets)+1, extension)
+}
+
+// splitShellBlock returns the commands of a shell code block. When lines
+// start with a "$ " prompt, the lines without one are output.
+func splitShellBlock(code string) (commands []string, output string) {
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	prompted := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "$ ") {
+			prompted = true
+			break
+		}
+	}
+
+	var outputLines []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case prompted && strings.HasPrefix(trimmed, "$ "):
+			commands = append(commands, strings.TrimSpace(trimmed[2:]))
+		case prompted:
+			outputLines = append(outputLines, line)
+		case trimmed != "":
+			commands = append(commands, trimmed)
+		}
+	}
+	return commands, strings.TrimSpace(strings.Join(outputLines, "\n"))
+}
+
+func (m *readmeImport) kibana(section *importSection, own []*importSection) {
+	var instructions []string
+	for _, s := range append([]*importSection{section}, own...) {
+		blocks := s.blocks
+		if s != section {
+			blocks = flattenSection(s)
+		}
+		ordered := hasOrderedList(blocks)
+		for _, block := range blocks {
+			switch {
+			case block.Kind == markdown.BlockList && (block.Ordered || !ordered):
+				for _, item := range block.Items {
+					instructions = append(instructions, oneLine(itemText(item)))
+				}
+			case block.Kind == markdown.BlockParagraph && !ordered:
+				instructions = append(instructions, oneLine(block.Text))
+			default:
+				m.unmapped(s, block)
+			}
+		}
+	}
+	if len(instructions) == 0 {
+		return
+	}
+
+	var steps []config.KibanaSetupStep
+	for i, instruction := range instructions {
+		steps = append(steps, config.KibanaSetupStep{Step: i + 1, Instruction: instruction})
+	}
+	if m.config.KibanaSetupInstructions == nil {
+		m.config.KibanaSetupInstructions = config.KibanaSetupInstructions{}
+	}
+	existing := m.config.KibanaSetupInstructions[config.DefaultKibanaInputType]
+	offset := len(existing.Steps)
+	for _, step := range steps {
+		step.Step += offset
+		existing.Steps = append(existing.Steps, step)
+	}
+	m.config.KibanaSetupInstructions[config.DefaultKibanaInputType] = existing
+	m.mapped("kibana_setup_instructions.default", len(steps))
+}
+
+// troubleshooting imports issue/solution pairs: subsections titled with the
+// issue, table rows of issue and solution, and list items with a bold issue
+// followed by the solution. Code blocks become diagnostic commands.
+func (m *readmeImport) troubleshooting(section *importSection, own []*importSection) {
+	troubleshooting := &m.config.Troubleshooting
+	addIssue := func(issue config.TroubleshootingIssue) {
+		issue.Issue = oneLine(issue.Issue)
+		if len(issue.Solutions) == 1 {
+			issue.Solution, issue.Solutions = issue.Solutions[0], nil
+		}
+		troubleshooting.CommonIssues = append(troubleshooting.CommonIssues, issue)
+		m.mapped("troubleshooting.common_issues", 1)
+	}
+	addCommands := func(block markdown.Block) {
+		commands, _ := splitShellBlock(block.Text)
+		troubleshooting.DiagnosticCommands = append(troubleshooting.DiagnosticCommands, commands...)
+		m.mapped("troubleshooting.diagnostic_commands", len(commands))
+	}
+
+	for _, block := range section.blocks {
+		switch block.Kind {
+		case markdown.BlockTable:
+			for _, row := range block.Rows[1:] {
+				if len(row) < 2 || row[0] == "" {
+					continue
+				}
+				addIssue(config.TroubleshootingIssue{Issue: row[0], Solutions: []string{oneLine(strings.Join(row[1:], " "))}})
+			}
+		case markdown.BlockList:
+			for _, item := range block.Items {
+				match := boldLead.FindStringSubmatch(oneLine(item.Text))
+				if match == nil || match[2] == "" {
+					m.report.Unmapped = append(m.report.Unmapped, fmt.Sprintf("%s: list item %q", sectionLabel(section), excerpt(item.Text)))
+					continue
+				}
+				addIssue(config.TroubleshootingIssue{Issue: strings.TrimRight(match[1], ":."), Solutions: []string{match[2]}})
+			}
+		case markdown.BlockCode:
+			addCommands(block)
+		default:
+			m.unmapped(section, block)
+		}
+	}
+
+	for _, child := range own {
+		issue := config.TroubleshootingIssue{Issue: child.title}
+		// Lists after a "Symptoms:" or "Causes:" line go to those fields;
+		// everything else is a solution
+		target := &issue.Solutions
+		for _, block := range flattenSection(child) {
+			if block.Kind == markdown.BlockCode {
+				addCommands(block)
+				continue
+			}
+			if match := issueLabel.FindStringSubmatch(oneLine(block.Text)); block.Kind == markdown.BlockParagraph && match != nil {
+				switch label := strings.ToLower(match[1]); {
+				case strings.HasPrefix(label, "symptom"):
+					target = &issue.Symptoms
+				case strings.Contains(label, "cause"):
+					target = &issue.Causes
+				case label == "prevention":
+					target = &issue.Prevention
+				default:
+					target = &issue.Solutions
+				}
+				continue
+			}
+			entries := blockTexts(block)
+			if entries == nil {
+				m.unmapped(child, block)
+				continue
+			}
+			if block.Kind == markdown.BlockParagraph {
+				// A paragraph ends a labelled list
+				target = &issue.Solutions
+			}
+			*target = append(*target, entries...)
+		}
+		if len(issue.Solutions) == 0 {
+			m.report.Unmapped = append(m.report.Unmapped, child.path+": issue without a solution")
+			continue
+		}
+		addIssue(issue)
+	}
+}
+
+// logLocations imports paths written as code spans or list items
+func (m *readmeImport) logLocations(section *importSection, own []*importSection) {
+	for _, s := range append([]*importSection{section}, own...) {
+		for _, block := range s.blocks {
+			var locations []string
+			switch block.Kind {
+			case markdown.BlockCode:
+				locations, _ = splitShellBlock(block.Text)
+			default:
+				for _, text := range blockTexts(block) {
+					if spans := codeSpans(text); len(spans) > 0 {
+						locations = append(locations, spans...)
+					} else if block.Kind == markdown.BlockList {
+						locations = append(locations, text)
+					}
+				}
+			}
+			if len(locations) == 0 {
+				m.unmapped(s, block)
+				continue
+			}
+			m.config.Troubleshooting.LogLocations = append(m.config.Troubleshooting.LogLocations, locations...)
+			m.mapped("troubleshooting.log_locations", len(locations))
+		}
+	}
+}
+
+// documentation imports the targets of links. Sections about support go to
+// the troubleshooting support resources.
+func (m *readmeImport) documentation(section *importSection, own []*importSection) {
+	for _, s := range append([]*importSection{section}, own...) {
+		target, name := &m.config.DocumentationSites, "documentation_sites"
+		if strings.Contains(strings.ToLower(s.title), "support") {
+			target, name = &m.config.Troubleshooting.SupportResources, "troubleshooting.support_resources"
+		}
+		for _, block := range s.blocks {
+			var links []string
+			for _, text := range blockTexts(block) {
+				for _, match := range linkTarget.FindAllStringSubmatch(text, -1) {
+					if strings.HasPrefix(match[2], "http") {
+						links = append(links, match[2])
+					}
+				}
+			}
+			if len(links) == 0 {
+				m.unmapped(s, block)
+				continue
+			}
+			*target = append(*target, links...)
+			m.mapped(name, len(links))
+		}
+	}
+}
+
+// finish numbers the collected steps and stores them in the configuration
+func (m *readmeImport) finish() {
+	for i, step := range m.setupSteps {
+		m.config.SetupInstructions.InstallationSteps = append(m.config.SetupInstructions.InstallationSteps, config.InstallationStep{
+			Step:           i + 1,
+			Title:          step.title,
+			Description:    strings.Join(step.description, "\n"),
+			Commands:       step.commands,
+			ConfigSnippets: step.snippets,
+			Verification:   strings.Join(step.expected, "\n"),
+		})
+	}
+	m.mapped("setup_instructions.installation_steps", len(m.setupSteps))
+
+	for i, step := range m.validationSteps {
+		description := step.description
+		// Validation steps have no config snippets; show them in the description
+		for _, snippet := range step.snippets {
+			description = append(description, fmt.Sprintf("%s:\n%s", snippet.Filename, snippet.Content))
+		}
+		m.config.ValidationSteps.Steps = append(m.config.ValidationSteps.Steps, config.ValidationStep{
+			Step:           i + 1,
+			Title:          step.title,
+			Description:    strings.Join(description, "\n"),
+			Commands:       step.commands,
+			ExpectedOutput: strings.Join(step.expected, "\n"),
+		})
+	}
+	m.mapped("validation_steps", len(m.validationSteps))
+
+	// Report each configuration section once, in the order first filled
+	var mapped []string
+	for _, name := range m.report.Mapped {
+		mapped = append(mapped, fmt.Sprintf("%s (%d)", name, m.counts[name]))
+	}
+	m.report.Mapped = mapped
+}
+
+// mapped records items imported into a configuration section
+func (m *readmeImport) mapped(name string, count int) {
+	if count == 0 {
+		return
+	}
+	if _, seen := m.counts[name]; !seen {
+		m.report.Mapped = append(m.report.Mapped, name)
+	}
+	m.counts[name] += count
+}
+
+// unmapped reports a block that has no place in the configuration
+func (m *readmeImport) unmapped(section *importSection, block markdown.Block) {
+	var what string
+	switch block.Kind {
+	case markdown.BlockParagraph:
+		what = fmt.Sprintf("paragraph %q", excerpt(block.Text))
+	case markdown.BlockList:
+		what = fmt.Sprintf("list of %d items, starting %q", len(block.Items), excerpt(itemText(block.Items[0])))
+	case markdown.BlockCode:
+		what = fmt.Sprintf("%s %q", strings.TrimSpace(block.Language+" code block"), excerpt(block.Text))
+	case markdown.BlockTable:
+		what = fmt.Sprintf("table with columns %s", strings.Join(block.Rows[0], ", "))
+	default:
+		what = block.Kind
+	}
+	m.report.Unmapped = append(m.report.Unmapped, sectionLabel(section)+": "+what)
+}
+
+// mergeImportedConfig replaces the sections of an existing configuration
+// that the README provided, keeping everything else
+func mergeImportedConfig(existing, imported *config.ServiceConfig) *config.ServiceConfig {
+	merged := *existing
+	if imported.Title != "" {
+		merged.Title = imported.Title
+	}
+	if imported.Description != "" {
+		merged.Description = imported.Description
+	}
+
+	info, importedInfo := &merged.ServiceInfo, imported.ServiceInfo
+	replaceList(&info.CommonUseCases, importedInfo.CommonUseCases)
+	replaceList(&info.DataTypesCollected, importedInfo.DataTypesCollected)
+	replaceList(&info.Compatibility.ElasticStackVersions, importedInfo.Compatibility.ElasticStackVersions)
+	replaceList(&info.Compatibility.ServiceVersions, importedInfo.Compatibility.ServiceVersions)
+	if importedInfo.ScalingAndPerformance.Description != "" {
+		info.ScalingAndPerformance.Description = importedInfo.ScalingAndPerformance.Description
+	}
+	replaceList(&info.ScalingAndPerformance.ScalingGuidance, importedInfo.ScalingAndPerformance.ScalingGuidance)
+
+	replaceList(&merged.SetupInstructions.Prerequisites, imported.SetupInstructions.Prerequisites)
+	if len(imported.SetupInstructions.InstallationSteps) > 0 {
+		merged.SetupInstructions.InstallationSteps = imported.SetupInstructions.InstallationSteps
+	}
+	if steps, ok := imported.KibanaSetupInstructions[config.DefaultKibanaInputType]; ok {
+		kibana := config.KibanaSetupInstructions{}
+		for inputType, existingSteps := range existing.KibanaSetupInstructions {
+			kibana[inputType] = existingSteps
+		}
+		kibana[config.DefaultKibanaInputType] = steps
+		merged.KibanaSetupInstructions = kibana
+	}
+
+	troubleshooting, importedTroubleshooting := &merged.Troubleshooting, imported.Troubleshooting
+	if len(importedTroubleshooting.CommonIssues) > 0 {
+		// References to the common library are kept, since READMEs rarely
+		// repeat the shared issues
+		var issues []config.TroubleshootingIssue
+		for _, issue := range existing.Troubleshooting.CommonIssues {
+			if issue.Ref != "" {
+				issues = append(issues, issue)
+			}
+		}
+		troubleshooting.CommonIssues = append(importedTroubleshooting.CommonIssues, issues...)
+	}
+	replaceList(&troubleshooting.DiagnosticCommands, importedTroubleshooting.DiagnosticCommands)
+	replaceList(&troubleshooting.LogLocations, importedTroubleshooting.LogLocations)
+	replaceList(&troubleshooting.SupportResources, importedTroubleshooting.SupportResources)
+	if len(imported.ValidationSteps.Steps) > 0 {
+		merged.ValidationSteps = imported.ValidationSteps
+	}
+	replaceList(&merged.DocumentationSites, imported.DocumentationSites)
+	markImportedDrafts(existing, &merged)
+	return &merged
+}
+
+// markImportedDrafts marks the sections an import changed as drafts, since
+// they need a review again. When the title, description or documentation
+// sites changed, the service becomes a draft and its unchanged sections keep
+// the status they had.
+func markImportedDrafts(existing, merged *config.ServiceConfig) {
+	serviceChanged := merged.Title != existing.Title || merged.Description != existing.Description ||
+		!reflect.DeepEqual(merged.DocumentationSites, existing.DocumentationSites)
+	status := existing.ServiceStatus()
+	mark := func(changed bool, sectionStatus *string) {
+		if changed {
+			*sectionStatus = config.StatusDraft
+		} else if serviceChanged && *sectionStatus == "" {
+			*sectionStatus = status
+		}
+	}
+
+	mark(!reflect.DeepEqual(merged.ServiceInfo, existing.ServiceInfo), &merged.ServiceInfo.Status)
+	mark(!reflect.DeepEqual(merged.SetupInstructions, existing.SetupInstructions), &merged.SetupInstructions.Status)
+	kibana := config.KibanaSetupInstructions{}
+	for inputType, steps := range merged.KibanaSetupInstructions {
+		mark(!reflect.DeepEqual(steps, existing.KibanaSetupInstructions[inputType]), &steps.Status)
+		kibana[inputType] = steps
+	}
+	merged.KibanaSetupInstructions = kibana
+	mark(!reflect.DeepEqual(merged.Troubleshooting, existing.Troubleshooting), &merged.Troubleshooting.Status)
+	mark(!reflect.DeepEqual(merged.ValidationSteps, existing.ValidationSteps), &merged.ValidationSteps.Status)
+	if serviceChanged {
+		merged.Status = config.StatusDraft
+	}
+}
+
+func replaceList(target *[]string, imported []string) {
+	if len(imported) > 0 {
+		*target = imported
+	}
+}
+
+// FormatImportReport renders an import report as Markdown
+func FormatImportReport(report *ImportReport) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Import Report: %s\n\n**Source**: %s\n", report.Service, report.Source))
+	if report.MergedWith != "" {
+		result.WriteString(fmt.Sprintf("**Merged into**: %s (sections missing from the README are kept)\n", report.MergedWith))
+	}
+	sections := []struct {
+		title string
+		items []string
+		empty string
+	}{
+		{"Mapped", report.Mapped, "Nothing could be mapped."},
+		{"Skipped", report.Skipped, ""},
+		{"Not Mapped", report.Unmapped, "Everything was mapped."},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 && section.empty == "" {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\n## %s\n\n", section.title))
+		if len(section.items) == 0 {
+			result.WriteString(section.empty + "\n")
+			continue
+		}
+		result.WriteString(formatList(section.items))
+	}
+	return result.String()
+}
+
+// FormatImportedConfig renders a drafted service configuration as YAML
+func FormatImportedConfig(serviceConfig *config.ServiceConfig) (string, error) {
+	return formatYAML(serviceConfig)
+}
+
+// flattenSection returns the blocks of a section followed by those of its
+// subsections, whose headings become paragraphs
+func flattenSection(section *importSection) []markdown.Block {
+	blocks := append([]markdown.Block{}, section.blocks...)
+	for _, child := range section.children {
+		blocks = append(blocks, markdown.Block{Kind: markdown.BlockParagraph, Text: child.title})
+		blocks = append(blocks, flattenSection(child)...)
+	}
+	return blocks
+}
+
+// placeholderItem reports whether a list item is a TODO placeholder. A
+// "- # TODO" item holds a heading rather than text.
+func placeholderItem(item markdown.ListItem) bool {
+	text := item.Text
+	if text == "" && len(item.Blocks) > 0 && item.Blocks[0].Kind == markdown.BlockHeading {
+		text = item.Blocks[0].Text
+	}
+	return placeholder.MatchString(strings.TrimSpace(text))
+}
+
+func hasOrderedList(blocks []markdown.Block) bool {
+	for _, block := range blocks {
+		if block.Kind == markdown.BlockList && block.Ordered {
+			return true
+		}
+	}
+	return false
+}
+
+// itemText returns the text of a list item, including nested list items
+func itemText(item markdown.ListItem) string {
+	text := item.Text
+	for _, block := range item.Blocks {
+		if block.Kind == markdown.BlockList {
+			for _, nested := range block.Items {
+				text += "; " + itemText(nested)
+			}
+		}
+	}
+	return text
+}
+
+// stepHeading returns a step heading without a "Step 1:" prefix
+func stepHeading(title string) string {
+	return stepNumber.ReplaceAllString(title, "")
+}
+
+// stepTitle returns the first sentence of a numbered step, without a
+// trailing period or colon
+func stepTitle(text string) string {
+	text = oneLine(text)
+	if end := strings.IndexAny(text, ".:"); end > 0 && end+1 < len(text) && text[end+1] == ' ' {
+		text = text[:end]
+	}
+	return strings.TrimRight(text, ".:")
+}
+
+func codeSpans(text string) []string {
+	var spans []string
+	parts := strings.Split(text, "`")
+	for i := 1; i < len(parts)-1; i += 2 {
+		spans = append(spans, parts[i])
+	}
+	return spans
+}
+
+func sectionLabel(section *importSection) string {
+	if section.path == "" {
+		return "(before the first heading)"
+	}
+	return section.path
+}
+
+// sentences splits texts into sentences, so that a stack and a service
+// version in one paragraph are told apart
+func sentences(texts []string) []string {
+	var result []string
+	for _, text := range texts {
+		for _, sentence := range sentenceEnd.Split(text, -1) {
+			if sentence = strings.TrimSpace(sentence); sentence != "" {
+				result = append(result, sentence)
+			}
+		}
+	}
+	return result
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// excerpt returns the start of a text on one line, for reports
+func excerpt(text string) string {
+	text = oneLine(text)
+	if len(text) > 60 {
+		return text[:60] + "..."
+	}
+	return text
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/config"
+)
+
+const importTestReadme = "# Nginx Integration\n\n" +
+	"The Nginx integration collects access and error logs.\n\n" +
+	"## Compatibility\n\n" +
+	"This integration works with Nginx 1.19 or later. It requires Elastic Stack 8.13.0 or later.\n\n" +
+	"## Setup\n\n" +
+	"1. Install Nginx.\n\n   ```bash\n   sudo apt-get install nginx\n   ```\n\n" +
+	"2. **Enable the status page**: Add this to `nginx.conf`:\n\n   ```nginx\n   location /nginx_status { stub_status; }\n   ```\n\n" +
+	"3. Reload Nginx.\n\n   ```\n   $ sudo nginx -s reload\n   ```\n\n" +
+	"## Troubleshooting\n\n" +
+	"| Problem | Solution |\n|---|---|\n| No access logs | Check the log path |\n\n" +
+	"### Permission denied\n\nSymptoms:\n\n- Agent logs show permission denied\n\n" +
+	"Grant the agent read access to `/var/log/nginx`.\n\n```bash\nls -l /var/log/nginx\n```\n\n" +
+	"## Log locations\n\n- `/var/log/nginx/access.log`\n\n" +
+	"## Documentation\n\nSee the [Nginx docs](https://nginx.org/en/docs/).\n\n" +
+	"## Exported fields\n\n{{fields \"access\"}}\n\n" +
+	"## Community\n\nJoin the forum.\n"
+
+func TestImportReadmeMarkdown(t *testing.T) {
+	imported, report := ImportReadmeMarkdown(importTestReadme, "nginx")
+
+	if imported.Title != "Nginx" || imported.Description != "The Nginx integration collects access and error logs." || imported.Status != config.StatusDraft {
+		t.Errorf("overview = %q, %q, %q", imported.Title, imported.Description, imported.Status)
+	}
+
+	compatibility := imported.ServiceInfo.Compatibility
+	if !reflect.DeepEqual(compatibility.ServiceVersions, []string{">=1.19"}) || !reflect.DeepEqual(compatibility.ElasticStackVersions, []string{">=8.13.0"}) {
+		t.Errorf("compatibility = service %q, stack %q", compatibility.ServiceVersions, compatibility.ElasticStackVersions)
+	}
+
+	type step struct {
+		Title       string
+		Description string
+		Commands    []string
+		Snippets    []string
+	}
+	var steps []step
+	for _, installationStep := range imported.SetupInstructions.InstallationSteps {
+		var snippets []string
+		for _, snippet := range installationStep.ConfigSnippets {
+			snippets = append(snippets, snippet.Filename+": "+snippet.Content)
+		}
+		steps = append(steps, step{installationStep.Title, installationStep.Description, installationStep.Commands, snippets})
+	}
+	wantSteps := []step{
+		{Title: "Install Nginx", Commands: []string{"sudo apt-get install nginx"}},
+		{Title: "Enable the status page", Description: "Add this to `nginx.conf`:", Snippets: []string{"nginx.conf: location /nginx_status { stub_status; }\n"}},
+		{Title: "Reload Nginx", Commands: []string{"sudo nginx -s reload"}},
+	}
+	if !reflect.DeepEqual(steps, wantSteps) {
+		t.Errorf("installation steps = %+v, want %+v", steps, wantSteps)
+	}
+
+	troubleshooting := imported.Troubleshooting
+	var issues []string
+	for _, issue := range troubleshooting.CommonIssues {
+		issues = append(issues, issue.Issue+" | "+strings.Join(issue.Symptoms, ", ")+" | "+strings.Join(issue.AllSolutions(), ", "))
+	}
+	wantIssues := []string{
+		"No access logs |  | Check the log path",
+		"Permission denied | Agent logs show permission denied | Grant the agent read access to `/var/log/nginx`.",
+	}
+	if !reflect.DeepEqual(issues, wantIssues) {
+		t.Errorf("issues = %q, want %q", issues, wantIssues)
+	}
+	if !reflect.DeepEqual(troubleshooting.DiagnosticCommands, []string{"ls -l /var/log/nginx"}) {
+		t.Errorf("diagnostic commands = %q", troubleshooting.DiagnosticCommands)
+	}
+	if !reflect.DeepEqual(troubleshooting.LogLocations, []string{"/var/log/nginx/access.log"}) {
+		t.Errorf("log locations = %q", troubleshooting.LogLocations)
+	}
+	if !reflect.DeepEqual(imported.DocumentationSites, []string{"https://nginx.org/en/docs/"}) {
+		t.Errorf("documentation sites = %q", imported.DocumentationSites)
+	}
+
+	wantReport := ImportReport{
+		Service: "nginx",
+		Mapped: []string{
+			"title (1)",
+			"description (1)",
+			"service_info.compatibility.service_versions (1)",
+			"service_info.compatibility.elastic_stack_versions (1)",
+			"troubleshooting.common_issues (2)",
+			"troubleshooting.diagnostic_commands (1)",
+			"troubleshooting.log_locations (1)",
+			"documentation_sites (1)",
+			"setup_instructions.installation_steps (3)",
+		},
+		Skipped: []string{
+			`Nginx Integration > Exported fields: "{{fields \"access\"}}"`,
+			"Nginx Integration > Exported fields: generated from the package by the readme command",
+		},
+		Unmapped: []string{`Nginx Integration > Community: paragraph "Join the forum."`},
+	}
+	if !reflect.DeepEqual(*report, wantReport) {
+		t.Errorf("report = %+v, want %+v", *report, wantReport)
+	}
+}
+
+func TestSplitShellBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		commands []string
+		output   string
+	}{
+		{name: "plain commands", code: "systemctl restart nginx\n\n  nginx -t\n", commands: []string{"systemctl restart nginx", "nginx -t"}},
+		{name: "prompts and output", code: "$ curl localhost/nginx_status\nActive connections: 1\n$ nginx -v\n", commands: []string{"curl localhost/nginx_status", "nginx -v"}, output: "Active connections: 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commands, output := splitShellBlock(test.code)
+			if !reflect.DeepEqual(commands, test.commands) || output != test.output {
+				t.Errorf("splitShellBlock = %q, %q, want %q, %q", commands, output, test.commands, test.output)
+			}
+		})
+	}
+}
+
+func TestPackageNameFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "packages/nginx/docs/README.md", want: "nginx"},
+		{path: "packages/nginx/_dev/build/docs/README.md", want: "nginx"},
+		{path: "README.md"},
+		{path: "/tmp/nginx/README.md"},
+	}
+	for _, test := range tests {
+		if got := packageNameFromPath(filepath.FromSlash(test.path)); got != test.want {
+			t.Errorf("packageNameFromPath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestImportReadmeMerge(t *testing.T) {
+	provider := newTestReadmeProvider(t, map[string]string{
+		"nginx.yaml": `service_name: nginx
+title: Nginx
+description: Existing description
+status: reviewed
+setup_instructions:
+  prerequisites: [Nginx is installed]
+  installation_steps:
+    - step: 1
+      title: Install Nginx
+troubleshooting:
+  common_issues:
+    - issue: Old issue
+      solution: Old solution
+  log_locations: [/var/log/nginx/error.log]
+`,
+	}, nil)
+	readme := filepath.Join(t.TempDir(), "packages", "nginx", "docs", "README.md")
+	writeTestFiles(t, filepath.Dir(readme), map[string]string{"README.md": "# Nginx\n\n## Troubleshooting\n\n| Problem | Solution |\n|---|---|\n| No access logs | Check the log path |\n"})
+
+	merged, report, err := provider.ImportReadme(readme, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Service != "nginx" || report.MergedWith != filepath.Join(provider.configDir, "services", "nginx.yaml") {
+		t.Errorf("report service %q merged with %q", report.Service, report.MergedWith)
+	}
+	if merged.Description != "Existing description" || !reflect.DeepEqual(merged.SetupInstructions.Prerequisites, []string{"Nginx is installed"}) {
+		t.Errorf("sections missing from the README were not kept: %q, %q", merged.Description, merged.SetupInstructions.Prerequisites)
+	}
+	if len(merged.Troubleshooting.CommonIssues) != 1 || merged.Troubleshooting.CommonIssues[0].Issue != "No access logs" {
+		t.Errorf("common issues = %+v, want the imported issue only", merged.Troubleshooting.CommonIssues)
+	}
+	if !reflect.DeepEqual(merged.Troubleshooting.LogLocations, []string{"/var/log/nginx/error.log"}) {
+		t.Errorf("log locations = %q, want the existing ones", merged.Troubleshooting.LogLocations)
+	}
+	if merged.Status != config.StatusReviewed || merged.Troubleshooting.Status != config.StatusDraft || merged.SetupInstructions.Status != "" {
+		t.Errorf("statuses = service %q, troubleshooting %q, setup %q; want reviewed, draft and inherited",
+			merged.Status, merged.Troubleshooting.Status, merged.SetupInstructions.Status)
+	}
+}
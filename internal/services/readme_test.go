@@ -0,0 +1,66 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/config"
+)
+
+// newTestReadmeProvider returns a README provider for the given service
+// files, keyed by name under config/services, and package tree
+func newTestReadmeProvider(t *testing.T, services, packageFiles map[string]string) *ReadmeProvider {
+	t.Helper()
+	configDir := t.TempDir()
+	files := make(map[string]string)
+	for name, content := range services {
+		files["services/"+name] = content
+	}
+	writeTestFiles(t, configDir, files)
+
+	configLoader := config.NewConfigLoader(configDir)
+	if err := configLoader.LoadAllServices(); err != nil {
+		t.Fatal(err)
+	}
+	return &ReadmeProvider{
+		configDir:    configDir,
+		configLoader: configLoader,
+		integrations: newTestIntegrationProvider(t, packageFiles),
+	}
+}
+
+func TestReadmePackageErrors(t *testing.T) {
+	services := map[string]string{
+		"demo.yaml":    "service_name: demo\ntitle: Demo\ndescription: Demo service\n",
+		"unknown.yaml": "service_name: unknown\ntitle: Unknown\ndescription: Service without a package\n",
+	}
+	provider := newTestReadmeProvider(t, services, map[string]string{
+		"demo/manifest.yml": "name: demo\npolicy_templates: {\n",
+	})
+
+	tests := []struct {
+		service string
+		want    string
+		err     string
+	}{
+		{service: "unknown", want: "# Unknown"},
+		{service: "demo", err: "failed to load package demo"},
+	}
+	for _, test := range tests {
+		t.Run(test.service, func(t *testing.T) {
+			readme, err := provider.Readme(test.service, "", "")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("Readme error = %v, want it to contain %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Readme: %v", err)
+			}
+			if !strings.Contains(readme, test.want) {
+				t.Errorf("Readme = %q, want it to contain %q", readme, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"elastic-integration-docs-mcp/internal/config"
+)
+
+// unfinishedContent matches placeholders left in content that still needs work
+var unfinishedContent = regexp.MustCompile(`\b(TODO|TBD|FIXME)\b`)
+
+// ReviewProvider reports which curated content still needs sign-off
+type ReviewProvider struct {
+	configLoader *config.ConfigLoader
+	// loadErr is reported by Review, as StaleProvider does
+	loadErr error
+}
+
+func NewReviewProvider(configDir string) *ReviewProvider {
+	configLoader := config.NewConfigLoader(configDir)
+	loadErr := configLoader.LoadAllServices()
+	if loadErr != nil {
+		configLoader = config.NewConfigLoader(configDir)
+	}
+
+	return &ReviewProvider{
+		configLoader: configLoader,
+		loadErr:      loadErr,
+	}
+}
+
+// ReviewOptions selects the services in a review checklist
+type ReviewOptions struct {
+	// ServiceNames limits the checklist to these services; all when empty
+	ServiceNames []string
+	// All also lists services with nothing left to review
+	All bool
+}
+
+// ReviewItem is a checklist entry for one section of a service
+type ReviewItem struct {
+	Section string `json:"section"`
+	Status  string `json:"status"`
+	// Open reports whether the section still needs sign-off
+	Open bool   `json:"open"`
+	Note string `json:"note,omitempty"`
+}
+
+// ServiceReview is the checklist of a service
+type ServiceReview struct {
+	Service string       `json:"service"`
+	Title   string       `json:"title"`
+	Status  string       `json:"status"`
+	Open    int          `json:"open"`
+	Items   []ReviewItem `json:"items"`
+}
+
+// ReviewReport lists the review checklists of services. The counts cover
+// every selected service, including those left out because nothing is open.
+type ReviewReport struct {
+	Reviewed   int             `json:"reviewed"`
+	Draft      int             `json:"draft"`
+	Deprecated int             `json:"deprecated"`
+	Open       int             `json:"open"`
+	Services   []ServiceReview `json:"services"`
+	// Errors lists service files that failed to load and were not reviewed
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Review builds the review checklists of the selected services
+func (p *ReviewProvider) Review(options ReviewOptions) (*ReviewReport, error) {
+	if p.loadErr != nil {
+		return nil, p.loadErr
+	}
+	serviceNames := options.ServiceNames
+	if len(serviceNames) == 0 {
+		serviceNames = p.configLoader.GetAllServiceNames()
+		sort.Strings(serviceNames)
+	}
+
+	report := &ReviewReport{Services: []ServiceReview{}}
+	if len(options.ServiceNames) == 0 {
+		report.Errors = loadErrorMessages(p.configLoader)
+	}
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := p.configLoader.GetServiceConfig(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		review := reviewService(serviceConfig)
+		switch review.Status {
+		case config.StatusReviewed:
+			report.Reviewed++
+		case config.StatusDeprecated:
+			report.Deprecated++
+		default:
+			report.Draft++
+		}
+		report.Open += review.Open
+		if review.Open > 0 || options.All {
+			report.Services = append(report.Services, review)
+		}
+	}
+	return report, nil
+}
+
+// reviewService checks every section of a service. Empty sections have
+// nothing to sign off and are left out.
+func reviewService(serviceConfig *config.ServiceConfig) ServiceReview {
+	review := ServiceReview{Service: serviceConfig.ServiceName, Title: serviceConfig.Title, Status: serviceConfig.ServiceStatus()}
+	for _, section := range serviceConfig.ContentSections() {
+		if section.Empty {
+			continue
+		}
+		item := ReviewItem{Section: section.Name, Status: serviceConfig.SectionStatus(section.Status)}
+		switch item.Status {
+		case config.StatusDraft:
+			item.Open, item.Note = true, "needs review"
+		case config.StatusDeprecated:
+			item.Open, item.Note = true, "update or remove it"
+		default:
+			if content, err := yaml.Marshal(section.Content); err == nil && unfinishedContent.Match(content) {
+				item.Open, item.Note = true, "still contains TODO placeholders"
+			}
+		}
+		if item.Open {
+			review.Open++
+		}
+		review.Items = append(review.Items, item)
+	}
+	return review
+}
+
+// FormatReviewReport renders review checklists as Markdown task lists
+func FormatReviewReport(report *ReviewReport) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Content Review\n\n**Services**: %d reviewed, %d draft, %d deprecated\n",
+		report.Reviewed, report.Draft, report.Deprecated))
+	if report.Open > 0 {
+		result.WriteString(fmt.Sprintf("**Needs sign-off**: %d sections\n", report.Open))
+	}
+	result.WriteString(formatLoadErrors(report.Errors))
+	if report.Open == 0 {
+		result.WriteString("\nNothing needs sign-off.\n")
+	}
+
+	for _, review := range report.Services {
+		result.WriteString(fmt.Sprintf("\n## %s (%s)\n\n", review.Service, review.Status))
+		for _, item := range review.Items {
+			check := "x"
+			if item.Open {
+				check = " "
+			}
+			line := fmt.Sprintf("- [%s] `%s`: %s", check, item.Section, item.Status)
+			if item.Note != "" {
+				line += ", " + item.Note
+			}
+			result.WriteString(line + "\n")
+		}
+	}
+	return result.String()
+}
+
+// statusNotice returns a Markdown notice for content with a status other
+// than reviewed when the loader labels such content, or "" otherwise
+func statusNotice(loader *config.ConfigLoader, status string) string {
+	if loader.ContentMode() != config.ContentModeLabel {
+		return ""
+	}
+	switch status {
+	case config.StatusDraft:
+		return "**Draft**: this content has not been reviewed yet and may be incomplete or wrong."
+	case config.StatusDeprecated:
+		return "**Deprecated**: this content is outdated and kept for reference only."
+	}
+	return ""
+}
+
+// labelledStatus returns the status to report in structured output: draft
+// or deprecated when the loader labels such content, or "" otherwise
+func labelledStatus(loader *config.ConfigLoader, status string) string {
+	if loader.ContentMode() != config.ContentModeLabel || status == config.StatusReviewed {
+		return ""
+	}
+	return status
+}
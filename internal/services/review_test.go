@@ -0,0 +1,132 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/config"
+)
+
+func TestReview(t *testing.T) {
+	configDir := t.TempDir()
+	writeTestFiles(t, configDir, map[string]string{
+		"services/nginx.yaml": `service_name: nginx
+title: Nginx
+status: reviewed
+setup_instructions:
+  prerequisites: [Nginx is installed]
+troubleshooting:
+  status: draft
+  log_locations: [/var/log/nginx/error.log]
+validation_steps:
+  steps:
+    - step: 1
+      title: TODO check the status page
+`,
+		"services/apache.yaml": `service_name: apache
+title: Apache
+status: reviewed
+setup_instructions:
+  prerequisites: [Apache is installed]
+`,
+		"services/mysql.yaml": `service_name: mysql
+title: MySQL
+status: deprecated
+`,
+	})
+	provider := NewReviewProvider(configDir)
+
+	tests := []struct {
+		name     string
+		options  ReviewOptions
+		counts   [4]int
+		services []string
+	}{
+		{
+			name:   "open sections",
+			counts: [4]int{2, 0, 1, 3},
+			services: []string{
+				"mysql deprecated: service deprecated update or remove it",
+				"nginx reviewed: service reviewed; setup_instructions reviewed; troubleshooting draft needs review; validation_steps reviewed still contains TODO placeholders",
+			},
+		},
+		{
+			name:    "all services",
+			options: ReviewOptions{All: true},
+			counts:  [4]int{2, 0, 1, 3},
+			services: []string{
+				"apache reviewed: service reviewed; setup_instructions reviewed",
+				"mysql deprecated: service deprecated update or remove it",
+				"nginx reviewed: service reviewed; setup_instructions reviewed; troubleshooting draft needs review; validation_steps reviewed still contains TODO placeholders",
+			},
+		},
+		{
+			name:    "one service",
+			options: ReviewOptions{ServiceNames: []string{"apache"}},
+			counts:  [4]int{1, 0, 0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := provider.Review(test.options)
+			if err != nil {
+				t.Fatalf("Review: %v", err)
+			}
+			if counts := [4]int{report.Reviewed, report.Draft, report.Deprecated, report.Open}; counts != test.counts {
+				t.Errorf("reviewed, draft, deprecated and open = %v, want %v", counts, test.counts)
+			}
+			var services []string
+			for _, review := range report.Services {
+				var items []string
+				for _, item := range review.Items {
+					items = append(items, strings.TrimSpace(item.Section+" "+item.Status+" "+item.Note))
+				}
+				services = append(services, review.Service+" "+review.Status+": "+strings.Join(items, "; "))
+			}
+			if strings.Join(services, "\n") != strings.Join(test.services, "\n") {
+				t.Errorf("services =\n%s\nwant\n%s", strings.Join(services, "\n"), strings.Join(test.services, "\n"))
+			}
+		})
+	}
+
+	report, err := provider.Review(ReviewOptions{ServiceNames: []string{"nginx"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := FormatReviewReport(report)
+	for _, want := range []string{
+		"**Needs sign-off**: 2 sections",
+		"## nginx (reviewed)",
+		"- [x] `setup_instructions`: reviewed\n",
+		"- [ ] `troubleshooting`: draft, needs review\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("FormatReviewReport output does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestStatusLabels(t *testing.T) {
+	tests := []struct {
+		mode   config.ContentMode
+		status string
+		notice string
+		label  string
+	}{
+		{mode: config.ContentModeLabel, status: config.StatusDraft, notice: "**Draft**: this content has not been reviewed yet", label: config.StatusDraft},
+		{mode: config.ContentModeLabel, status: config.StatusDeprecated, notice: "**Deprecated**: this content is outdated", label: config.StatusDeprecated},
+		{mode: config.ContentModeLabel, status: config.StatusReviewed},
+		{mode: config.ContentModeAll, status: config.StatusDraft},
+	}
+	for _, test := range tests {
+		t.Setenv(config.ContentModeEnv, string(test.mode))
+		loader := config.NewConfigLoaderFromEnv(t.TempDir())
+		if notice := statusNotice(loader, test.status); !strings.HasPrefix(notice, test.notice) || (test.notice == "") != (notice == "") {
+			t.Errorf("statusNotice(%s, %s) = %q, want %q", test.mode, test.status, notice, test.notice)
+		}
+		if label := labelledStatus(loader, test.status); label != test.label {
+			t.Errorf("labelledStatus(%s, %s) = %q, want %q", test.mode, test.status, label, test.label)
+		}
+	}
+}
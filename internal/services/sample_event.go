@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// sampleTimestamp is the fixed timestamp used in synthesized events so that
+// the output is reproducible
+const sampleTimestamp = "2024-01-15T10:30:00.000Z"
+
+// GetSampleEvent returns an example event for each of an integration's data
+// streams: the package's sample_event.json when it has one, otherwise an
+// event synthesized from the field definitions
+func (i *IntegrationProvider) GetSampleEvent(integrationName, dataStream string) (shared.CallToolResult, error) {
+	title, streams, source, err := i.dataStreams(integrationName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	streams, err = selectStreams(streams, integrationName, dataStream)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s Sample Events\n\n**Source**: %s\n", title, source))
+
+	for _, stream := range streams {
+		result.WriteString(fmt.Sprintf("\n## %s (%s)\n\n", stream.Name, stream.Type))
+
+		event, note, err := i.sampleEvent(integrationName, stream)
+		if err != nil {
+			return shared.CallToolResult{}, fmt.Errorf("failed to format sample event for %s: %v", stream.Name, err)
+		}
+		result.WriteString(fmt.Sprintf("%s\n\n```json\n%s\n```\n", note, event))
+	}
+
+	return textResult(result.String()), nil
+}
+
+// sampleEvent returns a data stream's indented sample event and a note on
+// where it came from
+func (i *IntegrationProvider) sampleEvent(integrationName string, stream shared.IntegrationDataStream) (string, string, error) {
+	note := "Synthesized from the field definitions; values without an example are placeholders."
+	if len(stream.SampleEvent) > 0 {
+		var indented bytes.Buffer
+		err := json.Indent(&indented, bytes.TrimSpace(stream.SampleEvent), "", "  ")
+		if err == nil {
+			return indented.String(), "From the data stream's `sample_event.json`.", nil
+		}
+		note = fmt.Sprintf("The data stream's `sample_event.json` is not valid JSON (%v). %s", err, note)
+	}
+
+	event, err := formatJSON(i.synthesizeEvent(integrationName, stream))
+	return event, note, err
+}
+
+// synthesizeEvent builds an event from a data stream's field definitions,
+// using each field's example when it has one and a value generated from its
+// type otherwise. Fields declared with "external: ecs" take their type and
+// example from ECS when ECS definitions are configured.
+func (i *IntegrationProvider) synthesizeEvent(integrationName string, stream shared.IntegrationDataStream) map[string]interface{} {
+	dataset := datasetName(strings.ToLower(integrationName), stream.Name)
+	event := make(map[string]interface{})
+	setEventField(event, "@timestamp", sampleTimestamp)
+	setEventField(event, "data_stream.type", stream.Type)
+	setEventField(event, "data_stream.dataset", dataset)
+	setEventField(event, "data_stream.namespace", "default")
+	setEventField(event, "event.dataset", dataset)
+
+	for _, field := range stream.Fields {
+		fieldType, example := field.Type, field.Example
+		if ecsField, inECS, err := i.ecs.Lookup(field.Name); err == nil && inECS {
+			if fieldType == "" {
+				fieldType = ecsField.Type
+			}
+			if example == "" && ecsField.Example != nil {
+				example = fmt.Sprint(ecsField.Example)
+			}
+		}
+
+		if hasEventField(event, field.Name) {
+			continue
+		}
+		if value, ok := sampleValue(field.Name, fieldType, example); ok {
+			setEventField(event, field.Name, value)
+		}
+	}
+	return event
+}
+
+// sampleValue converts a field's example to its type, or generates a value
+// for the type. Object-like fields without an example produce no value since
+// their children are listed separately.
+func sampleValue(name, fieldType, example string) (interface{}, bool) {
+	if example != "" {
+		return convertExample(fieldType, example), true
+	}
+
+	segments := strings.Split(name, ".")
+	last := segments[len(segments)-1]
+	switch fieldType {
+	case "group", "object", "nested", "flattened", "":
+		return nil, false
+	case "date", "date_nanos":
+		return sampleTimestamp, true
+	case "ip":
+		return "192.0.2.10", true
+	case "long", "integer", "short", "byte", "unsigned_long":
+		return 1, true
+	case "float", "double", "half_float", "scaled_float":
+		return 0.5, true
+	case "boolean":
+		return true, true
+	case "geo_point":
+		return map[string]interface{}{"lat": 40.7128, "lon": -74.006}, true
+	case "array":
+		return []interface{}{}, true
+	default:
+		return "example-" + last, true
+	}
+}
+
+// convertExample parses an example written as text into the field's type,
+// keeping the text when it does not parse
+func convertExample(fieldType, example string) interface{} {
+	switch fieldType {
+	case "long", "integer", "short", "byte", "unsigned_long":
+		if value, err := strconv.ParseInt(example, 10, 64); err == nil {
+			return value
+		}
+	case "float", "double", "half_float", "scaled_float":
+		if value, err := strconv.ParseFloat(example, 64); err == nil {
+			return value
+		}
+	case "boolean":
+		if value, err := strconv.ParseBool(example); err == nil {
+			return value
+		}
+	case "array", "object", "geo_point", "flattened":
+		var value interface{}
+		if err := json.Unmarshal([]byte(example), &value); err == nil {
+			return value
+		}
+	}
+	return example
+}
+
+// setEventField sets a dotted field in a nested event. When a parent of the
+// field already holds a value, the dotted name is kept as a key instead.
+func setEventField(event map[string]interface{}, name string, value interface{}) {
+	segments := strings.Split(name, ".")
+	current := event
+	for index, segment := range segments[:len(segments)-1] {
+		next, exists := current[segment]
+		if !exists {
+			child := make(map[string]interface{})
+			current[segment] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			current[strings.Join(segments[index:], ".")] = value
+			return
+		}
+		current = child
+	}
+	current[segments[len(segments)-1]] = value
+}
+
+// hasEventField reports whether a dotted field is set in a nested event
+func hasEventField(event map[string]interface{}, name string) bool {
+	var current interface{} = event
+	for _, segment := range strings.Split(name, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if current, ok = object[segment]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// sandboxArg0 is the program name of the re-executed binary that sets up a
+// sandbox and then runs the step script
+const sandboxArg0 = "validation-step-sandbox"
+
+// lockedMountFlags are the mount flags that an unprivileged remount must keep
+const lockedMountFlags = syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC |
+	syscall.MS_NOATIME | syscall.MS_NODIRATIME | syscall.MS_RELATIME
+
+// sandboxUnavailable is nil: Linux steps run in namespaces
+var sandboxUnavailable error
+
+// init turns the re-executed binary into the sandbox of a step before any
+// other code runs
+func init() {
+	if dir, ok := os.LookupEnv(sandboxEnv); ok && len(os.Args) == 2 && os.Args[0] == sandboxArg0 {
+		enterSandbox(dir, os.Args[1])
+	}
+}
+
+// sandboxCommand re-executes the running binary in new user, mount, PID, IPC
+// and UTS namespaces, mapping the current user to root inside them. The
+// re-executed binary makes every mount read-only except the step directory,
+// mounts a /proc of its own PID namespace and then runs the script with sh.
+// The network is shared so that steps can reach Elasticsearch and Kibana.
+func sandboxCommand(ctx context.Context, script, dir string, env []string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{sandboxArg0, script}
+	cmd.Dir = dir
+	cmd.Env = append(env, sandboxEnv+"="+dir)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+	return cmd, nil
+}
+
+// enterSandbox isolates the file system of the re-executed binary and
+// replaces it with the step script. It never returns.
+func enterSandbox(dir, script string) {
+	if err := isolateFilesystem(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "%s%v\n", sandboxErrorPrefix, err)
+		os.Exit(sandboxSetupFailed)
+	}
+	os.Unsetenv(sandboxEnv)
+
+	shell, err := exec.LookPath("sh")
+	if err == nil {
+		err = syscall.Exec(shell, []string{"sh", "-e", "-c", script}, os.Environ())
+	}
+	fmt.Fprintf(os.Stderr, "%sfailed to run sh: %v\n", sandboxErrorPrefix, err)
+	os.Exit(sandboxSetupFailed)
+}
+
+// isolateFilesystem makes every mount read-only except dir, which stays
+// writable as a bind mount of its own, and mounts a new /proc
+func isolateFilesystem(dir string) error {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve step directory: %v", err)
+	}
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %v", err)
+	}
+	if err := syscall.Mount(dir, dir, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("failed to mount step directory: %v", err)
+	}
+	// The working directory was entered before the bind mount covered it
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("failed to enter step directory: %v", err)
+	}
+	if err := syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("failed to mount /proc: %v", err)
+	}
+
+	points, err := mountPoints()
+	if err != nil {
+		return err
+	}
+	for _, point := range points {
+		if point == dir {
+			continue
+		}
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(point, &stat); err != nil {
+			return fmt.Errorf("failed to read mount %s: %v", point, err)
+		}
+		flags := uintptr(stat.Flags)&lockedMountFlags | syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY
+		if err := syscall.Mount("", point, "", flags, ""); err != nil {
+			return fmt.Errorf("failed to make %s read-only: %v", point, err)
+		}
+	}
+	return nil
+}
+
+// mountPoints lists the mount points of the current mount namespace
+func mountPoints() ([]string, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mounts: %v", err)
+	}
+	defer file.Close()
+
+	var points []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		points = append(points, unescapeMountPoint(fields[4]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list mounts: %v", err)
+	}
+	return points, nil
+}
+
+// unescapeMountPoint decodes the octal escapes, such as \040 for a space,
+// that /proc/self/mountinfo uses in paths
+func unescapeMountPoint(point string) string {
+	var unescaped strings.Builder
+	for i := 0; i < len(point); i++ {
+		if point[i] == '\\' && i+3 < len(point) {
+			if value, err := strconv.ParseUint(point[i+1:i+4], 8, 8); err == nil {
+				unescaped.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		unescaped.WriteByte(point[i])
+	}
+	return unescaped.String()
+}
@@ -0,0 +1,21 @@
+package services
+
+import "testing"
+
+func TestUnescapeMountPoint(t *testing.T) {
+	tests := []struct {
+		point string
+		want  string
+	}{
+		{"/", "/"},
+		{"/mnt/my\\040disk", "/mnt/my disk"},
+		{"/mnt/tab\\011and\\134backslash", "/mnt/tab\tand\\backslash"},
+		{"/mnt/short\\04", "/mnt/short\\04"},
+	}
+
+	for _, test := range tests {
+		if got := unescapeMountPoint(test.point); got != test.want {
+			t.Errorf("unescapeMountPoint(%q) = %q, want %q", test.point, got, test.want)
+		}
+	}
+}
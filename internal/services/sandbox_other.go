@@ -0,0 +1,16 @@
+//go:build !linux
+
+package services
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// sandboxUnavailable explains why steps cannot be sandboxed on this platform
+var sandboxUnavailable = errors.New("validation steps can only be sandboxed on Linux; run them with the sandbox disabled inside a disposable container or VM")
+
+func sandboxCommand(ctx context.Context, script, dir string, env []string) (*exec.Cmd, error) {
+	return nil, sandboxUnavailable
+}
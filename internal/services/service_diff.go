@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// defaultDiffRevision is the git revision compared against when none is given
+const defaultDiffRevision = "HEAD"
+
+// changeLabels are the labels of change kinds in formatted diffs
+var changeLabels = map[string]string{
+	config.ChangeAdded:     "Added",
+	config.ChangeRemoved:   "Removed",
+	config.ChangeChanged:   "Changed",
+	config.ChangeReordered: "Reordered",
+}
+
+// DiffProvider compares versions of service configurations
+type DiffProvider struct {
+	configDir    string
+	configLoader *config.ConfigLoader
+}
+
+func NewDiffProvider(configDir string) *DiffProvider {
+	configLoader := config.NewConfigLoader(configDir)
+	if err := configLoader.LoadCommonTroubleshooting(); err != nil {
+		// References to the library then fail to resolve, which is reported
+		// when a version is loaded
+		configLoader = config.NewConfigLoader(configDir)
+	}
+
+	return &DiffProvider{
+		configDir:    configDir,
+		configLoader: configLoader,
+	}
+}
+
+// DiffRequest names the two versions to compare: either two files, or a
+// service's file at two git revisions
+type DiffRequest struct {
+	OldFile string
+	NewFile string
+	// ServiceName selects config/services/<service>.yaml when no files are given
+	ServiceName string
+	// From is the old revision, HEAD by default; To is the new revision, or
+	// the working tree file when empty
+	From string
+	To   string
+}
+
+// ServiceDiff is the semantic difference between two versions of a service configuration
+type ServiceDiff struct {
+	Service string                `json:"service"`
+	Title   string                `json:"title"`
+	Old     string                `json:"old"`
+	New     string                `json:"new"`
+	Changes []config.ConfigChange `json:"changes"`
+}
+
+// CompareServiceVersions reports the semantic changes between two versions
+// of a service configuration. Unlike Diff, it only reads files under the
+// config directory, since tool callers should not read arbitrary files.
+func (d *DiffProvider) CompareServiceVersions(request DiffRequest) (shared.CallToolResult, error) {
+	for _, file := range []*string{&request.OldFile, &request.NewFile} {
+		if *file == "" {
+			continue
+		}
+		path, err := d.configFile(*file)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		*file = path
+	}
+	diff, err := d.Diff(request)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	return textResult(FormatServiceDiff(diff)), nil
+}
+
+// Diff loads the two versions of a request and compares them
+func (d *DiffProvider) Diff(request DiffRequest) (*ServiceDiff, error) {
+	var oldData, newData []byte
+	diff := &ServiceDiff{}
+	switch {
+	case request.OldFile != "" || request.NewFile != "":
+		if request.OldFile == "" || request.NewFile == "" {
+			return nil, fmt.Errorf("both an old and a new file are required")
+		}
+		var err error
+		if oldData, err = ioutil.ReadFile(request.OldFile); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", request.OldFile, err)
+		}
+		if newData, err = ioutil.ReadFile(request.NewFile); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", request.NewFile, err)
+		}
+		diff.Old, diff.New = request.OldFile, request.NewFile
+
+	case request.ServiceName != "":
+		path, err := serviceFilePath(d.configDir, request.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		from := request.From
+		if from == "" {
+			from = defaultDiffRevision
+		}
+		if oldData, err = gitShow(path, from); err != nil {
+			return nil, err
+		}
+		diff.Old = fmt.Sprintf("%s at %s", path, from)
+		if request.To == "" {
+			if newData, err = ioutil.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", path, err)
+			}
+			diff.New = path + " (working tree)"
+		} else {
+			if newData, err = gitShow(path, request.To); err != nil {
+				return nil, err
+			}
+			diff.New = fmt.Sprintf("%s at %s", path, request.To)
+		}
+
+	default:
+		return nil, fmt.Errorf("service_name, or old_file and new_file, are required")
+	}
+
+	oldConfig, err := d.configLoader.ParseServiceConfig(oldData, diff.Old)
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := d.configLoader.ParseServiceConfig(newData, diff.New)
+	if err != nil {
+		return nil, err
+	}
+
+	diff.Service, diff.Title = newConfig.ServiceName, serviceTitle(newConfig)
+	diff.Changes = config.DiffServiceConfigs(oldConfig, newConfig)
+	return diff, nil
+}
+
+// serviceFilePath returns the file of a service in a config directory,
+// preferring .yaml over .yml. Names that are not a single path element are
+// rejected, so a service name cannot reach outside the services directory.
+func serviceFilePath(configDir, serviceName string) (string, error) {
+	if strings.ContainsAny(serviceName, `/\`) || serviceName == "." || serviceName == ".." {
+		return "", fmt.Errorf("invalid service name '%s'", serviceName)
+	}
+	base := filepath.Join(configDir, "services", strings.ToLower(serviceName))
+	if _, err := os.Stat(base + ".yaml"); os.IsNotExist(err) {
+		if _, err := os.Stat(base + ".yml"); err == nil {
+			return base + ".yml", nil
+		}
+	}
+	return base + ".yaml", nil
+}
+
+// configFile resolves a file named by a tool caller, relative to the config
+// directory, and rejects files outside it
+func (d *DiffProvider) configFile(file string) (string, error) {
+	root, err := filepath.Abs(d.configDir)
+	if err != nil {
+		return "", err
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	// Symbolic links are resolved so that a link cannot point outside
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolvedRoot
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not in the config directory", file)
+	}
+	return path, nil
+}
+
+// gitShow reads a file as of a git revision with git show, from the
+// repository containing the file's directory. The revision is resolved to a
+// commit first, so that it cannot be read as an option.
+func gitShow(path, revision string) ([]byte, error) {
+	if strings.HasPrefix(revision, "-") {
+		return nil, fmt.Errorf("invalid revision '%s'", revision)
+	}
+	commit, err := runGit(filepath.Dir(path), "rev-parse", "--verify", "--quiet", "--end-of-options", revision+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision '%s'", revision)
+	}
+	content, err := runGit(filepath.Dir(path), "show", strings.TrimSpace(string(commit))+":./"+filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %v", path, revision, err)
+	}
+	return content, nil
+}
+
+// runGit runs git in a directory and returns its output, or its error
+// output as the error
+func runGit(dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s", message)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// FormatServiceDiff renders a service diff as Markdown, grouped by section
+func FormatServiceDiff(diff *ServiceDiff) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s Configuration Changes\n\n**Old**: %s\n**New**: %s\n", diff.Title, diff.Old, diff.New))
+	if len(diff.Changes) == 0 {
+		result.WriteString("\nNo semantic changes.\n")
+		return result.String()
+	}
+
+	counts := make(map[string]int)
+	for _, change := range diff.Changes {
+		counts[change.Kind]++
+	}
+	result.WriteString(fmt.Sprintf("**Summary**: %d added, %d removed, %d changed, %d reordered\n",
+		counts[config.ChangeAdded], counts[config.ChangeRemoved], counts[config.ChangeChanged], counts[config.ChangeReordered]))
+
+	section := ""
+	for _, change := range diff.Changes {
+		if change.Section != section {
+			section = change.Section
+			result.WriteString(fmt.Sprintf("\n## %s\n\n", section))
+		}
+		result.WriteString(fmt.Sprintf("- **%s** %s\n", changeLabels[change.Kind], change.Item))
+		for _, detail := range change.Details {
+			result.WriteString(fmt.Sprintf("  - %s\n", detail))
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commitTestFiles writes files to a git repository and commits them
+func commitTestFiles(t *testing.T, repo string, files map[string]string) {
+	t.Helper()
+	writeTestFiles(t, repo, files)
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "update"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
+
+func TestCompareServiceVersions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	configDir := filepath.Join(repo, "config")
+	const version1 = "service_name: nginx\ntitle: Nginx\nsetup_instructions:\n  installation_steps:\n    - step: 1\n      title: Install\n"
+	const version2 = version1 + "    - step: 2\n      title: Configure\n"
+	const version3 = version2 + "    - step: 3\n      title: Start\n"
+	commitTestFiles(t, repo, map[string]string{"config/services/nginx.yaml": version1})
+	commitTestFiles(t, repo, map[string]string{"config/services/nginx.yaml": version2})
+	writeTestFiles(t, repo, map[string]string{
+		"config/services/nginx.yaml": version3,
+		"config/old/nginx.yaml":      version1,
+		"secrets.yaml":               version1,
+	})
+	provider := NewDiffProvider(configDir)
+
+	tests := []struct {
+		name     string
+		request  DiffRequest
+		contains []string
+		err      string
+	}{
+		{
+			name:     "working tree against HEAD",
+			request:  DiffRequest{ServiceName: "nginx"},
+			contains: []string{"at HEAD", "(working tree)", "- **Added** Step 3: Start"},
+		},
+		{
+			name:     "two revisions",
+			request:  DiffRequest{ServiceName: "Nginx", From: "HEAD~1", To: "HEAD"},
+			contains: []string{"**Summary**: 1 added, 0 removed, 0 changed, 0 reordered", "- **Added** Step 2: Configure"},
+		},
+		{
+			name:     "no changes",
+			request:  DiffRequest{ServiceName: "nginx", From: "HEAD", To: "HEAD"},
+			contains: []string{"No semantic changes."},
+		},
+		{
+			name:     "files in the config directory",
+			request:  DiffRequest{OldFile: "old/nginx.yaml", NewFile: "services/nginx.yaml"},
+			contains: []string{"- **Added** Step 2: Configure", "- **Added** Step 3: Start"},
+		},
+		{
+			name:    "file outside the config directory",
+			request: DiffRequest{OldFile: "../secrets.yaml", NewFile: "services/nginx.yaml"},
+			err:     "../secrets.yaml is not in the config directory",
+		},
+		{
+			name:    "service name with a path",
+			request: DiffRequest{ServiceName: "../nginx"},
+			err:     "invalid service name '../nginx'",
+		},
+		{
+			name:    "revision that looks like an option",
+			request: DiffRequest{ServiceName: "nginx", From: "--output=/tmp/x"},
+			err:     "invalid revision '--output=/tmp/x'",
+		},
+		{
+			name:    "unknown revision",
+			request: DiffRequest{ServiceName: "nginx", From: "v9.9.9"},
+			err:     "unknown revision 'v9.9.9'",
+		},
+		{
+			name:    "nothing to compare",
+			request: DiffRequest{},
+			err:     "service_name, or old_file and new_file, are required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := provider.CompareServiceVersions(test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].Text
+			if test.err != "" {
+				if !result.IsError || text != test.err {
+					t.Errorf("CompareServiceVersions = %q, want error %q", text, test.err)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("CompareServiceVersions failed: %s", text)
+			}
+			for _, want := range test.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("output does not contain %q:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
@@ -13,7 +13,7 @@ type ServiceInfoProvider struct {
 }
 
 func NewServiceInfoProvider(configDir string) *ServiceInfoProvider {
-	configLoader := config.NewConfigLoader(configDir)
+	configLoader := config.NewConfigLoaderFromEnv(configDir)
 	if err := configLoader.LoadAllServices(); err != nil {
 		// In a real implementation, you might want to handle this error differently
 		// For now, we'll create an empty loader
@@ -39,9 +39,14 @@ func (s *ServiceInfoProvider) GetServiceInfo(serviceName string) (shared.CallToo
 		}, nil
 	}
 
+	notice := ""
+	if text := statusNotice(s.configLoader, serviceConfig.SectionStatus(serviceConfig.ServiceInfo.Status)); text != "" {
+		notice = "\n> " + text + "\n"
+	}
+
 	// Format the service info according to the requirements
 	info := fmt.Sprintf(`# %s Service Information
-
+%s
 ## Common Use Cases
 %s
 
@@ -51,6 +56,7 @@ func (s *ServiceInfoProvider) GetServiceInfo(serviceName string) (shared.CallToo
 ## Compatibility
 - **Elastic Stack Versions**: %s
 - **Service Versions**: %s
+- **Setup Platforms**: %s
 
 ## Scaling and Performance
 %s
@@ -61,10 +67,12 @@ func (s *ServiceInfoProvider) GetServiceInfo(serviceName string) (shared.CallToo
 ### Scaling Guidance
 %s`,
 		serviceConfig.Title,
+		notice,
 		formatList(serviceConfig.ServiceInfo.CommonUseCases),
 		formatList(serviceConfig.ServiceInfo.DataTypesCollected),
 		strings.Join(serviceConfig.ServiceInfo.Compatibility.ElasticStackVersions, ", "),
 		strings.Join(serviceConfig.ServiceInfo.Compatibility.ServiceVersions, ", "),
+		formatSetupPlatforms(serviceConfig.SetupInstructions.Platforms()),
 		serviceConfig.ServiceInfo.ScalingAndPerformance.Description,
 		formatList(serviceConfig.ServiceInfo.ScalingAndPerformance.PerformanceExpectations),
 		formatList(serviceConfig.ServiceInfo.ScalingAndPerformance.ScalingGuidance))
@@ -78,3 +86,15 @@ func (s *ServiceInfoProvider) GetServiceInfo(serviceName string) (shared.CallToo
 		},
 	}, nil
 }
+
+// LoadErrors returns the errors of the service files that failed to load
+func (s *ServiceInfoProvider) LoadErrors() []error {
+	return s.configLoader.LoadErrors()
+}
+
+func formatSetupPlatforms(platforms []string) string {
+	if len(platforms) == 0 {
+		return "all (no platform-specific steps)"
+	}
+	return strings.Join(platforms, ", ")
+}
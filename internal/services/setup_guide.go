@@ -1,11 +1,14 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"elastic-integration-docs-mcp/internal/config"
 	"elastic-integration-docs-mcp/internal/shared"
+	"elastic-integration-docs-mcp/internal/version"
 )
 
 type SetupGuideProvider struct {
@@ -13,7 +16,7 @@ type SetupGuideProvider struct {
 }
 
 func NewSetupGuideProvider(configDir string) *SetupGuideProvider {
-	configLoader := config.NewConfigLoader(configDir)
+	configLoader := config.NewConfigLoaderFromEnv(configDir)
 	if err := configLoader.LoadAllServices(); err != nil {
 		// In a real implementation, you might want to handle this error differently
 		// For now, we'll create an empty loader
@@ -25,7 +28,7 @@ func NewSetupGuideProvider(configDir string) *SetupGuideProvider {
 	}
 }
 
-func (s *SetupGuideProvider) GetServiceSetupInstructions(serviceName, version string) (shared.CallToolResult, error) {
+func (s *SetupGuideProvider) GetServiceSetupInstructions(serviceName, serviceVersion, platform string) (shared.CallToolResult, error) {
 	serviceConfig, err := s.configLoader.GetServiceConfig(serviceName)
 	if err != nil {
 		return shared.CallToolResult{
@@ -39,23 +42,55 @@ func (s *SetupGuideProvider) GetServiceSetupInstructions(serviceName, version st
 		}, nil
 	}
 
+	parsedVersion, versionWarning := checkServiceVersion(serviceConfig, serviceVersion)
+
 	versionInfo := ""
-	if version != "" {
-		versionInfo = fmt.Sprintf("\n**Version**: %s", version)
+	if serviceVersion != "" {
+		versionInfo = fmt.Sprintf("\n**Version**: %s", serviceVersion)
+	}
+	if versionWarning != "" {
+		versionInfo += "\n\n> " + versionWarning
+	}
+	if notice := statusNotice(s.configLoader, serviceConfig.SectionStatus(serviceConfig.SetupInstructions.Status)); notice != "" {
+		versionInfo += "\n\n> " + notice
+	}
+
+	platforms := serviceConfig.SetupInstructions.Platforms()
+	platformInfo := ""
+	normalized := ""
+	if platform != "" {
+		normalized, err = config.NormalizePlatform(platform)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+		platformInfo = fmt.Sprintf("\n**Platform**: %s", normalized)
+		if !containsString(platforms, normalized) {
+			platformInfo += fmt.Sprintf("\n\n> No %s-specific steps are defined for this service; showing the generic steps.", normalized)
+		}
 	}
 
-	instructions := fmt.Sprintf(`# %s Setup Instructions%s
+	steps, err := serviceConfig.SetupInstructions.SelectSteps(normalized, parsedVersion)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid setup instructions for %s: %v", serviceConfig.ServiceName, err)), nil
+	}
+
+	instructions := fmt.Sprintf(`# %s Setup Instructions%s%s
 
 ## Prerequisites
 %s
 
+## Supported Platforms
+%s
+
 ## Installation Steps
 
 %s`,
 		strings.ToUpper(serviceConfig.ServiceName),
 		versionInfo,
+		platformInfo,
 		formatList(serviceConfig.SetupInstructions.Prerequisites),
-		formatInstallationSteps(serviceConfig.SetupInstructions.InstallationSteps))
+		formatPlatforms(platforms, normalized),
+		formatInstallationSteps(steps, platform == "", serviceVersion == ""))
 
 	return shared.CallToolResult{
 		Content: []shared.ToolContent{
@@ -67,7 +102,7 @@ func (s *SetupGuideProvider) GetServiceSetupInstructions(serviceName, version st
 	}, nil
 }
 
-func (s *SetupGuideProvider) GetKibanaSetupInstructions(serviceName, inputType, version string) (shared.CallToolResult, error) {
+func (s *SetupGuideProvider) GetKibanaSetupInstructions(serviceName, inputType, serviceVersion string) (shared.CallToolResult, error) {
 	serviceConfig, err := s.configLoader.GetServiceConfig(serviceName)
 	if err != nil {
 		return shared.CallToolResult{
@@ -82,32 +117,49 @@ func (s *SetupGuideProvider) GetKibanaSetupInstructions(serviceName, inputType,
 	}
 
 	// Select the appropriate setup instructions based on input type
-	var steps []config.KibanaSetupStep
-	if inputType != "" {
-		switch strings.ToLower(inputType) {
-		case "tcp":
-			if serviceConfig.KibanaSetupInstructions.TCP.Steps != nil {
-				steps = serviceConfig.KibanaSetupInstructions.TCP.Steps
-			}
-		case "udp":
-			if serviceConfig.KibanaSetupInstructions.UDP.Steps != nil {
-				steps = serviceConfig.KibanaSetupInstructions.UDP.Steps
-			}
-		}
+	kibanaSteps, found := serviceConfig.KibanaSetupInstructions.Lookup(inputType)
+	if !found {
+		return errorResult(fmt.Sprintf("input type '%s' is not available for %s. Available input types: %s",
+			inputType, serviceConfig.ServiceName, strings.Join(serviceConfig.KibanaSetupInstructions.InputTypes(), ", "))), nil
 	}
+	steps := kibanaSteps.Steps
 
-	// Fall back to default if no specific input type or if not found
-	if steps == nil {
-		steps = serviceConfig.KibanaSetupInstructions.Default.Steps
+	parsedVersion, versionWarning := checkServiceVersion(serviceConfig, serviceVersion)
+	steps, err = config.SelectKibanaSteps(steps, parsedVersion)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid Kibana setup instructions for %s: %v", serviceConfig.ServiceName, err)), nil
 	}
 
 	// Format the steps as JSON-like structure as shown in requirements
 	var stepsJSON strings.Builder
-	stepsJSON.WriteString("{\n  \"steps\": [\n")
+	stepsJSON.WriteString("{\n")
+	if versionWarning != "" {
+		stepsJSON.WriteString(fmt.Sprintf("  \"warning\": %s,\n", jsonString(versionWarning)))
+	}
+	if status := labelledStatus(s.configLoader, serviceConfig.SectionStatus(kibanaSteps.Status)); status != "" {
+		stepsJSON.WriteString(fmt.Sprintf("  \"status\": \"%s\",\n", status))
+	}
+	stepsJSON.WriteString("  \"steps\": [\n")
 
 	for i, step := range steps {
-		stepsJSON.WriteString(fmt.Sprintf("    {\n      \"step\": %d,\n      \"instruction\": \"%s\"\n    }",
-			step.Step, step.Instruction))
+		stepsJSON.WriteString(fmt.Sprintf("    {\n      \"step\": %d,\n      \"instruction\": %s", step.Step, jsonString(step.Instruction)))
+		if step.VerifiedOn != "" {
+			stepsJSON.WriteString(fmt.Sprintf(",\n      \"verified_on\": %s", jsonString(step.VerifiedOn)))
+		}
+		if len(step.VerifiedVersions) > 0 {
+			versions := make([]string, len(step.VerifiedVersions))
+			for j, v := range step.VerifiedVersions {
+				versions[j] = jsonString(v)
+			}
+			stepsJSON.WriteString(fmt.Sprintf(",\n      \"verified_versions\": [%s]", strings.Join(versions, ", ")))
+		}
+		if step.Author != "" {
+			stepsJSON.WriteString(fmt.Sprintf(",\n      \"author\": %s", jsonString(step.Author)))
+		}
+		if step.SourceURL != "" {
+			stepsJSON.WriteString(fmt.Sprintf(",\n      \"source_url\": %s", jsonString(step.SourceURL)))
+		}
+		stepsJSON.WriteString("\n    }")
 		if i < len(steps)-1 {
 			stepsJSON.WriteString(",")
 		}
@@ -126,10 +178,31 @@ func (s *SetupGuideProvider) GetKibanaSetupInstructions(serviceName, inputType,
 	}, nil
 }
 
-func formatInstallationSteps(steps []config.InstallationStep) string {
+// formatInstallationSteps renders installation steps as markdown. When
+// showPlatforms or showVersions is set, each step notes which platforms or
+// service versions it is limited to or has variants for.
+func formatInstallationSteps(steps []config.InstallationStep, showPlatforms, showVersions bool) string {
 	var result strings.Builder
 	for _, step := range steps {
 		result.WriteString(fmt.Sprintf("\n### Step %d: %s\n%s\n\n", step.Step, step.Title, step.Description))
+		result.WriteString(formatProvenance(step.Provenance))
+
+		if showPlatforms && len(step.OnlyPlatforms) > 0 {
+			result.WriteString(fmt.Sprintf("*Applies to: %s*\n\n", strings.Join(step.OnlyPlatforms, ", ")))
+		}
+		if showPlatforms && len(step.Platforms) > 0 {
+			result.WriteString(fmt.Sprintf("*Platform-specific instructions available for: %s*\n\n", strings.Join(sortedVariantNames(step.Platforms), ", ")))
+		}
+		if showVersions && step.VersionRange != "" {
+			result.WriteString(fmt.Sprintf("*Applies to service versions: %s*\n\n", step.VersionRange))
+		}
+		if showVersions && len(step.Versions) > 0 {
+			ranges := make([]string, 0, len(step.Versions))
+			for _, variant := range step.Versions {
+				ranges = append(ranges, variant.Range)
+			}
+			result.WriteString(fmt.Sprintf("*Version-specific instructions available for: %s*\n\n", strings.Join(ranges, "; ")))
+		}
 
 		if len(step.Commands) > 0 {
 			result.WriteString("**Commands:**\n```bash\n")
@@ -143,6 +216,7 @@ func formatInstallationSteps(steps []config.InstallationStep) string {
 			for _, snippet := range step.ConfigSnippets {
 				result.WriteString(fmt.Sprintf("**Configuration File: %s**\n```%s\n%s\n```\n\n",
 					snippet.Filename, getFileExtension(snippet.Filename), snippet.Content))
+				result.WriteString(formatProvenance(snippet.Provenance))
 			}
 		}
 
@@ -170,3 +244,75 @@ func getFileExtension(filename string) string {
 		return "text"
 	}
 }
+
+// formatPlatforms lists the platforms with specific steps. The hint to pass
+// a platform is only given when none was requested.
+func formatPlatforms(platforms []string, requested string) string {
+	if len(platforms) == 0 {
+		return "No platform-specific steps; the steps below apply to all platforms.\n"
+	}
+	list := fmt.Sprintf("Platform-specific steps are available for: %s.", strings.Join(platforms, ", "))
+	if requested == "" {
+		list += " Pass `platform` to get them."
+	}
+	return list + "\n"
+}
+
+func sortedVariantNames(variants map[string]config.PlatformVariant) []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonString quotes text as a JSON string
+func jsonString(text string) string {
+	encoded, _ := json.Marshal(text)
+	return string(encoded)
+}
+
+// checkServiceVersion parses the requested service version and returns a
+// warning when it falls outside the service's compatible versions. An empty
+// version, or one that is not a version number such as "latest", yields a
+// nil version so that no steps are filtered; the latter with a warning.
+func checkServiceVersion(serviceConfig *config.ServiceConfig, serviceVersion string) (*version.Version, string) {
+	if serviceVersion == "" {
+		return nil, ""
+	}
+
+	parsed, err := version.Parse(serviceVersion)
+	if err != nil {
+		return nil, fmt.Sprintf("**Warning**: %s is not a version number, so steps for all service versions are shown.", serviceVersion)
+	}
+
+	warning := ""
+	compatibility := serviceConfig.ServiceInfo.Compatibility
+	if supported, checked := compatibility.CheckServiceVersion(parsed); checked && !supported {
+		warning = fmt.Sprintf("**Warning**: %s %s is outside the supported service versions (%s). These instructions may not apply.",
+			serviceConfig.Title, serviceVersion, strings.Join(compatibility.ServiceVersions, ", "))
+	}
+	return &parsed, warning
+}
+
+func (s *SetupGuideProvider) ListKibanaInputTypes(serviceName string) (shared.CallToolResult, error) {
+	serviceConfig, err := s.configLoader.GetServiceConfig(serviceName)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	var inputTypes []string
+	for _, inputType := range serviceConfig.KibanaSetupInstructions.InputTypes() {
+		steps := serviceConfig.KibanaSetupInstructions[inputType].Steps
+		inputTypes = append(inputTypes, fmt.Sprintf("%s (%d steps)", inputType, len(steps)))
+	}
+
+	return textResult(fmt.Sprintf(`# %s Kibana Input Types
+
+%s
+Pass one of these as input_type to get_kibana_setup_instructions; "%s" is used when input_type is omitted.`,
+		serviceConfig.Title,
+		formatList(inputTypes),
+		config.DefaultKibanaInputType)), nil
+}
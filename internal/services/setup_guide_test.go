@@ -0,0 +1,100 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetupInstructionsPlatforms(t *testing.T) {
+	configDir := t.TempDir()
+	writeTestFiles(t, configDir, map[string]string{
+		"services/nginx.yaml": `service_name: nginx
+title: Nginx
+setup_instructions:
+  installation_steps:
+    - step: 1
+      title: Install Nginx
+      commands: [apt-get install nginx]
+      platforms:
+        rhel:
+          commands: [dnf install nginx]
+`,
+		"services/apache.yaml": `service_name: apache
+title: Apache
+setup_instructions:
+  installation_steps:
+    - step: 1
+      title: Install Apache
+`,
+	})
+	provider := NewSetupGuideProvider(configDir)
+
+	tests := []struct {
+		name     string
+		service  string
+		platform string
+		contains []string
+		excludes []string
+		err      string
+	}{
+		{
+			name:     "no platform",
+			service:  "nginx",
+			contains: []string{"Platform-specific steps are available for: rhel. Pass `platform` to get them.\n", "apt-get install nginx"},
+		},
+		{
+			name:     "platform with specific steps",
+			service:  "nginx",
+			platform: "RHEL",
+			contains: []string{"**Platform**: rhel", "Platform-specific steps are available for: rhel.\n", "dnf install nginx"},
+			excludes: []string{"Pass `platform`", "apt-get install nginx"},
+		},
+		{
+			name:     "platform without specific steps",
+			service:  "nginx",
+			platform: "macos",
+			contains: []string{"No macos-specific steps are defined for this service", "Platform-specific steps are available for: rhel.\n"},
+			excludes: []string{"Pass `platform`"},
+		},
+		{
+			name:     "service without platform variants",
+			service:  "apache",
+			contains: []string{"No platform-specific steps; the steps below apply to all platforms.\n"},
+		},
+		{
+			name:     "unknown platform",
+			service:  "nginx",
+			platform: "amiga",
+			err:      "amiga",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := provider.GetServiceSetupInstructions(test.service, "", test.platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].Text
+			if test.err != "" {
+				if !result.IsError || !strings.Contains(text, test.err) {
+					t.Errorf("GetServiceSetupInstructions = %q, want an error naming %q", text, test.err)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("GetServiceSetupInstructions failed: %s", text)
+			}
+			for _, want := range test.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("output does not contain %q:\n%s", want, text)
+				}
+			}
+			for _, unwanted := range test.excludes {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, text)
+				}
+			}
+		})
+	}
+}
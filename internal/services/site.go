@@ -0,0 +1,360 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/markdown"
+)
+
+// Site export formats
+const (
+	SiteFormatHTML   = "html"
+	SiteFormatMkDocs = "mkdocs"
+)
+
+// siteName is the title of the exported site
+const siteName = "Elastic Integration Catalog"
+
+// uncategorized is the category of services without configured or integration categories
+const uncategorized = "other"
+
+//go:embed templates/site
+var siteAssets embed.FS
+
+// markdownLink matches relative links to Markdown pages, which become links
+// to HTML pages in the HTML export
+var markdownLink = regexp.MustCompile(`^([^:]+)\.md(#.*)?$`)
+
+// SiteOptions configures a static site export
+type SiteOptions struct {
+	// Dir is the output directory
+	Dir string
+	// Format is SiteFormatHTML (the default) or SiteFormatMkDocs
+	Format string
+	// Template replaces the configured or built-in README template
+	Template string
+}
+
+// SiteSummary counts the pages of an exported site
+type SiteSummary struct {
+	Services     int
+	Categories   int
+	SharedIssues int
+}
+
+// SiteSearchEntry is an entry of the site's search_index.json
+type SiteSearchEntry struct {
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Kind        string   `json:"kind"`
+	Description string   `json:"description,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Headings    []string `json:"headings,omitempty"`
+}
+
+// sitePage is a Markdown page of the site, with its path relative to the site root
+type sitePage struct {
+	path     string
+	title    string
+	markdown string
+}
+
+// sharedIssue is a troubleshooting library entry with the services that reference it
+type sharedIssue struct {
+	issue    config.TroubleshootingIssue
+	services []*config.ServiceConfig
+}
+
+// ExportSite renders every service into a static site: a page per service
+// from the README template, a page per shared troubleshooting entry linking
+// the services that use it, an index by category and a search index.
+func (r *ReadmeProvider) ExportSite(options SiteOptions) (SiteSummary, error) {
+	if r.loadErr != nil {
+		return SiteSummary{}, r.loadErr
+	}
+	var summary SiteSummary
+	if options.Format == "" {
+		options.Format = SiteFormatHTML
+	}
+	if options.Format != SiteFormatHTML && options.Format != SiteFormatMkDocs {
+		return summary, fmt.Errorf("unsupported site format '%s'; use %s or %s", options.Format, SiteFormatHTML, SiteFormatMkDocs)
+	}
+	tmpl, err := r.parseTemplate(options.Template)
+	if err != nil {
+		return summary, err
+	}
+
+	names := r.configLoader.GetAllServiceNames()
+	sort.Strings(names)
+	issues := r.sharedIssues(names)
+
+	var pages []sitePage
+	var index []SiteSearchEntry
+	categories := make(map[string][]*config.ServiceConfig)
+	for _, name := range names {
+		serviceConfig, err := r.configLoader.GetServiceConfig(name)
+		if err != nil {
+			return summary, err
+		}
+		readme, data, err := r.render(tmpl, serviceConfig, "")
+		if err != nil {
+			return summary, fmt.Errorf("%s: %v", name, err)
+		}
+		readme = strings.TrimRight(readme, "\n") + "\n" + formatSharedIssueLinks(serviceConfig, issues)
+
+		serviceCategories := serviceConfig.Categories
+		if len(serviceCategories) == 0 && data.Integration != nil {
+			serviceCategories = data.Integration.Categories
+		}
+		if len(serviceCategories) == 0 {
+			serviceCategories = []string{uncategorized}
+		}
+		for _, category := range serviceCategories {
+			categories[category] = append(categories[category], serviceConfig)
+		}
+
+		page := sitePage{path: servicePagePath(name), title: serviceTitle(serviceConfig), markdown: readme}
+		pages = append(pages, page)
+		index = append(index, SiteSearchEntry{
+			Title:       page.title,
+			URL:         pageURL(page.path),
+			Kind:        "service",
+			Description: serviceConfig.Description,
+			Categories:  serviceCategories,
+			Headings:    markdown.Headings(readme),
+		})
+	}
+
+	for _, id := range sortedKeys(issues) {
+		page := sitePage{path: issuePagePath(id), title: issues[id].issue.Issue, markdown: formatSharedIssuePage(id, issues[id])}
+		pages = append(pages, page)
+		index = append(index, SiteSearchEntry{
+			Title:       page.title,
+			URL:         pageURL(page.path),
+			Kind:        "troubleshooting",
+			Description: strings.Join(issues[id].issue.Symptoms, "; "),
+			Headings:    markdown.Headings(page.markdown),
+		})
+	}
+	pages = append(pages, sitePage{path: "index.md", title: siteName, markdown: formatSiteIndex(categories, issues)})
+
+	searchIndex, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return summary, fmt.Errorf("failed to encode search index: %v", err)
+	}
+	if options.Format == SiteFormatMkDocs {
+		err = writeMkDocsSite(options.Dir, pages, searchIndex)
+	} else {
+		err = writeHTMLSite(options.Dir, pages, searchIndex)
+	}
+	if err != nil {
+		return summary, err
+	}
+
+	summary.Services = len(names)
+	summary.Categories = len(categories)
+	summary.SharedIssues = len(issues)
+	return summary, nil
+}
+
+// sharedIssues collects the troubleshooting library entries referenced by
+// the services, keyed by ID
+func (r *ReadmeProvider) sharedIssues(names []string) map[string]*sharedIssue {
+	issues := make(map[string]*sharedIssue)
+	for _, name := range names {
+		serviceConfig, err := r.configLoader.GetServiceConfig(name)
+		if err != nil {
+			continue
+		}
+		for _, issue := range serviceConfig.Troubleshooting.CommonIssues {
+			if issue.Ref == "" {
+				continue
+			}
+			if _, exists := issues[issue.Ref]; !exists {
+				issues[issue.Ref] = &sharedIssue{issue: issue}
+			}
+			issues[issue.Ref].services = append(issues[issue.Ref].services, serviceConfig)
+		}
+	}
+	return issues
+}
+
+// formatSharedIssueLinks lists the shared troubleshooting entries of a
+// service page with links to the pages of the entries
+func formatSharedIssueLinks(serviceConfig *config.ServiceConfig, issues map[string]*sharedIssue) string {
+	var result strings.Builder
+	for _, issue := range serviceConfig.Troubleshooting.CommonIssues {
+		entry, exists := issues[issue.Ref]
+		if issue.Ref == "" || !exists {
+			continue
+		}
+		if result.Len() == 0 {
+			result.WriteString("\n## Shared troubleshooting entries\n\n")
+		}
+		others := len(entry.services) - 1
+		switch others {
+		case 0:
+			result.WriteString(fmt.Sprintf("- [%s](../%s)\n", issue.Issue, issuePagePath(issue.Ref)))
+		case 1:
+			result.WriteString(fmt.Sprintf("- [%s](../%s), also listed for 1 other service\n", issue.Issue, issuePagePath(issue.Ref)))
+		default:
+			result.WriteString(fmt.Sprintf("- [%s](../%s), also listed for %d other services\n", issue.Issue, issuePagePath(issue.Ref), others))
+		}
+	}
+	return result.String()
+}
+
+func formatSharedIssuePage(id string, entry *sharedIssue) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s\n\n**ID**: `%s`\n", entry.issue.Issue, id))
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Symptoms", entry.issue.Symptoms},
+		{"Possible causes", entry.issue.Causes},
+		{"Solutions", entry.issue.AllSolutions()},
+		{"Prevention", entry.issue.Prevention},
+	}
+	for _, section := range sections {
+		if len(section.items) > 0 {
+			result.WriteString(fmt.Sprintf("\n## %s\n\n%s", section.title, formatList(section.items)))
+		}
+	}
+
+	result.WriteString("\n## Services\n\n")
+	for _, serviceConfig := range entry.services {
+		result.WriteString(fmt.Sprintf("- [%s](../%s)\n", serviceTitle(serviceConfig), servicePagePath(serviceConfig.ServiceName)))
+	}
+	return result.String()
+}
+
+func formatSiteIndex(categories map[string][]*config.ServiceConfig, issues map[string]*sharedIssue) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s\n\nSetup, validation and troubleshooting guides for Elastic integrations, by category.\n", siteName))
+
+	for _, category := range sortedKeys(categories) {
+		result.WriteString(fmt.Sprintf("\n## %s\n\n", categoryTitle(category)))
+		for _, serviceConfig := range categories[category] {
+			result.WriteString(fmt.Sprintf("- [%s](%s)", serviceTitle(serviceConfig), servicePagePath(serviceConfig.ServiceName)))
+			if serviceConfig.Description != "" {
+				result.WriteString(": " + serviceConfig.Description)
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	if len(issues) > 0 {
+		result.WriteString("\n## Shared troubleshooting entries\n\n")
+		for _, id := range sortedKeys(issues) {
+			result.WriteString(fmt.Sprintf("- [%s](%s) (%d services)\n", issues[id].issue.Issue, issuePagePath(id), len(issues[id].services)))
+		}
+	}
+	return result.String()
+}
+
+// writeHTMLSite converts the pages to HTML and writes them with the
+// stylesheet, search script and search index
+func writeHTMLSite(dir string, pages []sitePage, searchIndex []byte) error {
+	layout, err := template.ParseFS(siteAssets, "templates/site/page.html")
+	if err != nil {
+		return fmt.Errorf("invalid site page template: %v", err)
+	}
+
+	for _, page := range pages {
+		root := strings.Repeat("../", strings.Count(page.path, "/"))
+		content := markdown.ToHTML(page.markdown, htmlLink)
+		var output bytes.Buffer
+		err := layout.Execute(&output, map[string]interface{}{
+			"SiteName": siteName,
+			"Title":    page.title,
+			"Root":     root,
+			"Search":   page.path == "index.md",
+			"Content":  template.HTML(content),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %v", page.path, err)
+		}
+		if err := writeSiteFile(dir, pageURL(page.path), output.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	for _, asset := range []string{"style.css", "search.js"} {
+		content, err := siteAssets.ReadFile("templates/site/" + asset)
+		if err != nil {
+			return err
+		}
+		if err := writeSiteFile(dir, asset, content); err != nil {
+			return err
+		}
+	}
+	return writeSiteFile(dir, "search_index.json", searchIndex)
+}
+
+// writeMkDocsSite writes the pages as Markdown under docs/ with an mkdocs.yml
+func writeMkDocsSite(dir string, pages []sitePage, searchIndex []byte) error {
+	for _, page := range pages {
+		if err := writeSiteFile(dir, filepath.Join("docs", page.path), []byte(page.markdown)); err != nil {
+			return err
+		}
+	}
+	if err := writeSiteFile(dir, filepath.Join("docs", "search_index.json"), searchIndex); err != nil {
+		return err
+	}
+	// Page URLs in the search index end in .html, which needs use_directory_urls off
+	mkdocs := fmt.Sprintf("site_name: %s\ndocs_dir: docs\nuse_directory_urls: false\n", siteName)
+	return writeSiteFile(dir, "mkdocs.yml", []byte(mkdocs))
+}
+
+func writeSiteFile(dir, path string, content []byte) error {
+	path = filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// htmlLink points relative links to Markdown pages at the HTML pages
+func htmlLink(target string) string {
+	return markdownLink.ReplaceAllString(target, "$1.html$2")
+}
+
+func pageURL(path string) string {
+	return strings.TrimSuffix(path, ".md") + ".html"
+}
+
+func servicePagePath(name string) string {
+	return "services/" + name + ".md"
+}
+
+func issuePagePath(id string) string {
+	return "issues/" + id + ".md"
+}
+
+func serviceTitle(serviceConfig *config.ServiceConfig) string {
+	if serviceConfig.Title != "" {
+		return serviceConfig.Title
+	}
+	return serviceConfig.ServiceName
+}
+
+// categoryTitle turns a category ID such as database_security into a heading
+func categoryTitle(category string) string {
+	title := strings.ReplaceAll(category, "_", " ")
+	return strings.ToUpper(title[:1]) + title[1:]
+}
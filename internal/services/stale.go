@@ -0,0 +1,259 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+	"elastic-integration-docs-mcp/internal/version"
+)
+
+// DefaultStaleAge is how long verified content stays fresh unless a maximum
+// age is given
+const DefaultStaleAge = "1y"
+
+// staleAge matches ages such as 90d, 12w, 6m or 2y
+var staleAge = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// StaleProvider reports curated content whose verification is out of date
+type StaleProvider struct {
+	configLoader *config.ConfigLoader
+	// loadErr is reported by Stale, so that an unreadable config directory
+	// fails a staleness check instead of passing it with nothing checked
+	loadErr error
+}
+
+func NewStaleProvider(configDir string) *StaleProvider {
+	configLoader := config.NewConfigLoader(configDir)
+	loadErr := configLoader.LoadAllServices()
+	if loadErr != nil {
+		configLoader = config.NewConfigLoader(configDir)
+	}
+
+	return &StaleProvider{
+		configLoader: configLoader,
+		loadErr:      loadErr,
+	}
+}
+
+// StaleOptions selects the content checked for staleness
+type StaleOptions struct {
+	// ServiceNames limits the report to these services; all when empty
+	ServiceNames []string
+	// MaxAge is how long ago content may have been verified, such as 90d,
+	// 12w, 6m or 1y; DefaultStaleAge when empty
+	MaxAge string
+	// Unverified also reports content without a verified_on date
+	Unverified bool
+	// Now is the time ages are measured from; the current time when zero
+	Now time.Time
+}
+
+// StaleItem is a step, snippet or issue whose verification is out of date
+type StaleItem struct {
+	Service          string   `json:"service"`
+	Section          string   `json:"section"`
+	Item             string   `json:"item"`
+	VerifiedOn       string   `json:"verifiedOn,omitempty"`
+	VerifiedVersions []string `json:"verifiedVersions,omitempty"`
+	Author           string   `json:"author,omitempty"`
+	SourceURL        string   `json:"sourceUrl,omitempty"`
+	Reasons          []string `json:"reasons"`
+}
+
+// StaleReport lists stale content across services
+type StaleReport struct {
+	MaxAge string `json:"maxAge"`
+	Cutoff string `json:"cutoff"`
+	// Checked counts every item that can carry provenance, and Verified
+	// those with a verified_on date
+	Checked  int         `json:"checked"`
+	Verified int         `json:"verified"`
+	Items    []StaleItem `json:"items"`
+	// Errors lists service files that failed to load and were not checked
+	Errors []string `json:"errors,omitempty"`
+}
+
+// FindStaleContent reports content verified longer ago than maxAge, or
+// against an Elastic Stack version older than the supported range
+func (p *StaleProvider) FindStaleContent(serviceName, maxAge string, unverified bool) (shared.CallToolResult, error) {
+	options := StaleOptions{MaxAge: maxAge, Unverified: unverified}
+	if serviceName != "" {
+		options.ServiceNames = []string{serviceName}
+	}
+	report, err := p.Stale(options)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+	return textResult(FormatStaleReport(report)), nil
+}
+
+// Stale checks the provenance of the selected services
+func (p *StaleProvider) Stale(options StaleOptions) (*StaleReport, error) {
+	if p.loadErr != nil {
+		return nil, p.loadErr
+	}
+	if options.MaxAge == "" {
+		options.MaxAge = DefaultStaleAge
+	}
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+	cutoff, err := staleCutoff(options.Now, options.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceNames := options.ServiceNames
+	if len(serviceNames) == 0 {
+		serviceNames = p.configLoader.GetAllServiceNames()
+		sort.Strings(serviceNames)
+	}
+
+	report := &StaleReport{MaxAge: options.MaxAge, Cutoff: cutoff.Format(config.VerifiedOnLayout), Items: []StaleItem{}}
+	if len(options.ServiceNames) == 0 {
+		report.Errors = loadErrorMessages(p.configLoader)
+	}
+	for _, serviceName := range serviceNames {
+		serviceConfig, err := p.configLoader.GetServiceConfig(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		stackConstraint, hasStackRange := serviceConfig.ServiceInfo.Compatibility.StackConstraint()
+		oldestStack, hasOldestStack := lowestVersion(stackConstraint)
+
+		for _, item := range serviceConfig.ProvenanceItems() {
+			report.Checked++
+			provenance := item.Provenance
+			var reasons []string
+
+			if date, ok := provenance.VerifiedDate(); ok {
+				report.Verified++
+				if date.Before(cutoff) {
+					reasons = append(reasons, fmt.Sprintf("verified on %s, more than %s ago", provenance.VerifiedOn, options.MaxAge))
+				}
+			} else if options.Unverified {
+				reasons = append(reasons, "no verified_on date")
+			}
+
+			if newest, ok := provenance.NewestVerifiedVersion(); ok && hasStackRange && hasOldestStack && newest.Compare(oldestStack) < 0 {
+				reasons = append(reasons, fmt.Sprintf("verified against Elastic Stack %s, older than the supported range %s",
+					newest, stackConstraint))
+			}
+
+			if len(reasons) == 0 {
+				continue
+			}
+			report.Items = append(report.Items, StaleItem{
+				Service:          serviceConfig.ServiceName,
+				Section:          item.Section,
+				Item:             item.Item,
+				VerifiedOn:       provenance.VerifiedOn,
+				VerifiedVersions: provenance.VerifiedVersions,
+				Author:           provenance.Author,
+				SourceURL:        provenance.SourceURL,
+				Reasons:          reasons,
+			})
+		}
+	}
+	return report, nil
+}
+
+// staleCutoff returns the date before which verified content is stale
+func staleCutoff(now time.Time, maxAge string) (time.Time, error) {
+	match := staleAge.FindStringSubmatch(strings.TrimSpace(maxAge))
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid maximum age '%s': use a number of days, weeks, months or years such as 90d, 12w, 6m or 1y", maxAge)
+	}
+	n, _ := strconv.Atoi(match[1])
+	switch match[2] {
+	case "d":
+		return now.AddDate(0, 0, -n), nil
+	case "w":
+		return now.AddDate(0, 0, -7*n), nil
+	case "m":
+		return now.AddDate(0, -n, 0), nil
+	default:
+		return now.AddDate(-n, 0, 0), nil
+	}
+}
+
+// lowestVersion returns the lowest version written in a constraint, which is
+// the oldest version of the range
+func lowestVersion(constraint version.Constraint) (lowest version.Version, ok bool) {
+	for _, v := range constraint.Versions() {
+		if !ok || v.Compare(lowest) < 0 {
+			lowest, ok = v, true
+		}
+	}
+	return lowest, ok
+}
+
+// FormatStaleReport renders a stale report as Markdown, grouped by service
+func FormatStaleReport(report *StaleReport) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Stale Content\n\n**Maximum age**: %s (verified before %s is stale)\n", report.MaxAge, report.Cutoff))
+	result.WriteString(fmt.Sprintf("**Checked**: %d items, %d with a verified_on date\n", report.Checked, report.Verified))
+	result.WriteString(formatLoadErrors(report.Errors))
+	if len(report.Items) == 0 {
+		result.WriteString("\nNo stale content.\n")
+		return result.String()
+	}
+	result.WriteString(fmt.Sprintf("**Stale**: %d items\n", len(report.Items)))
+
+	service := ""
+	for _, item := range report.Items {
+		if item.Service != service {
+			service = item.Service
+			result.WriteString(fmt.Sprintf("\n## %s\n\n", service))
+		}
+		result.WriteString(fmt.Sprintf("- **%s** %s\n", item.Section, item.Item))
+		for _, reason := range item.Reasons {
+			result.WriteString(fmt.Sprintf("  - %s\n", reason))
+		}
+		if item.Author != "" || item.SourceURL != "" {
+			result.WriteString(fmt.Sprintf("  - %s\n", strings.Join(provenanceCredits(item.Author, item.SourceURL), "; ")))
+		}
+	}
+	return result.String()
+}
+
+// formatProvenance renders provenance as an italic line for tool output, or
+// "" when none is recorded
+func formatProvenance(provenance config.Provenance) string {
+	if provenance.IsZero() {
+		return ""
+	}
+	var parts []string
+	verified := ""
+	if provenance.VerifiedOn != "" {
+		verified = "verified on " + provenance.VerifiedOn
+	}
+	if len(provenance.VerifiedVersions) > 0 {
+		verified = strings.TrimSpace(verified + " against Elastic Stack " + strings.Join(provenance.VerifiedVersions, ", "))
+		if provenance.VerifiedOn == "" {
+			verified = "verified " + verified
+		}
+	}
+	if verified != "" {
+		parts = append(parts, verified)
+	}
+	parts = append(parts, provenanceCredits(provenance.Author, provenance.SourceURL)...)
+	return fmt.Sprintf("*Provenance: %s*\n\n", strings.Join(parts, "; "))
+}
+
+func provenanceCredits(author, sourceURL string) []string {
+	var credits []string
+	if author != "" {
+		credits = append(credits, "author: "+author)
+	}
+	if sourceURL != "" {
+		credits = append(credits, "source: "+sourceURL)
+	}
+	return credits
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaleCutoff(t *testing.T) {
+	now := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		maxAge string
+		want   string
+		err    string
+	}{
+		{maxAge: "90d", want: "2024-12-31"},
+		{maxAge: "2w", want: "2025-03-17"},
+		{maxAge: "1m", want: "2025-03-03"},
+		{maxAge: " 1y ", want: "2024-03-31"},
+		{maxAge: "1.5y", err: "invalid maximum age '1.5y': use a number of days, weeks, months or years such as 90d, 12w, 6m or 1y"},
+		{maxAge: "90", err: "invalid maximum age '90'"},
+		{maxAge: "d", err: "invalid maximum age 'd'"},
+	}
+	for _, test := range tests {
+		cutoff, err := staleCutoff(now, test.maxAge)
+		if test.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+				t.Errorf("staleCutoff(%q) error = %v, want %q", test.maxAge, err, test.err)
+			}
+			continue
+		}
+		if err != nil || cutoff.Format("2006-01-02") != test.want {
+			t.Errorf("staleCutoff(%q) = %v, %v, want %s", test.maxAge, cutoff, err, test.want)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	configDir := t.TempDir()
+	writeTestFiles(t, configDir, map[string]string{
+		"services/nginx.yaml": `service_name: nginx
+service_info:
+  compatibility:
+    elastic_stack_versions: ["^8.13.0"]
+setup_instructions:
+  installation_steps:
+    - step: 1
+      title: Install
+      verified_on: "2024-01-10"
+      author: jane
+    - step: 2
+      title: Configure
+      verified_on: "2025-03-01"
+      verified_versions: ["8.12.0"]
+    - step: 3
+      title: Start
+troubleshooting:
+  common_issues:
+    - issue: No data
+      verified_on: "2025-03-01"
+      verified_versions: ["8.11.0", "8.14.0"]
+`,
+		"services/apache.yaml": `service_name: apache
+setup_instructions:
+  installation_steps:
+    - step: 1
+      title: Install
+      verified_on: "2020-01-01"
+      source_url: https://example.com/apache
+`,
+	})
+	provider := NewStaleProvider(configDir)
+	now := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		options  StaleOptions
+		checked  int
+		verified int
+		want     []string
+		err      string
+	}{
+		{
+			name:     "all services",
+			options:  StaleOptions{},
+			checked:  5,
+			verified: 4,
+			want: []string{
+				"apache | Step 1: Install | verified on 2020-01-01, more than 1y ago",
+				"nginx | Step 1: Install | verified on 2024-01-10, more than 1y ago",
+				"nginx | Step 2: Configure | verified against Elastic Stack 8.12.0, older than the supported range ^8.13.0",
+			},
+		},
+		{
+			name:     "one service with unverified content",
+			options:  StaleOptions{ServiceNames: []string{"nginx"}, MaxAge: "6m", Unverified: true},
+			checked:  4,
+			verified: 3,
+			want: []string{
+				"nginx | Step 1: Install | verified on 2024-01-10, more than 6m ago",
+				"nginx | Step 2: Configure | verified against Elastic Stack 8.12.0, older than the supported range ^8.13.0",
+				"nginx | Step 3: Start | no verified_on date",
+			},
+		},
+		{
+			name:    "invalid maximum age",
+			options: StaleOptions{MaxAge: "soon"},
+			err:     "invalid maximum age 'soon'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Now = now
+			report, err := provider.Stale(test.options)
+			if test.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+					t.Errorf("Stale error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stale: %v", err)
+			}
+			if report.Checked != test.checked || report.Verified != test.verified {
+				t.Errorf("checked %d items, %d verified, want %d and %d", report.Checked, report.Verified, test.checked, test.verified)
+			}
+			var got []string
+			for _, item := range report.Items {
+				got = append(got, item.Service+" | "+item.Item+" | "+strings.Join(item.Reasons, "; "))
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("stale items =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+
+	if _, err := NewStaleProvider(t.TempDir()).Stale(StaleOptions{}); err == nil {
+		t.Errorf("Stale without a services directory succeeded, want the load error")
+	}
+
+	result, err := provider.FindStaleContent("apache", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].Text
+	for _, want := range []string{"## apache", "- **Installation steps** Step 1: Install", "  - source: https://example.com/apache"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("FindStaleContent output does not contain %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// rsyslogLegacyForward matches selector lines forwarding with @ (UDP) or @@ (TCP)
+var rsyslogLegacyForward = regexp.MustCompile(`^\S+\s+(@@?)(\(o\))?\[?([^\]:;\s]+)\]?(?::(\d+))?(?:;(\S+))?`)
+
+// rsyslogParameter matches name="value" parameters in RainerScript
+var rsyslogParameter = regexp.MustCompile(`([A-Za-z.]+)\s*=\s*"([^"]*)"`)
+
+type rsyslogValidator struct{}
+
+func (rsyslogValidator) Name() string {
+	return "rsyslog"
+}
+
+func (rsyslogValidator) Description() string {
+	return "rsyslog.conf and /etc/rsyslog.d/*.conf, legacy or RainerScript syntax"
+}
+
+func (rsyslogValidator) Validate(content string) shared.ValidationResult {
+	f := newFindings()
+	forwards := 0
+
+	statements := splitRsyslogStatements(content, f)
+	for _, statement := range statements {
+		text := statement.text
+		if match := rsyslogLegacyForward.FindStringSubmatch(text); match != nil {
+			forwards++
+			checkRsyslogForward(f, statement.line, match[1] == "@", match[4], match[5])
+			continue
+		}
+
+		if strings.HasPrefix(text, "action(") || strings.Contains(text, " action(") {
+			params := make(map[string]string)
+			for _, match := range rsyslogParameter.FindAllStringSubmatch(text, -1) {
+				params[strings.ToLower(match[1])] = match[2]
+			}
+			if params["type"] != "omfwd" {
+				continue
+			}
+			forwards++
+			if params["target"] == "" {
+				f.addError(statement.line, "forwarding", "omfwd action has no target")
+			}
+			checkRsyslogForward(f, statement.line, !strings.EqualFold(params["protocol"], "tcp"), params["port"], params["template"])
+			if params["queue.type"] == "" {
+				f.addSuggestion(statement.line, "reliability", "The forwarding action has no queue",
+					`Add queue.type="LinkedList" queue.filename="fwd" action.resumeRetryCount="-1"`,
+					"Messages are dropped while Elastic Agent is unreachable")
+			}
+		}
+	}
+
+	if forwards == 0 {
+		f.addWarning(0, "forwarding", "No forwarding rule found; rsyslog is not sending messages to Elastic Agent",
+			`Add 'action(type="omfwd" target="<agent host>" port="9514" protocol="tcp")'`)
+	}
+	return f.result
+}
+
+func checkRsyslogForward(f *findings, line int, udp bool, port, template string) {
+	if udp {
+		f.addSuggestion(line, "transport", "Messages are forwarded over UDP",
+			"Forward over TCP (@@ or protocol=\"tcp\") and use the tcp input",
+			"UDP drops messages under load and truncates long messages")
+	}
+	if port == "" {
+		f.addWarning(line, "forwarding", "No port is set; rsyslog forwards to port 514",
+			"Set the port the integration's syslog input listens on")
+	}
+	if template == "" || template == "RSYSLOG_TraditionalForwardFormat" {
+		f.addWarning(line, "format", "Messages are forwarded in the traditional RFC 3164 format, without year or time zone",
+			"Use template=\"RSYSLOG_SyslogProtocol23Format\" to send RFC 5424 timestamps")
+	}
+}
+
+// rsyslogStatement is a statement from rsyslog.conf starting on line
+type rsyslogStatement struct {
+	text string
+	line int
+}
+
+// splitRsyslogStatements joins multi-line RainerScript statements and drops comments
+func splitRsyslogStatements(content string, f *findings) []rsyslogStatement {
+	var statements []rsyslogStatement
+	var current strings.Builder
+	depth, start := 0, 0
+
+	for index, text := range strings.Split(content, "\n") {
+		if comment := strings.Index(text, "#"); comment >= 0 && !strings.Contains(text[:comment], `"`) {
+			text = text[:comment]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if depth == 0 {
+			start = index + 1
+		}
+
+		current.WriteString(text + " ")
+		depth += strings.Count(text, "(") - strings.Count(text, ")")
+		if depth < 0 {
+			f.addError(index+1, "syntax", "Unexpected ')'")
+			depth = 0
+		}
+		if depth == 0 {
+			statements = append(statements, rsyslogStatement{text: strings.TrimSpace(current.String()), line: start})
+			current.Reset()
+		}
+	}
+
+	if depth > 0 {
+		f.addError(start, "syntax", "Statement is missing a closing ')'")
+	}
+	return statements
+}
+
+// syslogNGBlock is a top-level object from syslog-ng.conf, such as a
+// source, destination or log path
+type syslogNGBlock struct {
+	kind string
+	name string
+	body string
+	line int
+}
+
+// syslogNGReference matches source(name) and destination(name) references in
+// log paths; inline driver calls such as source(system()) do not match
+var syslogNGReference = regexp.MustCompile(`\b(source|destination)\(\s*([A-Za-z0-9_]+)\s*\)`)
+
+// syslogNGNetworkDriver matches destination drivers that send over the network
+var syslogNGNetworkDriver = regexp.MustCompile(`\b(network|syslog|tcp|udp|tcp6|udp6)\s*\(`)
+
+// syslogNGUDP matches UDP transports in destination drivers
+var syslogNGUDP = regexp.MustCompile(`transport\(\s*"?udp"?\s*\)|\budp6?\s*\(`)
+
+// syslogNGPort matches the port() option, but not transport()
+var syslogNGPort = regexp.MustCompile(`\bport\(`)
+
+type syslogNGValidator struct{}
+
+func (syslogNGValidator) Name() string {
+	return "syslog-ng"
+}
+
+func (syslogNGValidator) Description() string {
+	return "syslog-ng.conf and /etc/syslog-ng/conf.d/*.conf"
+}
+
+func (syslogNGValidator) Validate(content string) shared.ValidationResult {
+	f := newFindings()
+	blocks := parseSyslogNGConfig(content, f)
+
+	defined := map[string]map[string]int{"source": {}, "destination": {}}
+	used := make(map[string]bool)
+	networkDestinations := 0
+	for _, block := range blocks {
+		switch block.kind {
+		case "source", "destination":
+			defined[block.kind][block.name] = block.line
+		}
+		if block.kind != "destination" || !syslogNGNetworkDriver.MatchString(block.body) {
+			continue
+		}
+
+		networkDestinations++
+		if syslogNGUDP.MatchString(block.body) {
+			f.addSuggestion(block.line, "transport", fmt.Sprintf("Destination %s forwards over UDP", block.name),
+				"Use transport(\"tcp\") and the integration's tcp input", "UDP drops messages under load and truncates long messages")
+		}
+		if !syslogNGPort.MatchString(block.body) {
+			f.addWarning(block.line, "forwarding", fmt.Sprintf("Destination %s sets no port; syslog-ng uses the driver's default", block.name),
+				"Set port() to the port the integration's syslog input listens on")
+		}
+	}
+
+	for _, block := range blocks {
+		if block.kind != "log" {
+			continue
+		}
+		for _, match := range syslogNGReference.FindAllStringSubmatch(block.body, -1) {
+			kind, name := match[1], match[2]
+			if _, exists := defined[kind][name]; !exists {
+				f.addError(block.line, "reference", fmt.Sprintf("Log path references undefined %s %s", kind, name))
+			}
+			used[kind+":"+name] = true
+		}
+	}
+
+	for _, name := range sortedKeys(defined["destination"]) {
+		if !used["destination:"+name] {
+			f.addWarning(defined["destination"][name], "forwarding", fmt.Sprintf("Destination %s is not used by any log path", name),
+				fmt.Sprintf("Add 'log { source(<source>); destination(%s); };'", name))
+		}
+	}
+
+	if networkDestinations == 0 {
+		f.addWarning(0, "forwarding", "No network destination found; syslog-ng is not sending messages to Elastic Agent",
+			`Add 'destination d_elastic { syslog("<agent host>" transport("tcp") port(9514)); };'`)
+	}
+	return f.result
+}
+
+// parseSyslogNGConfig splits syslog-ng configuration into top-level blocks,
+// recording unbalanced braces as errors
+func parseSyslogNGConfig(content string, f *findings) []syslogNGBlock {
+	var blocks []syslogNGBlock
+	var header, body strings.Builder
+	depth, line, blockLine := 0, 1, 0
+	inQuotes := false
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c == '\n' {
+			line++
+		}
+		if !inQuotes && c == '#' {
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+			continue
+		}
+		if c == '"' && (i == 0 || content[i-1] != '\\') {
+			inQuotes = !inQuotes
+		}
+		if inQuotes {
+			if depth > 0 {
+				body.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '{':
+			if depth == 0 {
+				blockLine = line
+			} else {
+				body.WriteByte(c)
+			}
+			depth++
+		case '}':
+			depth--
+			switch {
+			case depth < 0:
+				f.addError(line, "syntax", "Unexpected '}'")
+				depth = 0
+			case depth == 0:
+				fields := strings.Fields(header.String())
+				block := syslogNGBlock{body: body.String(), line: blockLine}
+				if len(fields) > 0 {
+					block.kind = fields[0]
+				}
+				if len(fields) > 1 {
+					block.name = fields[1]
+				}
+				blocks = append(blocks, block)
+				header.Reset()
+				body.Reset()
+			default:
+				body.WriteByte(c)
+			}
+		case ';':
+			if depth == 0 {
+				header.Reset()
+			} else {
+				body.WriteByte(c)
+			}
+		default:
+			switch {
+			case depth > 0:
+				body.WriteByte(c)
+			case c == '\n' && strings.HasPrefix(strings.TrimSpace(header.String()), "@"):
+				// Pragmas such as @version: 4.2 end at the line break
+				header.Reset()
+			default:
+				header.WriteByte(c)
+			}
+		}
+	}
+
+	if depth > 0 {
+		f.addError(blockLine, "syntax", "Block is never closed")
+	}
+	return blocks
+}
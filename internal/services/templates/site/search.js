@@ -0,0 +1,38 @@
+// Client-side search over search_index.json. Browsers block fetch() for
+// file:// URLs, so serve the site over HTTP, e.g. python3 -m http.server.
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var root = document.querySelector("script[src$='search.js']").getAttribute("src").replace(/search\.js$/, "");
+  var entries = [];
+
+  fetch(root + "search_index.json")
+    .then(function (response) { return response.json(); })
+    .then(function (index) { entries = index; });
+
+  function text(entry) {
+    return [entry.title, entry.description].concat(entry.categories || [], entry.headings || []).join(" ").toLowerCase();
+  }
+
+  input.addEventListener("input", function () {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    if (words.length === 0) {
+      return;
+    }
+    entries.filter(function (entry) {
+      var haystack = text(entry);
+      return words.every(function (word) { return haystack.indexOf(word) >= 0; });
+    }).slice(0, 20).forEach(function (entry) {
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = root + entry.url;
+      link.textContent = entry.title;
+      item.appendChild(link);
+      if (entry.description) {
+        item.appendChild(document.createTextNode(": " + entry.description));
+      }
+      results.appendChild(item);
+    });
+  });
+})();
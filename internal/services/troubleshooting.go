@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// Scores awarded for each kind of match between an error message and an issue
+const (
+	errorPatternScore   = 10
+	symptomScore        = 5
+	partialSymptomScore = 2
+	keywordScore        = 1
+)
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "not": true, "are": true, "was": true,
+	"with": true, "from": true, "this": true, "that": true, "has": true, "have": true,
+	"been": true, "being": true, "into": true, "when": true, "failed": true, "error": true,
+}
+
+// matchTroubleshootingIssues ranks issues by how well they match an error message,
+// dropping issues that do not match at all. Best matches come first.
+func matchTroubleshootingIssues(issues []config.TroubleshootingIssue, errorMessage string) []shared.TroubleshootingMatch {
+	message := strings.ToLower(strings.TrimSpace(errorMessage))
+	messageTokens := tokenize(message)
+
+	var matches []shared.TroubleshootingMatch
+	for _, issue := range issues {
+		score := 0
+		var reasons []string
+
+		for _, pattern := range issue.MatchingErrorPatterns(errorMessage) {
+			score += errorPatternScore
+			reasons = append(reasons, fmt.Sprintf("error message matches pattern `%s`", pattern))
+		}
+
+		for _, symptom := range issue.Symptoms {
+			lowered := strings.ToLower(symptom)
+			if strings.Contains(message, lowered) || (len(messageTokens) > 0 && strings.Contains(lowered, message)) {
+				score += symptomScore
+				reasons = append(reasons, fmt.Sprintf("error message matches symptom %q", symptom))
+				continue
+			}
+			symptomTokens := tokenize(lowered)
+			common := sharedTokens(symptomTokens, messageTokens)
+			if len(symptomTokens) > 0 && len(common)*2 >= len(symptomTokens) {
+				score += partialSymptomScore
+				reasons = append(reasons, fmt.Sprintf("error message partially matches symptom %q", symptom))
+			}
+		}
+
+		if keywords := sharedTokens(tokenize(strings.ToLower(issue.Issue)), messageTokens); len(keywords) > 0 {
+			score += keywordScore * len(keywords)
+			reasons = append(reasons, fmt.Sprintf("shares keywords with issue: %s", strings.Join(keywords, ", ")))
+		}
+
+		if score == 0 {
+			continue
+		}
+
+		matches = append(matches, shared.TroubleshootingMatch{
+			TroubleshootingIssue: toSharedIssue(issue),
+			Score:                score,
+			Explanation:          reasons,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
+
+// toSharedIssue converts a configured issue into the API representation
+func toSharedIssue(issue config.TroubleshootingIssue) shared.TroubleshootingIssue {
+	sharedIssue := shared.TroubleshootingIssue{
+		Issue:      issue.Issue,
+		SharedID:   issue.Ref,
+		Symptoms:   issue.Symptoms,
+		Causes:     issue.Causes,
+		Solutions:  issue.AllSolutions(),
+		Prevention: issue.Prevention,
+	}
+	if !issue.Provenance.IsZero() {
+		sharedIssue.Provenance = &shared.Provenance{
+			SourceURL:        issue.SourceURL,
+			VerifiedOn:       issue.VerifiedOn,
+			VerifiedVersions: issue.VerifiedVersions,
+			Author:           issue.Author,
+		}
+	}
+	return sharedIssue
+}
+
+// toTroubleshootingGuide converts a service's troubleshooting section into the API representation
+func toTroubleshootingGuide(serviceName string, troubleshooting config.Troubleshooting) shared.TroubleshootingGuide {
+	issues := make([]shared.TroubleshootingIssue, 0, len(troubleshooting.CommonIssues))
+	for _, issue := range troubleshooting.CommonIssues {
+		issues = append(issues, toSharedIssue(issue))
+	}
+
+	return shared.TroubleshootingGuide{
+		ServiceName:        serviceName,
+		CommonIssues:       issues,
+		DiagnosticCommands: troubleshooting.DiagnosticCommands,
+		LogLocations:       troubleshooting.LogLocations,
+		SupportResources:   troubleshooting.SupportResources,
+	}
+}
+
+// tokenize splits text into lowercase keywords, ignoring short and common words
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if len(field) < 3 || stopWords[field] || seen[field] {
+			continue
+		}
+		seen[field] = true
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// sharedTokens returns the tokens of a that also appear in b
+func sharedTokens(a, b []string) []string {
+	lookup := make(map[string]bool, len(b))
+	for _, token := range b {
+		lookup[token] = true
+	}
+
+	var result []string
+	for _, token := range a {
+		if lookup[token] {
+			result = append(result, token)
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"elastic-integration-docs-mcp/internal/config"
+)
+
+func TestMatchTroubleshootingIssuesWithLibrary(t *testing.T) {
+	configDir := t.TempDir()
+	writeTestFiles(t, configDir, map[string]string{
+		"common/troubleshooting/agent.yaml": `issues:
+  - id: agent-not-enrolled
+    issue: Elastic Agent is not enrolled
+    symptoms: [agent is not enrolled]
+    solutions: [Enroll the agent in Fleet]
+  - id: tls-unknown-authority
+    issue: Certificate signed by unknown authority
+    error_patterns: ['x509: certificate signed by unknown authority']
+`,
+		"services/nginx.yaml": `service_name: nginx
+troubleshooting:
+  common_issues:
+    - issue: Access log is empty
+      symptoms: [no access log events]
+    - ref: agent-not-enrolled
+    - ref: tls-unknown-authority
+`,
+	})
+	configLoader := config.NewConfigLoader(configDir)
+	if err := configLoader.LoadAllServices(); err != nil {
+		t.Fatal(err)
+	}
+	serviceConfig, err := configLoader.GetServiceConfig("nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{
+			message: "Post https://es:9200: x509: certificate signed by unknown authority",
+			want:    []string{"tls-unknown-authority|Certificate signed by unknown authority"},
+		},
+		{
+			message: "agent is not enrolled",
+			want:    []string{"agent-not-enrolled|Elastic Agent is not enrolled"},
+		},
+		{
+			message: "No access log events in Kibana",
+			want:    []string{"|Access log is empty"},
+		},
+		{
+			message: "disk quota exceeded",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			var got []string
+			for _, match := range matchTroubleshootingIssues(serviceConfig.Troubleshooting.CommonIssues, test.message) {
+				got = append(got, fmt.Sprintf("%s|%s", match.SharedID, match.Issue))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("matchTroubleshootingIssues = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 package services
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
 )
 
 func formatList(items []string) string {
@@ -13,3 +20,88 @@ func formatList(items []string) string {
 	return result.String()
 }
 
+// formatJSONResult renders a value as indented JSON tool output
+func formatJSONResult(value interface{}, what string) (shared.CallToolResult, error) {
+	text, err := formatJSON(value)
+	if err != nil {
+		return shared.CallToolResult{}, fmt.Errorf("failed to format %s: %v", what, err)
+	}
+
+	return shared.CallToolResult{
+		Content: []shared.ToolContent{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}, nil
+}
+
+// formatJSON encodes a value as indented JSON without HTML escaping
+func formatJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// formatYAML encodes a value as YAML with two-space indentation
+func formatYAML(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// errorResult wraps a message in an error tool result
+func errorResult(message string) shared.CallToolResult {
+	return shared.CallToolResult{
+		Content: []shared.ToolContent{
+			{
+				Type: "text",
+				Text: message,
+			},
+		},
+		IsError: true,
+	}
+}
+
+// textResult wraps text in a successful tool result
+func textResult(text string) shared.CallToolResult {
+	return shared.CallToolResult{
+		Content: []shared.ToolContent{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// loadErrorMessages returns the errors of the service files a loader skipped
+func loadErrorMessages(loader *config.ConfigLoader) []string {
+	var messages []string
+	for _, err := range loader.LoadErrors() {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// formatLoadErrors lists service files that failed to load, or returns ""
+// when there are none
+func formatLoadErrors(messages []string) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("**Not loaded**: %d service files\n%s", len(messages), formatList(messages))
+}
@@ -10,10 +10,11 @@ import (
 
 type ValidationProvider struct {
 	configLoader *config.ConfigLoader
+	integrations *IntegrationProvider
 }
 
 func NewValidationProvider(configDir string) *ValidationProvider {
-	configLoader := config.NewConfigLoader(configDir)
+	configLoader := config.NewConfigLoaderFromEnv(configDir)
 	if err := configLoader.LoadAllServices(); err != nil {
 		// In a real implementation, you might want to handle this error differently
 		// For now, we'll create an empty loader
@@ -22,6 +23,7 @@ func NewValidationProvider(configDir string) *ValidationProvider {
 
 	return &ValidationProvider{
 		configLoader: configLoader,
+		integrations: NewIntegrationProvider(),
 	}
 }
 
@@ -39,13 +41,19 @@ func (v *ValidationProvider) GetValidationSteps(serviceName string) (shared.Call
 		}, nil
 	}
 
+	notice := ""
+	if text := statusNotice(v.configLoader, serviceConfig.SectionStatus(serviceConfig.ValidationSteps.Status)); text != "" {
+		notice = "> " + text + "\n\n"
+	}
+
 	validationSteps := fmt.Sprintf(`# %s Integration Validation Steps
 
-%s
+%s%s
 
 ## Summary
 These validation steps will help you verify that the %s integration is running properly and collecting data as expected.`,
 		strings.ToUpper(serviceConfig.ServiceName),
+		notice,
 		formatValidationSteps(serviceConfig.ValidationSteps.Steps),
 		serviceConfig.ServiceName)
 
@@ -64,6 +72,7 @@ func formatValidationSteps(steps []config.ValidationStep) string {
 	for _, step := range steps {
 		result.WriteString(fmt.Sprintf("\n## Step %d: %s\n%s\n\n",
 			step.Step, step.Title, step.Description))
+		result.WriteString(formatProvenance(step.Provenance))
 
 		if len(step.Commands) > 0 {
 			result.WriteString("**Commands:**\n```bash\n")
@@ -76,6 +85,27 @@ func formatValidationSteps(steps []config.ValidationStep) string {
 		if step.ExpectedOutput != "" {
 			result.WriteString(fmt.Sprintf("**Expected Output:**\n%s\n\n", step.ExpectedOutput))
 		}
+
+		if step.Expect != nil {
+			result.WriteString(fmt.Sprintf("**Checked by `validate run`:**\n%s\n", formatList(formatExpectation(step.Expect))))
+		}
 	}
 	return result.String()
 }
+
+// formatExpectation describes the assertions of an expect block
+func formatExpectation(expect *config.StepExpectation) []string {
+	assertions := []string{fmt.Sprintf("Exit code is %d", expect.WantExitCode())}
+	if expect.Stdout != "" {
+		assertions = append(assertions, fmt.Sprintf("Output matches `%s`", expect.Stdout))
+	}
+	if expect.JSONPath != "" && expect.JSONValue != "" {
+		assertions = append(assertions, fmt.Sprintf("JSON path `%s` is `%s`", expect.JSONPath, expect.JSONValue))
+	} else if expect.JSONPath != "" {
+		assertions = append(assertions, fmt.Sprintf("JSON path `%s` exists", expect.JSONPath))
+	}
+	if expect.Timeout != "" {
+		assertions = append(assertions, fmt.Sprintf("Completes within %s", expect.Timeout))
+	}
+	return assertions
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"elastic-integration-docs-mcp/internal/config"
+	"elastic-integration-docs-mcp/internal/shared"
+)
+
+// defaultStepTimeout bounds a validation step unless its expect block sets a timeout
+const defaultStepTimeout = 30 * time.Second
+
+// maxStepOutput bounds the stdout and stderr captured from a validation step
+const maxStepOutput = 1 << 20
+
+// sandboxEnv names the step directory in the environment of a sandboxed step
+const sandboxEnv = "VALIDATION_STEP_SANDBOX"
+
+// sandboxSetupFailed is the exit code of a sandbox that could not be set up,
+// which reports the reason on stderr after sandboxErrorPrefix
+const (
+	sandboxSetupFailed = 125
+	sandboxErrorPrefix = "validation step sandbox: "
+)
+
+// jsonPathSegment matches one segment of a JSON path, such as "hits" or "items[0]"
+var jsonPathSegment = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// jsonPathIndex matches the array indexes of a JSON path segment
+var jsonPathIndex = regexp.MustCompile(`\d+`)
+
+// ValidationRunOptions controls how validation steps are executed
+type ValidationRunOptions struct {
+	// Steps limits the run to these step numbers; all steps run when empty
+	Steps []int
+	// Timeout applies to steps whose expect block sets no timeout
+	Timeout time.Duration
+	// PassEnv names environment variables, such as credentials, passed to the steps
+	PassEnv []string
+	// NoSandbox runs the steps without isolation, for hosts that are
+	// disposable already, such as integration test containers
+	NoSandbox bool
+}
+
+// RunValidationSteps executes the commands of a service's validation steps
+// that have an expect block, and checks the block. Steps without one are
+// often prose for a person to follow, so they are skipped. Each step runs
+// as one "sh -e" script in a fresh temporary directory with a minimal
+// environment, and is killed with its child processes when it exceeds its
+// timeout. Unless options.NoSandbox is set, the script runs in a sandbox that
+// can only write to that directory and cannot see other processes; where no
+// sandbox is available, an error is returned.
+func (v *ValidationProvider) RunValidationSteps(serviceName string, options ValidationRunOptions) ([]shared.ValidationStepResult, error) {
+	serviceConfig, err := v.configLoader.GetServiceConfig(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if !options.NoSandbox && sandboxUnavailable != nil {
+		return nil, sandboxUnavailable
+	}
+
+	if options.Timeout <= 0 {
+		options.Timeout = defaultStepTimeout
+	}
+
+	var results []shared.ValidationStepResult
+	for _, step := range serviceConfig.ValidationSteps.Steps {
+		if len(options.Steps) > 0 && !containsInt(options.Steps, step.Step) {
+			continue
+		}
+		results = append(results, runValidationStep(step, options))
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no validation steps matched for %s", serviceName)
+	}
+	return results, nil
+}
+
+func runValidationStep(step config.ValidationStep, options ValidationRunOptions) shared.ValidationStepResult {
+	result := shared.ValidationStepResult{Step: step.Step, Title: step.Title}
+
+	if step.Expect == nil {
+		result.Status = "skip"
+		result.Message = "no expect block"
+		return result
+	}
+	script := stepScript(step.Commands)
+	if script == "" {
+		result.Status = "skip"
+		result.Message = "no commands to run"
+		return result
+	}
+
+	start := time.Now()
+	stdout, stderr, exitCode, err := runStepScript(script, step.Expect.TimeoutDuration(options.Timeout), options.PassEnv, !options.NoSandbox)
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	result.ExitCode = exitCode
+
+	if err != nil {
+		result.Failures = append(result.Failures, err.Error())
+	} else {
+		result.Failures = checkExpectation(step.Expect, stdout, exitCode)
+	}
+
+	result.Status = "pass"
+	if len(result.Failures) > 0 {
+		result.Status = "fail"
+		result.Stdout = stdout
+		result.Stderr = stderr
+	}
+	return result
+}
+
+// stepScript joins a step's commands into a shell script, or returns "" when
+// the step only contains comments
+func stepScript(commands []string) string {
+	runnable := false
+	for _, command := range commands {
+		trimmed := strings.TrimSpace(command)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			runnable = true
+		}
+	}
+	if !runnable {
+		return ""
+	}
+	return strings.Join(commands, "\n")
+}
+
+// runStepScript runs a script with sh in a temporary directory, sandboxed or
+// not, passing only PATH and the named environment variables through. An
+// error is returned when the script could not be run or timed out; a non-zero
+// exit code is not an error.
+func runStepScript(script string, timeout time.Duration, passEnv []string, sandboxed bool) (string, string, int, error) {
+	dir, err := os.MkdirTemp("", "validation-step-")
+	if err != nil {
+		return "", "", -1, fmt.Errorf("failed to create step directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"TMPDIR=" + dir,
+		"LANG=C.UTF-8",
+	}
+	for _, name := range passEnv {
+		if value, exists := os.LookupEnv(name); exists {
+			env = append(env, name+"="+value)
+		}
+	}
+
+	var cmd *exec.Cmd
+	if sandboxed {
+		if cmd, err = sandboxCommand(ctx, script, dir, env); err != nil {
+			return "", "", -1, err
+		}
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-e", "-c", script)
+		cmd.Dir = dir
+		cmd.Env = env
+	}
+	stdout := &limitedBuffer{limit: maxStepOutput}
+	stderr := &limitedBuffer{limit: maxStepOutput}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return stdout.String(), stderr.String(), -1, fmt.Errorf("timed out after %s", timeout)
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return stdout.String(), stderr.String(), 0, nil
+	case errors.As(err, &exitErr):
+		if sandboxed && exitErr.ExitCode() == sandboxSetupFailed && strings.HasPrefix(stderr.String(), sandboxErrorPrefix) {
+			return stdout.String(), stderr.String(), -1, fmt.Errorf("failed to set up the sandbox: %s", strings.TrimSpace(strings.TrimPrefix(stderr.String(), sandboxErrorPrefix)))
+		}
+		return stdout.String(), stderr.String(), exitErr.ExitCode(), nil
+	case sandboxed:
+		return stdout.String(), stderr.String(), -1, fmt.Errorf("failed to start the sandbox: %v; user namespaces may be disabled on this host, so run the step without the sandbox inside a disposable container", err)
+	default:
+		return stdout.String(), stderr.String(), -1, fmt.Errorf("failed to run commands: %v", err)
+	}
+}
+
+// checkExpectation returns the assertions of an expect block that the output
+// does not satisfy. The exit code must be 0 unless the block sets another.
+func checkExpectation(expect *config.StepExpectation, stdout string, exitCode int) []string {
+	var failures []string
+	if want := expect.WantExitCode(); exitCode != want {
+		failures = append(failures, fmt.Sprintf("exit code %d, expected %d", exitCode, want))
+	}
+	if expect == nil {
+		return failures
+	}
+
+	if expect.Stdout != "" {
+		pattern := regexp.MustCompile(expect.Stdout)
+		if !pattern.MatchString(stdout) {
+			failures = append(failures, fmt.Sprintf("stdout does not match /%s/", expect.Stdout))
+		}
+	}
+
+	if expect.JSONPath != "" {
+		var document interface{}
+		if err := json.Unmarshal([]byte(stdout), &document); err != nil {
+			return append(failures, fmt.Sprintf("stdout is not JSON: %v", err))
+		}
+		value, found := lookupJSONPath(document, expect.JSONPath)
+		switch {
+		case !found:
+			failures = append(failures, fmt.Sprintf("JSON path %s not found", expect.JSONPath))
+		case expect.JSONValue != "" && jsonValueString(value) != expect.JSONValue:
+			failures = append(failures, fmt.Sprintf("JSON path %s is %s, expected %s", expect.JSONPath, jsonValueString(value), expect.JSONValue))
+		}
+	}
+	return failures
+}
+
+// lookupJSONPath resolves a dotted path with array indexes, such as
+// "$.hits.hits[0]._source", in a decoded JSON document
+func lookupJSONPath(document interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := document
+	if path == "" {
+		return current, true
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		match := jsonPathSegment.FindStringSubmatch(segment)
+		if match == nil {
+			return nil, false
+		}
+		if match[1] != "" {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[match[1]]; !ok {
+				return nil, false
+			}
+		}
+		for _, index := range jsonPathIndex.FindAllString(match[2], -1) {
+			items, ok := current.([]interface{})
+			position, _ := strconv.Atoi(index)
+			if !ok || position >= len(items) {
+				return nil, false
+			}
+			current = items[position]
+		}
+	}
+	return current, true
+}
+
+// jsonValueString formats a JSON value for comparison: strings as-is, other values as JSON
+func jsonValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+func containsInt(items []int, value int) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBuffer keeps at most limit bytes and discards the rest
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (l *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := l.limit - l.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			l.buf.Write(p[:remaining])
+		} else {
+			l.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (l *limitedBuffer) String() string {
+	return l.buf.String()
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"elastic-integration-docs-mcp/internal/config"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestCheckExpectation(t *testing.T) {
+	tests := []struct {
+		name     string
+		expect   *config.StepExpectation
+		stdout   string
+		exitCode int
+		failures []string
+	}{
+		{
+			name:   "exit code 0 by default",
+			expect: &config.StepExpectation{},
+		},
+		{
+			name:     "unexpected exit code",
+			expect:   &config.StepExpectation{},
+			exitCode: 1,
+			failures: []string{"exit code 1, expected 0"},
+		},
+		{
+			name:     "expected non-zero exit code",
+			expect:   &config.StepExpectation{ExitCode: intPtr(2)},
+			exitCode: 2,
+		},
+		{
+			name:   "stdout matches",
+			expect: &config.StepExpectation{Stdout: `Active connections: \d+`},
+			stdout: "Active connections: 3\n",
+		},
+		{
+			name:     "stdout does not match",
+			expect:   &config.StepExpectation{Stdout: `(?i)healthy`},
+			stdout:   "degraded",
+			failures: []string{"stdout does not match /(?i)healthy/"},
+		},
+		{
+			name:   "JSON value matches",
+			expect: &config.StepExpectation{JSONPath: "_shards.failed", JSONValue: "0"},
+			stdout: `{"count":12,"_shards":{"failed":0}}`,
+		},
+		{
+			name:   "JSON path with index",
+			expect: &config.StepExpectation{JSONPath: "$.hits.hits[1]._source.host", JSONValue: "web-2"},
+			stdout: `{"hits":{"hits":[{"_source":{"host":"web-1"}},{"_source":{"host":"web-2"}}]}}`,
+		},
+		{
+			name:   "JSON value compared as JSON",
+			expect: &config.StepExpectation{JSONPath: "status", JSONValue: `{"ok":true}`},
+			stdout: `{"status":{"ok":true}}`,
+		},
+		{
+			name:     "JSON value differs",
+			expect:   &config.StepExpectation{JSONPath: "_shards.failed", JSONValue: "0"},
+			stdout:   `{"_shards":{"failed":2}}`,
+			failures: []string{"JSON path _shards.failed is 2, expected 0"},
+		},
+		{
+			name:     "JSON path missing",
+			expect:   &config.StepExpectation{JSONPath: "hits.hits[3]"},
+			stdout:   `{"hits":{"hits":[]}}`,
+			failures: []string{"JSON path hits.hits[3] not found"},
+		},
+		{
+			name:     "stdout is not JSON",
+			expect:   &config.StepExpectation{JSONPath: "count"},
+			stdout:   "curl: (7) Failed to connect",
+			exitCode: 7,
+			failures: []string{"exit code 7, expected 0", "stdout is not JSON: invalid character 'c' looking for beginning of value"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			failures := checkExpectation(test.expect, test.stdout, test.exitCode)
+			if strings.Join(failures, "\n") != strings.Join(test.failures, "\n") {
+				t.Errorf("failures = %q, want %q", failures, test.failures)
+			}
+		})
+	}
+}
+
+func TestStepScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		script   string
+	}{
+		{"commands", []string{"nginx -t", "curl -sf http://127.0.0.1/"}, "nginx -t\ncurl -sf http://127.0.0.1/"},
+		{"only comments", []string{"# open Kibana", "  "}, ""},
+		{"empty", nil, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if script := stepScript(test.commands); script != test.script {
+				t.Errorf("stepScript() = %q, want %q", script, test.script)
+			}
+		})
+	}
+}
+
+func TestRunStepScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("validation steps run with sh")
+	}
+	t.Setenv("STEP_PASSED", "passed")
+	t.Setenv("STEP_HIDDEN", "hidden")
+
+	tests := []struct {
+		name     string
+		script   string
+		timeout  time.Duration
+		stdout   string
+		exitCode int
+		err      string
+	}{
+		{name: "output", script: "echo hello", stdout: "hello\n"},
+		{name: "exit code", script: "exit 3", exitCode: 3},
+		{name: "sh -e stops at the first failure", script: "false\necho unreachable", exitCode: 1},
+		{name: "environment", script: `echo "$STEP_PASSED-$STEP_HIDDEN"`, stdout: "passed-\n"},
+		{name: "timeout", script: "echo started; sleep 10", timeout: 200 * time.Millisecond, stdout: "started\n", exitCode: -1, err: "timed out after 200ms"},
+		{name: "timeout kills child processes", script: "sleep 10 &\nwait", timeout: 200 * time.Millisecond, exitCode: -1, err: "timed out after 200ms"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timeout := test.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			start := time.Now()
+			stdout, _, exitCode, err := runStepScript(test.script, timeout, []string{"STEP_PASSED"}, false)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("step took %s", elapsed)
+			}
+			if stdout != test.stdout || exitCode != test.exitCode {
+				t.Errorf("stdout = %q, exit code = %d, want %q, %d", stdout, exitCode, test.stdout, test.exitCode)
+			}
+			if errorString(err) != test.err {
+				t.Errorf("error = %q, want %q", errorString(err), test.err)
+			}
+		})
+	}
+}
+
+func TestRunStepScriptSandboxed(t *testing.T) {
+	if sandboxUnavailable != nil {
+		t.Skip(sandboxUnavailable)
+	}
+	if _, _, _, err := runStepScript("true", 10*time.Second, nil, true); err != nil {
+		t.Skipf("sandbox not available on this host: %v", err)
+	}
+
+	outside := t.TempDir()
+	tests := []struct {
+		name     string
+		script   string
+		timeout  time.Duration
+		stdout   string
+		exitCode int
+		err      string
+	}{
+		{name: "step directory is writable", script: "echo data > file; cat file", stdout: "data\n"},
+		{name: "TMPDIR is the step directory", script: `test "$TMPDIR" = "$(pwd)"`},
+		{name: "other directories are read-only", script: "touch " + filepath.Join(outside, "file"), exitCode: 1},
+		{name: "step is the first process of its PID namespace", script: "echo $$; head -c 2 /proc/1/cmdline", stdout: "1\nsh"},
+		{name: "timeout", script: "sleep 10", timeout: 200 * time.Millisecond, exitCode: -1, err: "timed out after 200ms"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timeout := test.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			stdout, stderr, exitCode, err := runStepScript(test.script, timeout, nil, true)
+			if stdout != test.stdout || exitCode != test.exitCode {
+				t.Errorf("stdout = %q, exit code = %d, want %q, %d (stderr %q)", stdout, exitCode, test.stdout, test.exitCode, stderr)
+			}
+			if errorString(err) != test.err {
+				t.Errorf("error = %q, want %q", errorString(err), test.err)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "file")); err == nil {
+		t.Error("sandboxed step wrote outside its directory")
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
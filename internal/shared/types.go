@@ -1,21 +1,67 @@
 package shared
 
-// TroubleshootingGuide represents a troubleshooting guide for a service
+import "encoding/json"
+
+// TroubleshootingGuide represents a troubleshooting guide for a service.
+// Status is draft or deprecated when the server labels content that is not
+// reviewed.
 type TroubleshootingGuide struct {
 	ServiceName        string                 `json:"serviceName"`
+	Status             string                 `json:"status,omitempty"`
 	CommonIssues       []TroubleshootingIssue `json:"commonIssues"`
-	DiagnosticCommands []string               `json:"diagnosticCommands"`
-	LogLocations       []string               `json:"logLocations"`
-	SupportResources   []string               `json:"supportResources"`
+	DiagnosticCommands []string               `json:"diagnosticCommands,omitempty"`
+	LogLocations       []string               `json:"logLocations,omitempty"`
+	SupportResources   []string               `json:"supportResources,omitempty"`
 }
 
 // TroubleshootingIssue represents a specific troubleshooting issue
 type TroubleshootingIssue struct {
-	Issue      string   `json:"issue"`
-	Symptoms   []string `json:"symptoms"`
-	Causes     []string `json:"causes"`
-	Solutions  []string `json:"solutions"`
-	Prevention []string `json:"prevention"`
+	Issue      string      `json:"issue"`
+	SharedID   string      `json:"sharedId,omitempty"`
+	Symptoms   []string    `json:"symptoms,omitempty"`
+	Causes     []string    `json:"causes,omitempty"`
+	Solutions  []string    `json:"solutions"`
+	Prevention []string    `json:"prevention,omitempty"`
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records where curated content came from and when it was last verified
+type Provenance struct {
+	SourceURL        string   `json:"sourceUrl,omitempty"`
+	VerifiedOn       string   `json:"verifiedOn,omitempty"`
+	VerifiedVersions []string `json:"verifiedVersions,omitempty"`
+	Author           string   `json:"author,omitempty"`
+}
+
+// TroubleshootingMatch represents a troubleshooting issue matched against an error message
+type TroubleshootingMatch struct {
+	TroubleshootingIssue
+	Score       int      `json:"matchScore"`
+	Explanation []string `json:"matchExplanation"`
+}
+
+// TroubleshootingLookup represents the issues matching an error message for a service
+type TroubleshootingLookup struct {
+	ServiceName        string                 `json:"serviceName"`
+	Status             string                 `json:"status,omitempty"`
+	ErrorMessage       string                 `json:"errorMessage"`
+	Matches            []TroubleshootingMatch `json:"matches"`
+	DiagnosticCommands []string               `json:"diagnosticCommands,omitempty"`
+	LogLocations       []string               `json:"logLocations,omitempty"`
+	SupportResources   []string               `json:"supportResources,omitempty"`
+}
+
+// TroubleshootingStep represents the current position in a guided troubleshooting session
+type TroubleshootingStep struct {
+	SessionID   string   `json:"sessionId"`
+	ServiceName string   `json:"serviceName"`
+	Node        string   `json:"node"`
+	Question    string   `json:"question,omitempty"`
+	Commands    []string `json:"commands,omitempty"`
+	Answers     []string `json:"answers,omitempty"`
+	Solution    string   `json:"solution,omitempty"`
+	Complete    bool     `json:"complete"`
+	Path        []string `json:"path"`
 }
 
 // CallToolResult represents the result of a tool call
@@ -48,10 +94,11 @@ type IntegrationDetails struct {
 
 // IntegrationDataStream represents a data stream in an integration
 type IntegrationDataStream struct {
-	Name        string  `json:"name"`
-	Type        string  `json:"type"`
-	Description string  `json:"description"`
-	Fields      []Field `json:"fields"`
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Fields      []Field         `json:"fields"`
+	SampleEvent json.RawMessage `json:"sampleEvent,omitempty"`
 }
 
 // Field represents a field in a data stream
@@ -61,6 +108,15 @@ type Field struct {
 	Description string `json:"description"`
 	Required    bool   `json:"required"`
 	Example     string `json:"example,omitempty"`
+	External    string `json:"external,omitempty"`
+}
+
+// CompatibilityRow represents which Elastic Stack minor versions a service supports.
+// Support values are "yes", "no", "unknown" or ">=x.y.z" when support starts at a later patch.
+type CompatibilityRow struct {
+	Service    string            `json:"service"`
+	Constraint string            `json:"constraint"`
+	Support    map[string]string `json:"support"`
 }
 
 // PolicyTemplate represents a policy template for an integration
@@ -194,10 +250,25 @@ type ValidationWarning struct {
 	Suggestion string `json:"suggestion,omitempty"`
 }
 
+// ValidationStepResult represents the outcome of running a validation step's
+// commands. Status is "pass", "fail" or "skip".
+type ValidationStepResult struct {
+	Step     int      `json:"step"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	ExitCode int      `json:"exitCode"`
+	Duration string   `json:"duration"`
+	Message  string   `json:"message,omitempty"`
+	Failures []string `json:"failures,omitempty"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+}
+
 // ValidationSuggestion represents a validation suggestion
 type ValidationSuggestion struct {
 	Type       string `json:"type"`
 	Message    string `json:"message"`
 	Suggestion string `json:"suggestion"`
 	Impact     string `json:"impact"`
+	Line       *int   `json:"line,omitempty"`
 }
@@ -0,0 +1,201 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint represents a version range in the npm style used by package
+// manifests, such as ">=2.4 <3.0" or "^8.17.8 || ^9.0.3". Comparators within
+// a group must all match; a version satisfies the constraint when any group matches.
+type Constraint struct {
+	raw       string
+	groups    [][]comparator
+	mentioned []Version
+}
+
+// comparator is a single bound. The "!=" operator excludes the half-open
+// range [version, upper) so that "!=2.4" excludes every 2.4.x release.
+type comparator struct {
+	op      string
+	version Version
+	upper   Version
+}
+
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"}
+
+// ParseConstraint parses a constraint made of "||" separated groups of space
+// or comma separated comparators. Caret (^), tilde (~), wildcard (2.x) and
+// hyphen (1.2 - 2.3) ranges are supported.
+func ParseConstraint(s string) (Constraint, error) {
+	constraint := Constraint{raw: strings.TrimSpace(s)}
+
+	for _, group := range strings.Split(s, "||") {
+		comparators, mentioned, err := parseGroup(group)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid version constraint '%s': %v", s, err)
+		}
+		constraint.groups = append(constraint.groups, comparators)
+		constraint.mentioned = append(constraint.mentioned, mentioned...)
+	}
+
+	return constraint, nil
+}
+
+// Check reports whether a version satisfies the constraint
+func (c Constraint) Check(v Version) bool {
+	for _, group := range c.groups {
+		if checkGroup(group, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Versions returns the versions written in the constraint, in order of appearance
+func (c Constraint) Versions() []Version {
+	return c.mentioned
+}
+
+func parseGroup(group string) ([]comparator, []Version, error) {
+	tokens := strings.FieldsFunc(group, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("empty range")
+	}
+
+	// Hyphen ranges: "1.2 - 2.3" covers 1.2.0 up to every 2.3.x release
+	if len(tokens) == 3 && tokens[1] == "-" {
+		lower, _, err := parse(tokens[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		upper, wildcard, err := parse(tokens[2])
+		if err != nil {
+			return nil, nil, err
+		}
+		mentioned := []Version{lower, upper}
+		if wildcard || upper.parts < 3 {
+			return []comparator{{op: ">=", version: lower}, {op: "<", version: upper.next(upper.parts)}}, mentioned, nil
+		}
+		return []comparator{{op: ">=", version: lower}, {op: "<=", version: upper}}, mentioned, nil
+	}
+
+	var comparators []comparator
+	var mentioned []Version
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		// Allow a space between the operator and the version, as in ">= 2.4"
+		if isOperator(token) && i+1 < len(tokens) {
+			i++
+			token += tokens[i]
+		}
+
+		parsed, v, err := parseComparator(token)
+		if err != nil {
+			return nil, nil, err
+		}
+		comparators = append(comparators, parsed...)
+		mentioned = append(mentioned, v)
+	}
+	return comparators, mentioned, nil
+}
+
+func checkGroup(group []comparator, v Version) bool {
+	for _, comp := range group {
+		if !comp.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseComparator(token string) ([]comparator, Version, error) {
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	v, wildcard, err := parse(strings.TrimPrefix(token, op))
+	if err != nil {
+		return nil, Version{}, err
+	}
+	if op == "==" {
+		op = "="
+	}
+
+	switch op {
+	case "^":
+		return []comparator{{op: ">=", version: v}, {op: "<", version: v.caretUpper(wildcard)}}, v, nil
+	case "~":
+		n := v.parts
+		if n > 2 {
+			n = 2
+		}
+		return []comparator{{op: ">=", version: v}, {op: "<", version: v.next(n)}}, v, nil
+	}
+
+	partial := wildcard || v.parts < 3
+	if !partial {
+		if op == "" {
+			op = "="
+		}
+		return []comparator{{op: op, version: v}}, v, nil
+	}
+
+	// A partial version stands for every release it prefixes, so "2.4"
+	// covers [2.4.0, 2.5.0) and the operators apply to that whole range.
+	upper := v.next(v.parts)
+	switch op {
+	case "", "=":
+		return []comparator{{op: ">=", version: v}, {op: "<", version: upper}}, v, nil
+	case ">=", "<":
+		return []comparator{{op: op, version: v}}, v, nil
+	case ">":
+		return []comparator{{op: ">=", version: upper}}, v, nil
+	case "<=":
+		return []comparator{{op: "<", version: upper}}, v, nil
+	case "!=":
+		return []comparator{{op: "!=", version: v, upper: upper}}, v, nil
+	}
+	return nil, Version{}, fmt.Errorf("unsupported operator '%s'", op)
+}
+
+func (c comparator) check(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		if c.upper.parts == 0 {
+			return cmp != 0
+		}
+		return cmp < 0 || v.Compare(c.upper) >= 0
+	}
+	return false
+}
+
+func isOperator(token string) bool {
+	for _, op := range operators {
+		if token == op {
+			return true
+		}
+	}
+	return false
+}
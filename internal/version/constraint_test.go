@@ -0,0 +1,80 @@
+package version
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+	}{
+		{constraint: ">=2.4 <3.0", matches: []string{"2.4.0", "2.9.9"}, misses: []string{"2.3.9", "3.0.0"}},
+		{constraint: ">= 2.4, < 3.0", matches: []string{"2.4.0", "2.99.0"}, misses: []string{"3.0.0"}},
+		{constraint: "2.x", matches: []string{"2.0.0", "2.9.1"}, misses: []string{"1.9.9", "3.0.0"}},
+		{constraint: "2.4", matches: []string{"2.4.0", "2.4.7"}, misses: []string{"2.5.0"}},
+		{constraint: ">2.4", matches: []string{"2.5.0"}, misses: []string{"2.4.9"}},
+		{constraint: "<=2.4", matches: []string{"2.4.9"}, misses: []string{"2.5.0"}},
+		{constraint: "!=2.4", matches: []string{"2.3.9", "2.5.0"}, misses: []string{"2.4.0", "2.4.3"}},
+		{constraint: "!=2.4.1", matches: []string{"2.4.0", "2.4.2"}, misses: []string{"2.4.1"}},
+		{constraint: "==1.2.3", matches: []string{"1.2.3"}, misses: []string{"1.2.4"}},
+		{constraint: "^8.17.8 || ^9.0.3", matches: []string{"8.17.8", "8.19.0", "9.0.3", "9.4.0"}, misses: []string{"8.17.7", "9.0.2", "10.0.0"}},
+		{constraint: "^0.2.3", matches: []string{"0.2.3", "0.2.9"}, misses: []string{"0.3.0"}},
+		{constraint: "^0.0.3", matches: []string{"0.0.3"}, misses: []string{"0.0.4"}},
+		{constraint: "^0.x", matches: []string{"0.1.0", "0.9.0"}, misses: []string{"1.0.0"}},
+		{constraint: "~1.2.3", matches: []string{"1.2.3", "1.2.9"}, misses: []string{"1.2.2", "1.3.0"}},
+		{constraint: "~1", matches: []string{"1.0.0", "1.9.0"}, misses: []string{"2.0.0"}},
+		{constraint: "1.2 - 2.3", matches: []string{"1.2.0", "2.3.9"}, misses: []string{"1.1.9", "2.4.0"}},
+		{constraint: "1.2 - 2.3.4", matches: []string{"2.3.4"}, misses: []string{"2.3.5"}},
+	}
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			constraint, err := ParseConstraint(test.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q): %v", test.constraint, err)
+			}
+			for _, text := range test.matches {
+				if !constraint.Check(mustParse(t, text)) {
+					t.Errorf("%s does not match %s, want a match", text, test.constraint)
+				}
+			}
+			for _, text := range test.misses {
+				if constraint.Check(mustParse(t, text)) {
+					t.Errorf("%s matches %s, want no match", text, test.constraint)
+				}
+			}
+		})
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, input := range []string{"", ">=2.4 ||", ">=latest", "1.2.3.4", "1.x - banana"} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseConstraint(input); err == nil {
+				t.Errorf("ParseConstraint(%q) succeeded, want an error", input)
+			}
+		})
+	}
+}
+
+func TestConstraintVersions(t *testing.T) {
+	constraint, err := ParseConstraint("^8.17.8 || >= 9.0.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := constraint.Versions()
+	if len(versions) != 2 || versions[0].String() != "8.17.8" || versions[1].String() != "9.0.3" {
+		t.Errorf("Versions() = %v, want [8.17.8 9.0.3]", versions)
+	}
+	if got := constraint.String(); got != "^8.17.8 || >= 9.0.3" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func mustParse(t *testing.T, text string) Version {
+	t.Helper()
+	v, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	return v
+}
@@ -0,0 +1,136 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version represents a semantic version. Parts missing from the parsed
+// string are recorded so that "2.4" can stand for any 2.4.x release.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	parts      int
+}
+
+// Parse parses a version such as "2.4", "v1.25.3" or "8.17.0-SNAPSHOT"
+func Parse(s string) (Version, error) {
+	v, wildcard, err := parse(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if wildcard {
+		return Version{}, fmt.Errorf("invalid version '%s': wildcards are only allowed in constraints", s)
+	}
+	return v, nil
+}
+
+// parse parses a possibly partial version, reporting whether it ended in a wildcard
+func parse(s string) (Version, bool, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if text == "" {
+		return Version{}, false, fmt.Errorf("invalid version '%s': empty", s)
+	}
+
+	var v Version
+	if i := strings.IndexAny(text, "-+"); i >= 0 {
+		if text[i] == '-' {
+			v.Prerelease = strings.SplitN(text[i+1:], "+", 2)[0]
+		}
+		text = text[:i]
+	}
+
+	fields := strings.Split(text, ".")
+	if len(fields) > 3 {
+		return Version{}, false, fmt.Errorf("invalid version '%s': too many components", s)
+	}
+
+	wildcard := false
+	numbers := make([]int, 0, 3)
+	for _, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			wildcard = true
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, false, fmt.Errorf("invalid version '%s': '%s' is not a number", s, field)
+		}
+		numbers = append(numbers, n)
+	}
+
+	v.parts = len(numbers)
+	if v.parts > 0 {
+		v.Major = numbers[0]
+	}
+	if v.parts > 1 {
+		v.Minor = numbers[1]
+	}
+	if v.parts > 2 {
+		v.Patch = numbers[2]
+	}
+	return v, wildcard, nil
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower than, equal to or higher than other
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	case v.Prerelease < other.Prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// next returns the lowest version above every version that the first n parts of v match
+func (v Version) next(n int) Version {
+	switch n {
+	case 0:
+		return Version{Major: 1 << 30, parts: 3}
+	case 1:
+		return Version{Major: v.Major + 1, parts: 3}
+	case 2:
+		return Version{Major: v.Major, Minor: v.Minor + 1, parts: 3}
+	default:
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1, parts: 3}
+	}
+}
+
+// caretUpper returns the exclusive upper bound of a caret range: the next
+// release that changes the left-most non-zero part, as npm defines it
+func (v Version) caretUpper(wildcard bool) Version {
+	switch {
+	case v.Major > 0 || v.parts < 2:
+		return v.next(1)
+	case v.Minor > 0 || v.parts < 3 || wildcard:
+		return v.next(2)
+	default:
+		return v.next(3)
+	}
+}
@@ -0,0 +1,71 @@
+package version
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "2.4", want: "2.4.0"},
+		{input: "v1.25.3", want: "1.25.3"},
+		{input: " 8.17.0-SNAPSHOT ", want: "8.17.0-SNAPSHOT"},
+		{input: "1.2.3+build.5", want: "1.2.3"},
+		{input: "1.2.3-rc.1+build.5", want: "1.2.3-rc.1"},
+		{input: "7", want: "7.0.0"},
+		{input: "", wantErr: true},
+		{input: "latest", wantErr: true},
+		{input: "1.2.3.4", wantErr: true},
+		{input: "1.-2", wantErr: true},
+		{input: "2.x", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			v, err := Parse(test.input)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", test.input, v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", test.input, err)
+			}
+			if got := v.String(); got != test.want {
+				t.Errorf("Parse(%q) = %s, want %s", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.2.3", b: "1.2.3", want: 0},
+		{a: "1.2", b: "1.2.0", want: 0},
+		{a: "1.2.3", b: "1.2.4", want: -1},
+		{a: "1.10.0", b: "1.9.0", want: 1},
+		{a: "2.0.0", b: "10.0.0", want: -1},
+		{a: "8.17.0-SNAPSHOT", b: "8.17.0", want: -1},
+		{a: "8.17.0", b: "8.17.0-SNAPSHOT", want: 1},
+		{a: "1.0.0-alpha", b: "1.0.0-beta", want: -1},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" vs "+test.b, func(t *testing.T) {
+			a, err := Parse(test.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Parse(test.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := a.Compare(b); got != test.want {
+				t.Errorf("Compare = %d, want %d", got, test.want)
+			}
+		})
+	}
+}